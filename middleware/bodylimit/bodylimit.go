@@ -0,0 +1,317 @@
+//Package bodylimit provide request body size limits and slow client protection middleware.
+package bodylimit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//ErrBodyTooLarge error raised when request body is larger than max bytes.
+var ErrBodyTooLarge = errors.New("bodylimit:request body too large")
+
+//ErrUploadTooSlow error raised when client uploads request body slower than min rate.
+var ErrUploadTooSlow = errors.New("bodylimit:request body upload too slow")
+
+//ErrReadTimeout error raised when request body is not read before read timeout.
+var ErrReadTimeout = errors.New("bodylimit:request body read timeout")
+
+//ErrReadDeadlineNotSupported error raised when response writer does not support read deadline.
+var ErrReadDeadlineNotSupported = errors.New("bodylimit:read deadline not supported")
+
+//DefaultMinRateGracePeriod default period before min rate is enforced.
+var DefaultMinRateGracePeriod = 5 * time.Second
+
+//Rule body limit rule.
+//Zero value fields inherit limiter defaults,negative value fields disable the limit.
+type Rule struct {
+	//Pattern request pattern rule applies to.
+	//Rule applies to all requests if nil.
+	Pattern requestmatching.Pattern
+	//MaxBytes max request body size in bytes.
+	MaxBytes int64
+	//MinBytesPerSecond min upload rate in bytes per second.
+	MinBytesPerSecond int64
+	//MinRateGracePeriod period before min rate is enforced.
+	MinRateGracePeriod time.Duration
+	//ReadTimeout duration in which whole request body must be read.
+	ReadTimeout time.Duration
+}
+
+func (r *Rule) merge(defaults *Rule) *Rule {
+	result := *r
+	if result.MaxBytes == 0 {
+		result.MaxBytes = defaults.MaxBytes
+	}
+	if result.MinBytesPerSecond == 0 {
+		result.MinBytesPerSecond = defaults.MinBytesPerSecond
+	}
+	if result.MinRateGracePeriod == 0 {
+		result.MinRateGracePeriod = defaults.MinRateGracePeriod
+	}
+	if result.ReadTimeout == 0 {
+		result.ReadTimeout = defaults.ReadTimeout
+	}
+	return &result
+}
+
+//Limiter body limit middleware.
+type Limiter struct {
+	//Default default rule
+	Default *Rule
+	//Rules rules checked in order.
+	//First matched rule will be used.
+	Rules []*Rule
+	//OnTooLarge handler serving request which body is too large.
+	//Status 413 will be returned if nil.
+	OnTooLarge http.HandlerFunc
+	//OnTimeout handler serving request which body is not read in time.
+	//Status 408 will be returned if nil.
+	OnTimeout http.HandlerFunc
+	//OnError func called when read deadline can not be set on response writer.
+	//Error will be ignored if nil.
+	OnError func(err error)
+}
+
+//RuleFor return merged rule for given request.
+func (l *Limiter) RuleFor(r *http.Request) *Rule {
+	defaults := l.Default
+	if defaults == nil {
+		defaults = &Rule{}
+	}
+	for k := range l.Rules {
+		if l.Rules[k].Pattern == nil || requestmatching.MustMatch(r, l.Rules[k].Pattern) {
+			return l.Rules[k].merge(defaults)
+		}
+	}
+	return defaults
+}
+
+func (l *Limiter) reject(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("Connection", "close")
+	if err == ErrBodyTooLarge {
+		if l.OnTooLarge != nil {
+			l.OnTooLarge(w, r)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if l.OnTimeout != nil {
+		l.OnTimeout(w, r)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusRequestTimeout), http.StatusRequestTimeout)
+}
+
+//ServeMiddleware serve as middleware.
+//Read deadlines are set by http.ResponseController.
+//If response writer does not support read deadline,
+//OnError will be called and deadlines are only checked between reads.
+func (l *Limiter) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	rule := l.RuleFor(r)
+	if rule.MaxBytes > 0 && r.ContentLength > rule.MaxBytes {
+		l.reject(w, r, ErrBodyTooLarge)
+		return
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		next(w, r)
+		return
+	}
+	b := newBody(r.Body, rule, http.NewResponseController(w))
+	b.onError = l.OnError
+	defer b.clearDeadline()
+	r.Body = b
+	s := &state{
+		limiter: l,
+		body:    b,
+		writer:  w,
+		request: r,
+	}
+	writer := middleware.WrapResponseWriter(w)
+	f := writer.Functions()
+	f.WriteHeaderFunc = s.WriteHeader
+	f.WriteFunc = s.Write
+	next(writer, r)
+	if b.Err() != nil {
+		s.rejectOnce()
+	}
+}
+
+//New create new limiter with given default rule.
+func New(defaults *Rule) *Limiter {
+	return &Limiter{
+		Default: defaults,
+	}
+}
+
+type state struct {
+	limiter  *Limiter
+	body     *body
+	writer   http.ResponseWriter
+	request  *http.Request
+	written  bool
+	rejected bool
+}
+
+func (s *state) rejectOnce() {
+	if s.written || s.rejected {
+		return
+	}
+	s.rejected = true
+	s.limiter.reject(s.writer, s.request, s.body.Err())
+}
+
+func (s *state) WriteHeader(statusCode int) {
+	if !s.written && s.body.Err() != nil {
+		s.rejectOnce()
+	}
+	if s.rejected {
+		return
+	}
+	s.written = true
+	s.writer.WriteHeader(statusCode)
+}
+
+func (s *state) Write(data []byte) (int, error) {
+	if !s.written && s.body.Err() != nil {
+		s.rejectOnce()
+	}
+	if s.rejected {
+		return len(data), nil
+	}
+	s.written = true
+	return s.writer.Write(data)
+}
+
+type body struct {
+	reader     io.ReadCloser
+	rule       *Rule
+	controller *http.ResponseController
+	start      time.Time
+	deadline   time.Time
+	//deadlineSet whether read deadline is set on connection.
+	deadlineSet bool
+	//unsupported whether response writer does not support read deadline.
+	unsupported bool
+	onError     func(err error)
+	read        int64
+	locker      sync.Mutex
+	err         error
+}
+
+func (b *body) Err() error {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	return b.err
+}
+
+func (b *body) fail(err error) error {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	if b.err == nil {
+		b.err = err
+	}
+	return b.err
+}
+
+func (b *body) rateDeadline() time.Time {
+	if b.rule.MinBytesPerSecond <= 0 {
+		return time.Time{}
+	}
+	grace := b.rule.MinRateGracePeriod
+	if grace <= 0 {
+		grace = DefaultMinRateGracePeriod
+	}
+	expected := time.Duration((b.read + 1) * int64(time.Second) / b.rule.MinBytesPerSecond)
+	return b.start.Add(grace + expected)
+}
+
+func (b *body) readDeadline() (time.Time, error) {
+	rate := b.rateDeadline()
+	if b.deadline.IsZero() || (!rate.IsZero() && rate.Before(b.deadline)) {
+		return rate, ErrUploadTooSlow
+	}
+	return b.deadline, ErrReadTimeout
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	if err := b.Err(); err != nil {
+		return 0, err
+	}
+	if b.rule.MaxBytes > 0 && int64(len(p)) > b.rule.MaxBytes-b.read+1 {
+		p = p[:b.rule.MaxBytes-b.read+1]
+	}
+	deadline, deadlineErr := b.readDeadline()
+	if !deadline.IsZero() {
+		if !time.Now().Before(deadline) {
+			return 0, b.fail(deadlineErr)
+		}
+		b.setReadDeadline(deadline)
+	}
+	n, err := b.reader.Read(p)
+	b.read += int64(n)
+	if b.rule.MaxBytes > 0 && b.read > b.rule.MaxBytes {
+		n = n - int(b.read-b.rule.MaxBytes)
+		b.read = b.rule.MaxBytes
+		return n, b.fail(ErrBodyTooLarge)
+	}
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		return n, b.fail(deadlineErr)
+	}
+	return n, err
+}
+
+func (b *body) Close() error {
+	return b.reader.Close()
+}
+
+func (b *body) setReadDeadline(deadline time.Time) {
+	if b.unsupported {
+		return
+	}
+	if err := b.controller.SetReadDeadline(deadline); err != nil {
+		b.unsupported = true
+		if b.onError != nil {
+			b.onError(fmt.Errorf("%w : %v", ErrReadDeadlineNotSupported, err))
+		}
+		return
+	}
+	b.deadlineSet = true
+}
+
+//clearDeadline clear read deadline after request served.
+//If body failed,deadline is set to now instead,
+//so server will not wait for rest of body and connection will be released.
+func (b *body) clearDeadline() {
+	if b.unsupported {
+		return
+	}
+	if b.Err() != nil {
+		b.controller.SetReadDeadline(time.Now())
+		return
+	}
+	if b.deadlineSet {
+		b.controller.SetReadDeadline(time.Time{})
+	}
+}
+
+func newBody(reader io.ReadCloser, rule *Rule, controller *http.ResponseController) *body {
+	b := &body{
+		reader:     reader,
+		rule:       rule,
+		controller: controller,
+		start:      time.Now(),
+	}
+	if rule.ReadTimeout > 0 {
+		b.deadline = b.start.Add(rule.ReadTimeout)
+	}
+	return b
+}
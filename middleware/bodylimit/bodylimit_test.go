@@ -0,0 +1,276 @@
+package bodylimit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+var readAction = func(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Write(data)
+}
+
+type onlyReader struct {
+	io.Reader
+}
+
+func newLimiter() *Limiter {
+	l := New(&Rule{
+		MaxBytes: 10,
+	})
+	p := requestmatching.NewPlainPattern()
+	p.Prefixs.Add("/upload")
+	l.Rules = append(l.Rules, &Rule{
+		Pattern:  p,
+		MaxBytes: 100,
+	})
+	p = requestmatching.NewPlainPattern()
+	p.Prefixs.Add("/unlimited")
+	l.Rules = append(l.Rules, &Rule{
+		Pattern:  p,
+		MaxBytes: -1,
+	})
+	return l
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter()
+	called := false
+	app := middleware.New(l.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		readAction(w, r)
+	})
+	s := httptest.NewServer(app)
+	defer s.Close()
+	resp, err := http.Post(s.URL+"/", "text/plain", strings.NewReader(strings.Repeat("a", 11)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 413 || called {
+		t.Fatal(resp.StatusCode, called)
+	}
+	resp, err = http.Post(s.URL+"/", "text/plain", &onlyReader{strings.NewReader(strings.Repeat("a", 11))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 413 || !called || strings.Contains(string(data), ErrBodyTooLarge.Error()) {
+		t.Fatal(resp.StatusCode, called, string(data))
+	}
+	resp, err = http.Post(s.URL+"/", "text/plain", &onlyReader{strings.NewReader(strings.Repeat("a", 10))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || len(data) != 10 {
+		t.Fatal(resp.StatusCode, string(data))
+	}
+	resp, err = http.Post(s.URL+"/upload", "text/plain", strings.NewReader(strings.Repeat("a", 100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatal(resp.StatusCode)
+	}
+	resp, err = http.Post(s.URL+"/upload", "text/plain", strings.NewReader(strings.Repeat("a", 101)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 413 {
+		t.Fatal(resp.StatusCode)
+	}
+	resp, err = http.Post(s.URL+"/unlimited", "text/plain", strings.NewReader(strings.Repeat("a", 1000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatal(resp.StatusCode)
+	}
+	resp, err = http.Get(s.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatal(resp.StatusCode)
+	}
+}
+
+func TestSlowClient(t *testing.T) {
+	l := New(&Rule{
+		MinBytesPerSecond:  1000,
+		MinRateGracePeriod: 100 * time.Millisecond,
+	})
+	l.OnTimeout = func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow", http.StatusRequestTimeout)
+	}
+	app := middleware.New(l.ServeMiddleware).HandleFunc(readAction)
+	s := httptest.NewServer(app)
+	defer s.Close()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(s.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte("POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 100000\r\n\r\nabc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestTimeout || strings.TrimSpace(string(data)) != "slow" || !resp.Close {
+		t.Fatal(resp.StatusCode, string(data))
+	}
+}
+
+func TestReadTimeout(t *testing.T) {
+	l := New(&Rule{})
+	p := requestmatching.NewPlainPattern()
+	p.Prefixs.Add("/deadline")
+	l.Rules = append(l.Rules, &Rule{
+		Pattern:     p,
+		ReadTimeout: 100 * time.Millisecond,
+	})
+	app := middleware.New(l.ServeMiddleware).HandleFunc(readAction)
+	s := httptest.NewServer(app)
+	defer s.Close()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(s.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte("POST /deadline HTTP/1.1\r\nHost: localhost\r\nContent-Length: 10\r\n\r\nabc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestTimeout {
+		t.Fatal(resp.StatusCode)
+	}
+}
+
+func TestFactory(t *testing.T) {
+	data, err := json.Marshal(&Config{
+		RuleConfig: RuleConfig{
+			MaxBytes: 10,
+		},
+		Rules: []*RuleConfig{
+			&RuleConfig{
+				Pattern: &requestmatching.PatternConfig{
+					PrefixList: []string{"/upload"},
+				},
+				MaxBytes: 100,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := middleware.New(m).HandleFunc(readAction)
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("POST", "/upload", strings.NewReader(strings.Repeat("a", 50))))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(strings.Repeat("a", 50))))
+	if rec.Code != 413 {
+		t.Fatal(rec.Code)
+	}
+}
+
+func TestReadTimeoutWrappedWriter(t *testing.T) {
+	l := New(&Rule{
+		ReadTimeout: 100 * time.Millisecond,
+	})
+	var deadlineErr error
+	l.OnError = func(err error) {
+		deadlineErr = err
+	}
+	wrap := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(middleware.WrapResponseWriter(w), r)
+	}
+	app := middleware.New(wrap, l.ServeMiddleware).HandleFunc(readAction)
+	s := httptest.NewServer(app)
+	defer s.Close()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(s.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte("POST / HTTP/1.1\r\nHost: localhost\r\nContent-Length: 10\r\n\r\nabc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestTimeout || !resp.Close || deadlineErr != nil {
+		t.Fatal(resp.StatusCode, deadlineErr)
+	}
+	//Connection should be closed by server instead of waiting for rest of body.
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = reader.ReadByte()
+	if err != io.EOF {
+		t.Fatal(err)
+	}
+}
+
+func TestReadDeadlineNotSupported(t *testing.T) {
+	l := New(&Rule{
+		ReadTimeout: time.Second,
+	})
+	var deadlineErr error
+	l.OnError = func(err error) {
+		deadlineErr = err
+	}
+	app := middleware.New(l.ServeMiddleware).HandleFunc(readAction)
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader("abc")))
+	if rec.Code != 200 || rec.Body.String() != "abc" || !errors.Is(deadlineErr, ErrReadDeadlineNotSupported) {
+		t.Fatal(rec.Code, deadlineErr)
+	}
+}
@@ -0,0 +1,85 @@
+package bodylimit
+
+import (
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//RuleConfig body limit rule config.
+//Zero value fields inherit default config,negative value fields disable the limit.
+type RuleConfig struct {
+	//Pattern request pattern rule applies to.
+	Pattern *requestmatching.PatternConfig
+	//MaxBytes max request body size in bytes.
+	MaxBytes int64
+	//MinBytesPerSecond min upload rate in bytes per second.
+	MinBytesPerSecond int64
+	//MinRateGracePeriodInSecond period in second before min rate is enforced.
+	MinRateGracePeriodInSecond int64
+	//ReadTimeoutInSecond duration in second in which whole request body must be read.
+	ReadTimeoutInSecond int64
+}
+
+//CreateRule create rule with config.
+//Return rule and any error if raised.
+func (c *RuleConfig) CreateRule() (*Rule, error) {
+	r := &Rule{
+		MaxBytes:           c.MaxBytes,
+		MinBytesPerSecond:  c.MinBytesPerSecond,
+		MinRateGracePeriod: time.Duration(c.MinRateGracePeriodInSecond) * time.Second,
+		ReadTimeout:        time.Duration(c.ReadTimeoutInSecond) * time.Second,
+	}
+	if c.Pattern != nil {
+		p, err := c.Pattern.CreatePattern()
+		if err != nil {
+			return nil, err
+		}
+		r.Pattern = p
+	}
+	return r, nil
+}
+
+//Config body limit config
+type Config struct {
+	RuleConfig
+	//Rules rule configs checked in order.
+	Rules []*RuleConfig
+}
+
+//CreateLimiter create limiter with config.
+//Return limiter and any error if raised.
+func (c *Config) CreateLimiter() (*Limiter, error) {
+	defaults, err := c.RuleConfig.CreateRule()
+	if err != nil {
+		return nil, err
+	}
+	defaults.Pattern = nil
+	l := New(defaults)
+	for k := range c.Rules {
+		r, err := c.Rules[k].CreateRule()
+		if err != nil {
+			return nil, err
+		}
+		l.Rules = append(l.Rules, r)
+	}
+	return l, nil
+}
+
+//NewFactory create new body limit middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		l, err := c.CreateLimiter()
+		if err != nil {
+			return nil, err
+		}
+		return l.ServeMiddleware, nil
+	}
+}
@@ -0,0 +1,245 @@
+//Package canonical provide canonical url redirect middleware.
+package canonical
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/herb-go/herb/middleware/secureheaders"
+	"github.com/herb-go/herb/service"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//ErrInvalidStatusCode error raised when redirect status code is not 301,302,307 or 308.
+var ErrInvalidStatusCode = errors.New("canonical:invalid redirect status code")
+
+//ErrUnknownMode error raised when mode name is unknown.
+var ErrUnknownMode = errors.New("canonical:unknown mode")
+
+//DefaultStatusCode default redirect status code
+const DefaultStatusCode = http.StatusMovedPermanently
+
+//Mode add or remove mode
+type Mode string
+
+const (
+	//ModeIgnore keep url unchanged.
+	ModeIgnore = Mode("")
+	//ModeAdd add prefix or suffix.
+	ModeAdd = Mode("add")
+	//ModeRemove remove prefix or suffix.
+	ModeRemove = Mode("remove")
+)
+
+//ParseMode parse mode name.
+//Return mode and any error if raised.
+func ParseMode(name string) (Mode, error) {
+	m := Mode(name)
+	switch m {
+	case ModeIgnore, ModeAdd, ModeRemove:
+		return m, nil
+	}
+	return ModeIgnore, ErrUnknownMode
+}
+
+//ValidateStatusCode validate redirect status code.
+//Return any error if raised.
+func ValidateStatusCode(code int) error {
+	switch code {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return nil
+	}
+	return ErrInvalidStatusCode
+}
+
+//Canonical canonical url redirect middleware struct.
+//All policies are applied in one redirect.
+//Status code of first changed policy in order of https,host,trailing slash and lowercase will be used.
+type Canonical struct {
+	//HTTPS whether http requests are redirected to https
+	HTTPS bool
+	//HTTPSStatusCode status code of https redirect
+	HTTPSStatusCode int
+	//HTTPSPort port used in https url.
+	//Port is removed if empty.
+	HTTPSPort string
+	//Host primary host.
+	//Requests to other hosts matching Hosts are redirected to primary host.
+	Host string
+	//Hosts host patterns to be canonicalized.
+	//All hosts are canonicalized if empty.
+	Hosts []service.HostPattern
+	//WWW www prefix mode used when primary host is empty.
+	WWW Mode
+	//HostStatusCode status code of host redirect
+	HostStatusCode int
+	//TrailingSlash trailing slash mode.
+	//Paths whose last segment contains "." are not changed in add mode.
+	TrailingSlash Mode
+	//TrailingSlashStatusCode status code of trailing slash redirect
+	TrailingSlashStatusCode int
+	//Lowercase whether paths are redirected to lowercase
+	Lowercase bool
+	//LowercaseStatusCode status code of lowercase redirect
+	LowercaseStatusCode int
+	//Exceptions requests matching any pattern are not redirected
+	Exceptions []requestmatching.Pattern
+}
+
+func statusCode(code int) int {
+	if code == 0 {
+		return DefaultStatusCode
+	}
+	return code
+}
+
+func (c *Canonical) matchHost(hostname string) bool {
+	if len(c.Hosts) == 0 {
+		return true
+	}
+	for _, p := range c.Hosts {
+		if p.Match(hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Canonical) canonicalHost(hostname string) string {
+	if !c.matchHost(hostname) {
+		return hostname
+	}
+	if c.Host != "" {
+		return strings.ToLower(c.Host)
+	}
+	switch c.WWW {
+	case ModeAdd:
+		if !strings.HasPrefix(hostname, "www.") && net.ParseIP(hostname) == nil {
+			return "www." + hostname
+		}
+	case ModeRemove:
+		return strings.TrimPrefix(hostname, "www.")
+	}
+	return hostname
+}
+
+func (c *Canonical) canonicalPath(p string) string {
+	switch c.TrailingSlash {
+	case ModeAdd:
+		if !strings.HasSuffix(p, "/") && !strings.Contains(path.Base(p), ".") {
+			p = p + "/"
+		}
+	case ModeRemove:
+		if len(p) > 1 {
+			p = strings.TrimRight(p, "/")
+			if p == "" {
+				p = "/"
+			}
+		}
+	}
+	return p
+}
+
+//lowerEscapedPath lowercase escaped path.
+//Percent-encoded octets are kept unchanged.
+func lowerEscapedPath(p string) string {
+	b := []byte(p)
+	for i := 0; i < len(b); i++ {
+		if b[i] == '%' {
+			i = i + 2
+			continue
+		}
+		if 'A' <= b[i] && b[i] <= 'Z' {
+			b[i] = b[i] + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+//Location return canonical url and redirect status code of request.
+//Return nil if request is already canonical or excepted.
+func (c *Canonical) Location(r *http.Request) (*url.URL, int) {
+	for _, p := range c.Exceptions {
+		if requestmatching.MustMatch(r, p) {
+			return nil, 0
+		}
+	}
+	status := 0
+	set := func(code int) {
+		if status == 0 {
+			status = statusCode(code)
+		}
+	}
+	https := secureheaders.IsHTTPS(r)
+	hostname, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		hostname = r.Host
+		port = ""
+	}
+	hostname = strings.ToLower(hostname)
+	if c.HTTPS && !https {
+		https = true
+		port = c.HTTPSPort
+		set(c.HTTPSStatusCode)
+	}
+	if hostname != "" {
+		canonical := c.canonicalHost(hostname)
+		if canonical != hostname {
+			hostname = canonical
+			set(c.HostStatusCode)
+		}
+	}
+	p := r.URL.EscapedPath()
+	if p == "" {
+		p = "/"
+	}
+	if slashed := c.canonicalPath(p); slashed != p {
+		p = slashed
+		set(c.TrailingSlashStatusCode)
+	}
+	if c.Lowercase {
+		if lower := lowerEscapedPath(p); lower != p {
+			p = lower
+			set(c.LowercaseStatusCode)
+		}
+	}
+	if status == 0 {
+		return nil, 0
+	}
+	u := &url.URL{
+		Scheme:   "http",
+		Host:     hostname,
+		Path:     p,
+		RawPath:  p,
+		RawQuery: r.URL.RawQuery,
+	}
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		u.Path = unescaped
+	}
+	if https {
+		u.Scheme = "https"
+	}
+	if port != "" {
+		u.Host = net.JoinHostPort(hostname, port)
+	}
+	return u, status
+}
+
+//ServeMiddleware serve as middleware.
+func (c *Canonical) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	u, status := c.Location(r)
+	if u == nil {
+		next(w, r)
+		return
+	}
+	http.Redirect(w, r, u.String(), status)
+}
+
+//New create new canonical middleware which redirects nothing.
+func New() *Canonical {
+	return &Canonical{}
+}
@@ -0,0 +1,199 @@
+package canonical
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/forwarded"
+	"github.com/herb-go/herb/service"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+func location(c *Canonical, r *http.Request) (string, int) {
+	u, status := c.Location(r)
+	if u == nil {
+		return "", status
+	}
+	return u.String(), status
+}
+
+func TestHTTPS(t *testing.T) {
+	c := New()
+	c.HTTPS = true
+	c.HTTPSStatusCode = http.StatusPermanentRedirect
+	l, status := location(c, httptest.NewRequest("POST", "http://example.com:8080/a?b=c", nil))
+	if l != "https://example.com/a?b=c" || status != 308 {
+		t.Fatal(l, status)
+	}
+	c.HTTPSPort = "8443"
+	l, _ = location(c, httptest.NewRequest("GET", "http://example.com/a", nil))
+	if l != "https://example.com:8443/a" {
+		t.Fatal(l)
+	}
+	req := httptest.NewRequest("GET", "http://example.com/a", nil)
+	req.TLS = &tls.ConnectionState{}
+	l, _ = location(c, req)
+	if l != "" {
+		t.Fatal(l)
+	}
+	fw := forwarded.New()
+	fw.Enabled = true
+	fw.ForwardedProtoHeader = "X-Forwarded-Proto"
+	app := middleware.New(fw.ServeMiddleware, c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {})
+	req = httptest.NewRequest("GET", "http://example.com/a", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	req = httptest.NewRequest("GET", "http://example.com/a", nil)
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 308 || rec.Header().Get("Location") != "https://example.com:8443/a" {
+		t.Fatal(rec.Code, rec.Header())
+	}
+}
+
+func TestHost(t *testing.T) {
+	c := New()
+	c.WWW = ModeAdd
+	l, status := location(c, httptest.NewRequest("GET", "http://Example.com/", nil))
+	if l != "http://www.example.com/" || status != 301 {
+		t.Fatal(l, status)
+	}
+	l, _ = location(c, httptest.NewRequest("GET", "http://127.0.0.1/", nil))
+	if l != "" {
+		t.Fatal(l)
+	}
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	req.Host = ""
+	l, _ = location(c, req)
+	if l != "" {
+		t.Fatal(l)
+	}
+	c.WWW = ModeRemove
+	c.HostStatusCode = http.StatusFound
+	l, status = location(c, httptest.NewRequest("GET", "http://www.example.com:8080/", nil))
+	if l != "http://example.com:8080/" || status != 302 {
+		t.Fatal(l, status)
+	}
+	c.Host = "Primary.com"
+	c.Hosts = []service.HostPattern{"*example.com"}
+	l, _ = location(c, httptest.NewRequest("GET", "http://www.example.com/a", nil))
+	if l != "http://primary.com/a" {
+		t.Fatal(l)
+	}
+	l, _ = location(c, httptest.NewRequest("GET", "http://other.com/a", nil))
+	if l != "" {
+		t.Fatal(l)
+	}
+	l, _ = location(c, httptest.NewRequest("GET", "http://primary.com/a", nil))
+	if l != "" {
+		t.Fatal(l)
+	}
+}
+
+func TestPath(t *testing.T) {
+	c := New()
+	c.TrailingSlash = ModeAdd
+	c.Lowercase = true
+	c.LowercaseStatusCode = http.StatusTemporaryRedirect
+	for path, expected := range map[string]string{
+		"/":             "",
+		"/a/":           "",
+		"/a":            "http://example.com/a/?q=A",
+		"/a/file.txt":   "",
+		"/A/":           "http://example.com/a/?q=A",
+		"/A/File.TXT":   "http://example.com/a/file.txt?q=A",
+		"/a%20b/":       "",
+		"/A%20B/":       "http://example.com/a%20b/?q=A",
+		"/a%2Fb/":       "",
+		"/A%2Fb":        "http://example.com/a%2Fb/?q=A",
+		"/a/b.c/d/e.f/": "",
+	} {
+		l, _ := location(c, httptest.NewRequest("GET", "http://example.com"+path+"?q=A", nil))
+		if l != expected {
+			t.Fatal(path, l)
+		}
+	}
+	_, status := location(c, httptest.NewRequest("GET", "http://example.com/A", nil))
+	if status != 301 {
+		t.Fatal(status)
+	}
+	_, status = location(c, httptest.NewRequest("GET", "http://example.com/A/", nil))
+	if status != 307 {
+		t.Fatal(status)
+	}
+	c.TrailingSlash = ModeRemove
+	for path, expected := range map[string]string{
+		"/":    "",
+		"/a":   "",
+		"/a/":  "http://example.com/a",
+		"/a//": "http://example.com/a",
+	} {
+		l, _ := location(c, httptest.NewRequest("GET", "http://example.com"+path, nil))
+		if l != expected {
+			t.Fatal(path, l)
+		}
+	}
+}
+
+func TestExceptions(t *testing.T) {
+	c := New()
+	c.HTTPS = true
+	c.Exceptions = []requestmatching.Pattern{
+		requestmatching.MustCreatePattern(&requestmatching.PatternConfig{PrefixList: []string{"/.well-known/acme-challenge/"}}),
+	}
+	l, _ := location(c, httptest.NewRequest("GET", "http://example.com/.well-known/acme-challenge/token", nil))
+	if l != "" {
+		t.Fatal(l)
+	}
+	l, _ = location(c, httptest.NewRequest("GET", "http://example.com/.well-known/other", nil))
+	if l != "https://example.com/.well-known/other" {
+		t.Fatal(l)
+	}
+}
+
+func TestFactory(t *testing.T) {
+	data, err := json.Marshal(&Config{
+		HTTPS:           true,
+		HTTPSStatusCode: 308,
+		Host:            "example.com",
+		Hosts:           []string{".example.com"},
+		TrailingSlash:   "remove",
+		Exceptions:      []*requestmatching.PatternConfig{{URLList: []string{"/health/"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "http://www.example.com/a/", nil))
+	if rec.Code != 308 || rec.Header().Get("Location") != "https://example.com/a" {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "http://www.example.com/health/", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	_, err = (&Config{HostStatusCode: 200}).CreateCanonical()
+	if err != ErrInvalidStatusCode {
+		t.Fatal(err)
+	}
+	_, err = (&Config{WWW: "unknown"}).CreateCanonical()
+	if err != ErrUnknownMode {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,92 @@
+package canonical
+
+import (
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//Config canonical config
+type Config struct {
+	//HTTPS whether http requests are redirected to https
+	HTTPS bool
+	//HTTPSStatusCode status code of https redirect
+	HTTPSStatusCode int
+	//HTTPSPort port used in https url
+	HTTPSPort string
+	//Host primary host
+	Host string
+	//Hosts host patterns to be canonicalized
+	Hosts []string
+	//WWW www prefix mode,"add" or "remove"
+	WWW string
+	//HostStatusCode status code of host redirect
+	HostStatusCode int
+	//TrailingSlash trailing slash mode,"add" or "remove"
+	TrailingSlash string
+	//TrailingSlashStatusCode status code of trailing slash redirect
+	TrailingSlashStatusCode int
+	//Lowercase whether paths are redirected to lowercase
+	Lowercase bool
+	//LowercaseStatusCode status code of lowercase redirect
+	LowercaseStatusCode int
+	//Exceptions request patterns which are not redirected
+	Exceptions []*requestmatching.PatternConfig
+}
+
+//CreateCanonical create canonical with config.
+//Return canonical and any error if raised.
+func (c *Config) CreateCanonical() (*Canonical, error) {
+	var err error
+	for _, code := range []int{c.HTTPSStatusCode, c.HostStatusCode, c.TrailingSlashStatusCode, c.LowercaseStatusCode} {
+		err = ValidateStatusCode(code)
+		if err != nil {
+			return nil, err
+		}
+	}
+	m := New()
+	m.HTTPS = c.HTTPS
+	m.HTTPSStatusCode = c.HTTPSStatusCode
+	m.HTTPSPort = c.HTTPSPort
+	m.Host = c.Host
+	for _, v := range c.Hosts {
+		m.Hosts = append(m.Hosts, service.HostPattern(v))
+	}
+	m.WWW, err = ParseMode(c.WWW)
+	if err != nil {
+		return nil, err
+	}
+	m.HostStatusCode = c.HostStatusCode
+	m.TrailingSlash, err = ParseMode(c.TrailingSlash)
+	if err != nil {
+		return nil, err
+	}
+	m.TrailingSlashStatusCode = c.TrailingSlashStatusCode
+	m.Lowercase = c.Lowercase
+	m.LowercaseStatusCode = c.LowercaseStatusCode
+	for _, v := range c.Exceptions {
+		p, err := v.CreatePattern()
+		if err != nil {
+			return nil, err
+		}
+		m.Exceptions = append(m.Exceptions, p)
+	}
+	return m, nil
+}
+
+//NewFactory create new canonical middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		m, err := c.CreateCanonical()
+		if err != nil {
+			return nil, err
+		}
+		return m.ServeMiddleware, nil
+	}
+}
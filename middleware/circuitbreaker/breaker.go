@@ -0,0 +1,187 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+//State circuit state
+type State int
+
+//Circuit states.
+const (
+	//StateClosed requests are passed through.
+	StateClosed = State(0)
+	//StateOpen requests are rejected and served by fallback.
+	StateOpen = State(1)
+	//StateHalfOpen limited trial requests are passed through.
+	StateHalfOpen = State(2)
+)
+
+//String return state name.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+//Clock time source interface
+type Clock interface {
+	//Now return current time.
+	Now() time.Time
+}
+
+//ClockFunc clock func type
+type ClockFunc func() time.Time
+
+//Now return current time.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+//SystemClock clock which returns system time.
+var SystemClock = ClockFunc(time.Now)
+
+type transition struct {
+	from State
+	to   State
+}
+
+//Breaker single circuit
+type Breaker struct {
+	//Key circuit key
+	Key        string
+	cb         *CircuitBreaker
+	locker     sync.Mutex
+	state      State
+	generation uint64
+	failures   int
+	successes  int
+	trials     int
+	openedAt   time.Time
+}
+
+func (b *Breaker) setState(to State, now time.Time, transitions *[]transition) {
+	if b.state == to {
+		return
+	}
+	*transitions = append(*transitions, transition{from: b.state, to: to})
+	b.state = to
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.trials = 0
+	if to == StateOpen {
+		b.openedAt = now
+	}
+}
+
+func (b *Breaker) currentState(now time.Time, transitions *[]transition) State {
+	if b.state == StateOpen && !now.Before(b.openedAt.Add(b.cb.OpenTimeout)) {
+		b.setState(StateHalfOpen, now, transitions)
+	}
+	return b.state
+}
+
+func (b *Breaker) notify(transitions []transition) {
+	if b.cb.OnStateChange == nil {
+		return
+	}
+	for _, t := range transitions {
+		b.cb.OnStateChange(b.Key, t.from, t.to)
+	}
+}
+
+//State return current circuit state.
+func (b *Breaker) State() State {
+	transitions := []transition{}
+	b.locker.Lock()
+	s := b.currentState(b.cb.Clock.Now(), &transitions)
+	b.locker.Unlock()
+	b.notify(transitions)
+	return s
+}
+
+//RetryAfter return duration after which open circuit will become half-open.
+//Return 0 if circuit is not open.
+func (b *Breaker) RetryAfter() time.Duration {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	if b.state != StateOpen {
+		return 0
+	}
+	d := b.openedAt.Add(b.cb.OpenTimeout).Sub(b.cb.Clock.Now())
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+//Allow check if request is allowed.
+//Return func which should be called with request result and true if allowed,
+//or nil and false if rejected.
+func (b *Breaker) Allow() (func(failed bool), bool) {
+	transitions := []transition{}
+	b.locker.Lock()
+	defer func() {
+		b.locker.Unlock()
+		b.notify(transitions)
+	}()
+	switch b.currentState(b.cb.Clock.Now(), &transitions) {
+	case StateOpen:
+		return nil, false
+	case StateHalfOpen:
+		if b.trials >= b.cb.halfOpenMaxRequests() {
+			return nil, false
+		}
+		b.trials++
+	}
+	generation := b.generation
+	var once sync.Once
+	return func(failed bool) {
+		once.Do(func() {
+			b.done(generation, failed)
+		})
+	}, true
+}
+
+func (b *Breaker) done(generation uint64, failed bool) {
+	transitions := []transition{}
+	b.locker.Lock()
+	defer func() {
+		b.locker.Unlock()
+		b.notify(transitions)
+	}()
+	now := b.cb.Clock.Now()
+	state := b.currentState(now, &transitions)
+	if generation != b.generation {
+		return
+	}
+	switch state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cb.maxFailures() {
+			b.setState(StateOpen, now, &transitions)
+		}
+	case StateHalfOpen:
+		b.trials--
+		if failed {
+			b.setState(StateOpen, now, &transitions)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cb.successThreshold() {
+			b.setState(StateClosed, now, &transitions)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+//Package circuitbreaker provide circuit breaker middleware.
+package circuitbreaker
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/herb-go/herb/identifier"
+	"github.com/herb-go/herb/middleware/httpinfo"
+	"github.com/herb-go/herb/middleware/router"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//DefaultFallback default fallback handler serving requests while circuit open.
+//Status 503 will be returned.
+var DefaultFallback = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+})
+
+//RouteIdentifier identifier which identify request by route name.
+//Middleware should be used after route name is set.
+var RouteIdentifier = identifier.IDFunc(func(r *http.Request) (string, error) {
+	return router.GetRoute(r).Name, nil
+})
+
+//Rule named pattern
+type Rule struct {
+	//Name rule name used as circuit key
+	Name string
+	//Pattern request pattern
+	Pattern requestmatching.Pattern
+}
+
+//Rules rule list which identify request by first matched rule name.
+//Empty string will be returned if no rule matched.
+type Rules []*Rule
+
+//IdentifyRequest identify http request
+//return identification and any error if rasied.
+func (rules Rules) IdentifyRequest(r *http.Request) (string, error) {
+	for _, rule := range rules {
+		ok, err := rule.Pattern.MatchRequest(r)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return rule.Name, nil
+		}
+	}
+	return "", nil
+}
+
+//DefaultFailureStatus default failure status checker.
+//Status codes not less than 500 are treated as failure.
+func DefaultFailureStatus(statusCode int) bool {
+	return statusCode >= 500
+}
+
+//CircuitBreaker circuit breaker middleware struct
+type CircuitBreaker struct {
+	//Key identifier which creates circuit key from request.
+	//Requests with empty key bypass circuit breaker.
+	Key identifier.Identifier
+	//MaxFailures consecutive failures after which circuit opens.
+	//Treated as 1 if not greater than 0.
+	MaxFailures int
+	//OpenTimeout duration after which open circuit becomes half-open.
+	OpenTimeout time.Duration
+	//HalfOpenMaxRequests max concurrent trial requests while half-open.
+	//Treated as 1 if not greater than 0.
+	HalfOpenMaxRequests int
+	//SuccessThreshold successful trial requests after which half-open circuit closes.
+	//Treated as 1 if not greater than 0.
+	SuccessThreshold int
+	//FailureStatus func which checks if response status code is failure.
+	FailureStatus func(statusCode int) bool
+	//Fallback handler serving requests while circuit open.
+	//Retry-After header is set before fallback called.
+	Fallback http.HandlerFunc
+	//OnStateChange func called when circuit state changed.
+	OnStateChange func(key string, from State, to State)
+	//Clock time source.
+	Clock    Clock
+	locker   sync.Mutex
+	breakers map[string]*Breaker
+}
+
+func (cb *CircuitBreaker) maxFailures() int {
+	if cb.MaxFailures <= 0 {
+		return 1
+	}
+	return cb.MaxFailures
+}
+
+func (cb *CircuitBreaker) halfOpenMaxRequests() int {
+	if cb.HalfOpenMaxRequests <= 0 {
+		return 1
+	}
+	return cb.HalfOpenMaxRequests
+}
+
+func (cb *CircuitBreaker) successThreshold() int {
+	if cb.SuccessThreshold <= 0 {
+		return 1
+	}
+	return cb.SuccessThreshold
+}
+
+//Breaker return circuit by given key.
+//Circuit will be created if not exists.
+func (cb *CircuitBreaker) Breaker(key string) *Breaker {
+	cb.locker.Lock()
+	defer cb.locker.Unlock()
+	b := cb.breakers[key]
+	if b == nil {
+		b = &Breaker{
+			Key: key,
+			cb:  cb,
+		}
+		cb.breakers[key] = b
+	}
+	return b
+}
+
+//States return states of all created circuits.
+func (cb *CircuitBreaker) States() map[string]State {
+	cb.locker.Lock()
+	breakers := make([]*Breaker, 0, len(cb.breakers))
+	for _, b := range cb.breakers {
+		breakers = append(breakers, b)
+	}
+	cb.locker.Unlock()
+	result := make(map[string]State, len(breakers))
+	for _, b := range breakers {
+		result[b.Key] = b.State()
+	}
+	return result
+}
+
+//ServeMiddleware serve as middleware.
+//Response status codes and panics of next are recorded as circuit results.
+func (cb *CircuitBreaker) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key, err := cb.Key.IdentifyRequest(r)
+	if err != nil {
+		panic(err)
+	}
+	if key == "" {
+		next(w, r)
+		return
+	}
+	b := cb.Breaker(key)
+	done, ok := b.Allow()
+	if !ok {
+		retry := (b.RetryAfter() + time.Second - 1) / time.Second
+		if retry > 0 {
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(retry), 10))
+		}
+		if cb.Fallback != nil {
+			cb.Fallback(w, r)
+			return
+		}
+		DefaultFallback(w, r)
+		return
+	}
+	resp := httpinfo.NewResponse()
+	finished := false
+	defer func() {
+		if !finished {
+			done(true)
+		}
+	}()
+	next(resp.WrapWriter(w), r)
+	finished = true
+	done(cb.FailureStatus(resp.StatusCode))
+}
+
+//New create new circuit breaker which keys circuits by route name.
+func New() *CircuitBreaker {
+	return &CircuitBreaker{
+		Key:           RouteIdentifier,
+		MaxFailures:   5,
+		OpenTimeout:   30 * time.Second,
+		FailureStatus: DefaultFailureStatus,
+		Clock:         SystemClock,
+		breakers:      map[string]*Breaker{},
+	}
+}
@@ -0,0 +1,233 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/middleware/router"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+type testClock struct {
+	locker sync.Mutex
+	now    time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newClock() *testClock {
+	return &testClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := newClock()
+	cb := New()
+	cb.Clock = clock
+	cb.MaxFailures = 2
+	cb.OpenTimeout = 10 * time.Second
+	cb.SuccessThreshold = 2
+	changes := []string{}
+	cb.OnStateChange = func(key string, from State, to State) {
+		changes = append(changes, key+":"+from.String()+">"+to.String())
+	}
+	status := 500
+	var called int
+	app := middleware.New(router.NewRouteNameMiddleware("api"), cb.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.WriteHeader(status)
+	})
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		return rec
+	}
+	serve()
+	if cb.Breaker("api").State() != StateClosed {
+		t.Fatal(cb.Breaker("api").State())
+	}
+	serve()
+	if cb.Breaker("api").State() != StateOpen || called != 2 {
+		t.Fatal(cb.Breaker("api").State(), called)
+	}
+	rec := serve()
+	if rec.Code != 503 || rec.Header().Get("Retry-After") != "10" || called != 2 {
+		t.Fatal(rec.Code, rec.Header(), called)
+	}
+	clock.Add(4500 * time.Millisecond)
+	rec = serve()
+	if rec.Header().Get("Retry-After") != "6" {
+		t.Fatal(rec.Header())
+	}
+	clock.Add(6 * time.Second)
+	if cb.Breaker("api").State() != StateHalfOpen {
+		t.Fatal(cb.Breaker("api").State())
+	}
+	serve()
+	if cb.Breaker("api").State() != StateOpen || called != 3 {
+		t.Fatal(cb.Breaker("api").State(), called)
+	}
+	clock.Add(10 * time.Second)
+	status = 200
+	serve()
+	if cb.Breaker("api").State() != StateHalfOpen {
+		t.Fatal(cb.Breaker("api").State())
+	}
+	serve()
+	if cb.Breaker("api").State() != StateClosed || called != 5 {
+		t.Fatal(cb.Breaker("api").State(), called)
+	}
+	expected := []string{"api:closed>open", "api:open>half-open", "api:half-open>open", "api:open>half-open", "api:half-open>closed"}
+	if len(changes) != len(expected) {
+		t.Fatal(changes)
+	}
+	for k := range expected {
+		if changes[k] != expected[k] {
+			t.Fatal(changes)
+		}
+	}
+	if cb.States()["api"] != StateClosed {
+		t.Fatal(cb.States())
+	}
+}
+
+func TestHalfOpenLimit(t *testing.T) {
+	clock := newClock()
+	cb := New()
+	cb.Clock = clock
+	cb.MaxFailures = 1
+	cb.OpenTimeout = time.Second
+	b := cb.Breaker("test")
+	done, ok := b.Allow()
+	if !ok {
+		t.Fatal(ok)
+	}
+	lateDone, _ := b.Allow()
+	done(true)
+	if b.State() != StateOpen {
+		t.Fatal(b.State())
+	}
+	clock.Add(time.Second)
+	trial, ok := b.Allow()
+	if !ok {
+		t.Fatal(ok)
+	}
+	_, ok = b.Allow()
+	if ok {
+		t.Fatal(ok)
+	}
+	lateDone(true)
+	if b.State() != StateHalfOpen {
+		t.Fatal(b.State())
+	}
+	trial(false)
+	trial(true)
+	if b.State() != StateClosed {
+		t.Fatal(b.State())
+	}
+}
+
+func TestPanic(t *testing.T) {
+	cb := New()
+	cb.Clock = newClock()
+	cb.MaxFailures = 1
+	cb.Key = Rules{{Name: "panic", Pattern: requestmatching.MustCreatePattern(&requestmatching.PatternConfig{URLList: []string{"/panic"}})}}
+	app := middleware.New(cb.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("test")
+		}
+		w.WriteHeader(500)
+	})
+	func() {
+		defer func() {
+			if r := recover(); r != "test" {
+				t.Fatal(r)
+			}
+		}()
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+	}()
+	if cb.Breaker("panic").State() != StateOpen {
+		t.Fatal(cb.Breaker("panic").State())
+	}
+	for i := 0; i < 3; i++ {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+	}
+	if len(cb.States()) != 1 {
+		t.Fatal(cb.States())
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	cb := New()
+	cb.MaxFailures = 1000000
+	b := cb.Breaker("test")
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				done, ok := b.Allow()
+				if ok {
+					done(j%2 == 0)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if b.State() != StateClosed {
+		t.Fatal(b.State())
+	}
+}
+
+func TestFactory(t *testing.T) {
+	body := "fallback"
+	data, err := json.Marshal(&Config{
+		Rules:              []*RuleConfig{{Name: "api", Pattern: &requestmatching.PatternConfig{PrefixList: []string{"/api/"}}}},
+		MaxFailures:        1,
+		FailureStatusCodes: []int{502},
+		Response:           &middlewarefactory.ResponseMiddleware{StatusCode: 503, Body: &body},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/500" {
+			w.WriteHeader(500)
+			return
+		}
+		w.WriteHeader(502)
+	})
+	for _, path := range []string{"/api/500", "/api/500", "/api/502", "/api/500"} {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code == 503 {
+			if path != "/api/500" || rec.Body.String() != "fallback" || rec.Header().Get("Retry-After") != "30" {
+				t.Fatal(path, rec.Body.String(), rec.Header())
+			}
+			return
+		}
+	}
+	t.Fatal("circuit not opened")
+}
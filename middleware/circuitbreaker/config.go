@@ -0,0 +1,102 @@
+package circuitbreaker
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//RuleConfig rule config
+type RuleConfig struct {
+	//Name rule name
+	Name string
+	//Pattern request pattern
+	Pattern *requestmatching.PatternConfig
+}
+
+//Config circuit breaker config
+type Config struct {
+	//Rules rules used to key circuits.
+	//Circuits are keyed by route name if empty.
+	Rules []*RuleConfig
+	//MaxFailures consecutive failures after which circuit opens.
+	MaxFailures int
+	//OpenTimeoutInSecond open timeout in second.
+	OpenTimeoutInSecond int64
+	//HalfOpenMaxRequests max concurrent trial requests while half-open.
+	HalfOpenMaxRequests int
+	//SuccessThreshold successful trial requests after which circuit closes.
+	SuccessThreshold int
+	//FailureStatusCodes status codes treated as failure.
+	//Status codes not less than 500 are used if empty.
+	FailureStatusCodes []int
+	//Response fallback response config.
+	//Status 503 will be returned if nil.
+	Response *middlewarefactory.ResponseMiddleware
+}
+
+//CreateCircuitBreaker create circuit breaker with config.
+//Return circuit breaker and any error if raised.
+func (c *Config) CreateCircuitBreaker() (*CircuitBreaker, error) {
+	cb := New()
+	if len(c.Rules) > 0 {
+		rules := Rules{}
+		for _, v := range c.Rules {
+			pc := v.Pattern
+			if pc == nil {
+				pc = &requestmatching.PatternConfig{}
+			}
+			p, err := pc.CreatePattern()
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, &Rule{Name: v.Name, Pattern: p})
+		}
+		cb.Key = rules
+	}
+	if c.MaxFailures > 0 {
+		cb.MaxFailures = c.MaxFailures
+	}
+	if c.OpenTimeoutInSecond > 0 {
+		cb.OpenTimeout = time.Duration(c.OpenTimeoutInSecond) * time.Second
+	}
+	cb.HalfOpenMaxRequests = c.HalfOpenMaxRequests
+	cb.SuccessThreshold = c.SuccessThreshold
+	if len(c.FailureStatusCodes) > 0 {
+		codes := c.FailureStatusCodes
+		cb.FailureStatus = func(statusCode int) bool {
+			for _, v := range codes {
+				if v == statusCode {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	if c.Response != nil {
+		resp := c.Response
+		cb.Fallback = func(w http.ResponseWriter, r *http.Request) {
+			resp.ServeMiddleware(w, r, nil)
+		}
+	}
+	return cb, nil
+}
+
+//NewFactory create new circuit breaker middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		cb, err := c.CreateCircuitBreaker()
+		if err != nil {
+			return nil, err
+		}
+		return cb.ServeMiddleware, nil
+	}
+}
@@ -0,0 +1,104 @@
+//Package conditional provide conditional request middleware.
+package conditional
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/herb-go/herb/middleware/httpinfo"
+)
+
+//Resolver func which returns current validators of requested resource cheaply,
+//without serving the request.
+//Nil validators should be returned if resource not exists.
+type Resolver func(r *http.Request) (*Validators, error)
+
+//Conditional conditional request middleware struct
+type Conditional struct {
+	//ETag whether entity tag should be generated from buffered body
+	//when handler does not supply one.
+	ETag bool
+	//Weak whether generated entity tag is weak.
+	Weak bool
+	//Range whether range requests over buffered body are served.
+	Range bool
+	//MaxBodySize max body size which will be buffered.
+	//Larger responses are streamed without conditional handling.
+	//Unlimited if not greater than 0.
+	MaxBodySize int
+	//Resolver optional resolver which returns validators before calling next.
+	//Preconditions of all methods,including If-Match and If-Unmodified-Since of unsafe methods,
+	//are evaluated against returned validators before next is called.
+	Resolver Resolver
+}
+
+func (c *Conditional) bufferable(r *http.Request, resp *httpinfo.Response) (bool, error) {
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	return c.MaxBodySize <= 0 || resp.ContentLength <= c.MaxBodySize, nil
+}
+
+//ServeMiddleware serve as middleware.
+//Successful GET and HEAD responses are buffered and evaluated against
+//ETag and Last-Modified headers set by handler or generated from body.
+func (c *Conditional) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if c.Resolver != nil {
+		v, err := c.Resolver(r)
+		if err != nil {
+			panic(err)
+		}
+		if Check(w, r, v) {
+			return
+		}
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		next(w, r)
+		return
+	}
+	resp := httpinfo.NewResponse()
+	resp.UpdateAutocommit(false)
+	resp.UpdateController(httpinfo.NewCommitController(r, resp).WithChecker(httpinfo.ValidatorFunc(c.bufferable)))
+	next(resp.WrapWriter(w), r)
+	if resp.Autocommit() || resp.StatusCode != http.StatusOK {
+		resp.Commit()
+		return
+	}
+	h := resp.Header()
+	body := resp.UncommittedData()
+	if c.ETag && h.Get("ETag") == "" {
+		h.Set("ETag", CreateETag(body, c.Weak))
+	}
+	v := ValidatorsFromHeader(h)
+	switch v.Evaluate(r) {
+	case http.StatusNotModified:
+		copyHeader(w.Header(), h)
+		writeNotModified(w)
+		return
+	case http.StatusPreconditionFailed:
+		writePreconditionFailed(w)
+		return
+	}
+	if c.Range {
+		h.Set("Accept-Ranges", "bytes")
+		if r.Header.Get("Range") != "" {
+			copyHeader(w.Header(), h)
+			http.ServeContent(w, r, "", v.LastModified, bytes.NewReader(body))
+			return
+		}
+	}
+	resp.Commit()
+}
+
+//New create new conditional middleware which generates strong entity tags.
+func New() *Conditional {
+	return &Conditional{
+		ETag: true,
+	}
+}
+
+func copyHeader(dst http.Header, src http.Header) {
+	for k := range src {
+		dst[k] = append([]string{}, src[k]...)
+	}
+}
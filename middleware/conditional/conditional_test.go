@@ -0,0 +1,187 @@
+package conditional
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+)
+
+var modtime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func request(app http.Handler, method string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", nil)
+	for k := range header {
+		req.Header[k] = header[k]
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestETag(t *testing.T) {
+	var called int
+	app := middleware.New(New().ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello"))
+	})
+	rec := request(app, "GET", nil)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != 200 || rec.Body.String() != "hello" || etag != CreateETag([]byte("hello"), false) {
+		t.Fatal(rec.Code, rec.Body.String(), etag)
+	}
+	rec = request(app, "GET", http.Header{"If-None-Match": []string{`"other", ` + etag}})
+	if rec.Code != 304 || rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "" || rec.Header().Get("ETag") != etag {
+		t.Fatal(rec.Code, rec.Body.String(), rec.Header())
+	}
+	rec = request(app, "GET", http.Header{"If-None-Match": []string{"W/" + etag}})
+	if rec.Code != 304 {
+		t.Fatal(rec.Code)
+	}
+	rec = request(app, "GET", http.Header{"If-Match": []string{"W/" + etag}})
+	if rec.Code != 412 {
+		t.Fatal(rec.Code)
+	}
+	rec = request(app, "GET", http.Header{"If-Match": []string{etag}})
+	if rec.Code != 200 || rec.Body.String() != "hello" {
+		t.Fatal(rec.Code)
+	}
+	rec = request(app, "POST", http.Header{"If-None-Match": []string{etag}})
+	if rec.Code != 200 || rec.Header().Get("ETag") != "" {
+		t.Fatal(rec.Code)
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	c := New()
+	c.ETag = false
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", modtime.Format(http.TimeFormat))
+		w.Write([]byte("hello"))
+	})
+	rec := request(app, "GET", http.Header{"If-Modified-Since": []string{modtime.Format(http.TimeFormat)}})
+	if rec.Code != 304 || rec.Header().Get("ETag") != "" || rec.Header().Get("Last-Modified") == "" {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	rec = request(app, "GET", http.Header{"If-Modified-Since": []string{modtime.Add(-time.Hour).Format(http.TimeFormat)}})
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	rec = request(app, "GET", http.Header{"If-Unmodified-Since": []string{modtime.Add(-time.Hour).Format(http.TimeFormat)}})
+	if rec.Code != 412 {
+		t.Fatal(rec.Code)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	var called int
+	c := New()
+	c.Resolver = func(r *http.Request) (*Validators, error) {
+		return &Validators{ETag: `"v1"`, LastModified: modtime}, nil
+	}
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.Write([]byte("updated"))
+	})
+	rec := request(app, "PUT", http.Header{"If-Match": []string{`"v0"`}})
+	if rec.Code != 412 || called != 0 {
+		t.Fatal(rec.Code, called)
+	}
+	rec = request(app, "PUT", http.Header{"If-Unmodified-Since": []string{modtime.Add(-time.Hour).Format(http.TimeFormat)}})
+	if rec.Code != 412 || called != 0 {
+		t.Fatal(rec.Code, called)
+	}
+	rec = request(app, "PUT", http.Header{"If-Match": []string{`"v1"`}})
+	if rec.Code != 200 || called != 1 {
+		t.Fatal(rec.Code, called)
+	}
+	rec = request(app, "GET", http.Header{"If-None-Match": []string{`"v1"`}})
+	if rec.Code != 304 || called != 1 {
+		t.Fatal(rec.Code, called)
+	}
+	c.Resolver = func(r *http.Request) (*Validators, error) {
+		return nil, nil
+	}
+	rec = request(app, "PUT", http.Header{"If-None-Match": []string{`*`}})
+	if rec.Code != 200 || called != 2 {
+		t.Fatal(rec.Code, called)
+	}
+	rec = request(app, "PUT", http.Header{"If-Match": []string{`*`}})
+	if rec.Code != 412 || called != 2 {
+		t.Fatal(rec.Code, called)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Check(w, r, &Validators{ETag: `W/"1"`}) {
+			return
+		}
+		w.Write([]byte("body"))
+	})
+	rec := request(app, "GET", http.Header{"If-None-Match": []string{`"1"`}})
+	if rec.Code != 304 || rec.Header().Get("ETag") != `W/"1"` {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	rec = request(app, "DELETE", http.Header{"If-Match": []string{`W/"1"`}})
+	if rec.Code != 412 {
+		t.Fatal(rec.Code)
+	}
+}
+
+func TestRange(t *testing.T) {
+	data, err := json.Marshal(&Config{Weak: true, Range: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("0123456789"))
+	})
+	rec := request(app, "GET", nil)
+	if rec.Code != 200 || rec.Header().Get("Accept-Ranges") != "bytes" || !isWeak(rec.Header().Get("ETag")) {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	rec = request(app, "GET", http.Header{"Range": []string{"bytes=2-4"}})
+	if rec.Code != 206 || rec.Body.String() != "234" || rec.Header().Get("Content-Range") != "bytes 2-4/10" {
+		t.Fatal(rec.Code, rec.Body.String(), rec.Header())
+	}
+}
+
+func TestStreamed(t *testing.T) {
+	c := New()
+	c.MaxBodySize = 4
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123"))
+		w.Write([]byte("4567"))
+		w.Write([]byte("89"))
+	})
+	rec := request(app, "GET", nil)
+	if rec.Code != 200 || rec.Body.String() != "0123456789" || rec.Header().Get("ETag") != "" {
+		t.Fatal(rec.Code, rec.Body.String(), rec.Header())
+	}
+	app = middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	rec = request(app, "GET", nil)
+	if rec.Code != 404 || rec.Header().Get("ETag") != "" {
+		t.Fatal(rec.Code, rec.Header())
+	}
+}
+
+func TestParseETags(t *testing.T) {
+	tags := ParseETags(` "a", W/"b,c" ,*, invalid`)
+	if len(tags) != 3 || tags[0] != `"a"` || tags[1] != `W/"b,c"` || tags[2] != "*" {
+		t.Fatal(tags)
+	}
+}
@@ -0,0 +1,40 @@
+package conditional
+
+import (
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+//Config conditional middleware config
+type Config struct {
+	//DisableETag whether entity tag generating is disabled.
+	DisableETag bool
+	//Weak whether generated entity tag is weak.
+	Weak bool
+	//Range whether range requests are served.
+	Range bool
+	//MaxBodySize max buffered body size.
+	MaxBodySize int
+}
+
+//CreateConditional create conditional middleware with config.
+func (c *Config) CreateConditional() *Conditional {
+	cond := New()
+	cond.ETag = !c.DisableETag
+	cond.Weak = c.Weak
+	cond.Range = c.Range
+	cond.MaxBodySize = c.MaxBodySize
+	return cond
+}
+
+//NewFactory create new conditional middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.CreateConditional().ServeMiddleware, nil
+	}
+}
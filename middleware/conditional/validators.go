@@ -0,0 +1,182 @@
+package conditional
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//Validators resource validators used to evaluate conditional requests.
+type Validators struct {
+	//ETag entity tag with quotes,for example "abc" or W/"abc".
+	//Empty if resource has no entity tag.
+	ETag string
+	//LastModified resource modification time.
+	//Zero if resource has no modification time.
+	LastModified time.Time
+}
+
+//Apply set validator headers to given header.
+func (v *Validators) Apply(h http.Header) {
+	if v.ETag != "" {
+		h.Set("ETag", v.ETag)
+	}
+	if !v.LastModified.IsZero() {
+		h.Set("Last-Modified", v.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+//Evaluate evaluate conditional request headers against validators.
+//Nil validators means resource not exists.
+//Return http.StatusNotModified or http.StatusPreconditionFailed if request should not be served,
+//or 0 if request should be served normally.
+func (v *Validators) Evaluate(r *http.Request) int {
+	safe := r.Method == http.MethodGet || r.Method == http.MethodHead
+	if im := r.Header.Get("If-Match"); im != "" {
+		if !v.match(im, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if ius := r.Header.Get("If-Unmodified-Since"); ius != "" && v != nil && !v.LastModified.IsZero() {
+		t, err := http.ParseTime(ius)
+		if err == nil && v.LastModified.Truncate(time.Second).After(t) {
+			return http.StatusPreconditionFailed
+		}
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if v.match(inm, true) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && safe && v != nil && !v.LastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err == nil && !v.LastModified.Truncate(time.Second).After(t) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+func (v *Validators) match(list string, weak bool) bool {
+	if v == nil {
+		return false
+	}
+	for _, tag := range ParseETags(list) {
+		if tag == "*" {
+			return true
+		}
+		if v.ETag == "" {
+			continue
+		}
+		if weak {
+			if opaqueTag(tag) == opaqueTag(v.ETag) {
+				return true
+			}
+		} else if !isWeak(tag) && !isWeak(v.ETag) && tag == v.ETag {
+			return true
+		}
+	}
+	return false
+}
+
+func isWeak(tag string) bool {
+	return strings.HasPrefix(tag, "W/")
+}
+
+func opaqueTag(tag string) string {
+	return strings.TrimPrefix(tag, "W/")
+}
+
+//ParseETags parse entity tag list from If-Match or If-None-Match header value.
+//Invalid tags will be ignored.
+func ParseETags(list string) []string {
+	result := []string{}
+	for {
+		list = strings.TrimLeft(list, " \t,")
+		if list == "" {
+			return result
+		}
+		if list[0] == '*' {
+			result = append(result, "*")
+			list = list[1:]
+			continue
+		}
+		prefix := ""
+		if strings.HasPrefix(list, "W/") {
+			prefix = "W/"
+			list = list[2:]
+		}
+		if list == "" || list[0] != '"' {
+			return result
+		}
+		end := strings.IndexByte(list[1:], '"')
+		if end < 0 {
+			return result
+		}
+		result = append(result, prefix+list[:end+2])
+		list = list[end+2:]
+	}
+}
+
+//CreateETag create entity tag by hashing given data.
+//Weak entity tag will be created if weak is true.
+func CreateETag(data []byte, weak bool) string {
+	hash := sha256.Sum256(data)
+	tag := `"` + hex.EncodeToString(hash[:16]) + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+//ValidatorsFromHeader create validators from ETag and Last-Modified headers.
+func ValidatorsFromHeader(h http.Header) *Validators {
+	v := &Validators{
+		ETag: h.Get("ETag"),
+	}
+	if lm := h.Get("Last-Modified"); lm != "" {
+		t, err := http.ParseTime(lm)
+		if err == nil {
+			v.LastModified = t
+		}
+	}
+	return v
+}
+
+func writeNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	if h.Get("ETag") != "" {
+		h.Del("Last-Modified")
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+func writePreconditionFailed(w http.ResponseWriter) {
+	http.Error(w, http.StatusText(http.StatusPreconditionFailed), http.StatusPreconditionFailed)
+}
+
+//Check evaluate conditional request with given validators and write response if request should not be served.
+//Handlers can call Check with cheaply computed validators before doing expensive work.
+//Validator headers will be set to response.
+//Nil validators means resource not exists.
+//Return true if response is written.
+func Check(w http.ResponseWriter, r *http.Request, v *Validators) bool {
+	if v != nil {
+		v.Apply(w.Header())
+	}
+	switch v.Evaluate(r) {
+	case http.StatusNotModified:
+		writeNotModified(w)
+		return true
+	case http.StatusPreconditionFailed:
+		writePreconditionFailed(w)
+		return true
+	}
+	return false
+}
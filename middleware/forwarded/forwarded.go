@@ -1,6 +1,7 @@
 package forwarded
 
 import (
+	"net"
 	"net/http"
 	"strings"
 )
@@ -65,7 +66,7 @@ func (m *Middleware) ServeMiddleware(w http.ResponseWriter, r *http.Request, nex
 	if m.ForwardedForHeader != "" {
 		forwardedFor := headers.Get(m.ForwardedForHeader)
 		if forwardedFor != "" {
-			r.RemoteAddr = strings.TrimSpace(strings.Split(forwardedFor, ",")[0]) + ":-1"
+			r.RemoteAddr = net.JoinHostPort(strings.TrimSpace(strings.Split(forwardedFor, ",")[0]), "-1")
 		}
 	}
 	if m.ForwardedProtoHeader != "" {
@@ -18,6 +18,8 @@ type Response struct {
 	buffer        *bytes.Buffer
 	controller    Controller
 	locked        bool
+	headerCalled  bool
+	headerWritten bool
 }
 
 //NewResponse create new response
@@ -37,11 +39,29 @@ func (resp *Response) flushHeader() {
 		}
 	}
 }
+func (resp *Response) writeHeader() {
+	if resp.headerWritten {
+		return
+	}
+	resp.headerWritten = true
+	resp.flushHeader()
+	resp.writer.WriteHeader(resp.StatusCode)
+}
+
+//writeHeaderFunc record status code.
+//Only first call takes effect like http.ResponseWriter,
+//header will be written immediately in autocommit mode.
 func (resp *Response) writeHeaderFunc(statusCode int) {
 	resp.locked = true
+	if resp.headerCalled {
+		return
+	}
+	resp.headerCalled = true
 	resp.StatusCode = statusCode
 	resp.controller.BeforeWriteHeader()
-
+	if resp.autocommit {
+		resp.writeHeader()
+	}
 }
 
 func (resp *Response) writeFunc(data []byte) (int, error) {
@@ -50,9 +70,9 @@ func (resp *Response) writeFunc(data []byte) (int, error) {
 	resp.locked = true
 	if !resp.Written {
 		resp.Written = true
+		resp.headerCalled = true
 		if resp.autocommit {
-			resp.flushHeader()
-			resp.writer.WriteHeader(resp.StatusCode)
+			resp.writeHeader()
 		}
 	}
 	resp.controller.BeforeWrite()
@@ -93,16 +113,24 @@ func (resp *Response) UncommittedData() []byte {
 func (resp *Response) SetUncommittedData(data []byte) {
 	resp.buffer = bytes.NewBuffer(data)
 }
+
+//Commit write buffered header and data to response writer and turn on autocommit.
+//Header will only be copied if neither WriteHeader nor Write called,
+//so that it will be sent with status code written later.
+//Return any error if raised.
 func (resp *Response) Commit() error {
 	if resp.autocommit {
 		return nil
 	}
 	resp.autocommit = true
-	resp.flushHeader()
+	if !resp.headerCalled {
+		resp.flushHeader()
+		return nil
+	}
+	resp.writeHeader()
 	if !resp.Written {
 		return nil
 	}
-	resp.writer.WriteHeader(resp.StatusCode)
 	_, err := resp.writer.Write(resp.buffer.Bytes())
 	return err
 }
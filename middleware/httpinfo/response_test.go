@@ -378,3 +378,60 @@ func TestResponse(t *testing.T) {
 		t.Fatal(string(data))
 	}
 }
+
+func TestResponseWriteHeaderOnly(t *testing.T) {
+	app := middleware.New(respMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("testfield", "testvalue")
+		w.WriteHeader(http.StatusNotModified)
+		w.WriteHeader(http.StatusOK)
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNotModified || rec.Header().Get("testfield") != "testvalue" || len(rec.Header()["Testfield"]) != 1 {
+		t.Fatal(rec)
+	}
+	if lastresp.StatusCode != http.StatusNotModified || lastresp.Written {
+		t.Fatal(lastresp)
+	}
+	app = middleware.New(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		lastresp = NewResponse()
+		lastresp.UpdateAutocommit(false)
+		next(lastresp.WrapWriter(w), r)
+		if w.(*httptest.ResponseRecorder).Code != 200 {
+			t.Fatal(w)
+		}
+		err := lastresp.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Fatal(rec)
+	}
+}
+
+func TestResponseCommitHeaderOnly(t *testing.T) {
+	app := middleware.New(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		lastresp = NewResponse()
+		lastresp.UpdateAutocommit(false)
+		next(lastresp.WrapWriter(w), r)
+		err := lastresp.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("testfield", "testvalue")
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("testfield") != "testvalue" || rec.Body.Len() != 0 {
+		t.Fatal(rec)
+	}
+	if lastresp.Written || !lastresp.Autocommit() {
+		t.Fatal(lastresp)
+	}
+}
@@ -0,0 +1,237 @@
+//Package instrument provide per middleware timing and tracing for middleware apps.
+package instrument
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"runtime"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//Span attribute keys
+const (
+	AttributeMiddlewareName     = "herb.middleware.name"
+	AttributeNextCalled         = "herb.middleware.next_called"
+	AttributeSelfDuration       = "herb.middleware.self_duration_ns"
+	AttributeHTTPStatusCode     = "http.status_code"
+	AttributeMiddlewarePanicked = "herb.middleware.panicked"
+)
+
+//Record middleware execution record
+type Record struct {
+	//Name middleware name
+	Name string
+	//Start time when middleware started
+	Start time.Time
+	//Duration time spent in middleware,including time spent in next.
+	Duration time.Duration
+	//SelfDuration time spent in middleware itself,excluding time spent in next.
+	SelfDuration time.Duration
+	//NextCalled whether middleware called next.
+	NextCalled bool
+	//StatusCode status code observed by middleware.
+	//Zero if nothing written.
+	StatusCode int
+	//Panicked whether middleware panicked.
+	Panicked bool
+}
+
+//Recorder middleware execution recorder interface
+type Recorder interface {
+	//Record record middleware execution.
+	Record(r *http.Request, rec *Record)
+}
+
+//RecorderFunc recorder func type
+type RecorderFunc func(r *http.Request, rec *Record)
+
+//Record record middleware execution.
+func (f RecorderFunc) Record(r *http.Request, rec *Record) {
+	f(r, rec)
+}
+
+type contextKey struct {
+	instrumentation *Instrumentation
+}
+
+//Instrumentation instrumentation layer which records timing and tracing spans for middlewares.
+type Instrumentation struct {
+	//Tracer tracer used to create spans.
+	//NopTracer will be used if nil.
+	Tracer Tracer
+	//Recorder recorder which receives execution records.
+	//Records will be dropped if nil.
+	Recorder Recorder
+	//Pattern only requests matching pattern will be instrumented.
+	//All requests will be instrumented if nil.
+	Pattern requestmatching.Pattern
+}
+
+func (i *Instrumentation) enabled(r *http.Request) bool {
+	if i.Pattern == nil {
+		return true
+	}
+	key := contextKey{instrumentation: i}
+	v := r.Context().Value(key)
+	if v != nil {
+		return v.(bool)
+	}
+	result := requestmatching.MustMatch(r, i.Pattern)
+	ctx := context.WithValue(r.Context(), key, result)
+	*r = *r.WithContext(ctx)
+	return result
+}
+
+func (i *Instrumentation) tracer() Tracer {
+	if i.Tracer == nil {
+		return NopTracer
+	}
+	return i.Tracer
+}
+
+func (i *Instrumentation) finish(r *http.Request, span Span, rec *Record) {
+	span.SetAttributes(
+		Attribute{Key: AttributeMiddlewareName, Value: rec.Name},
+		Attribute{Key: AttributeNextCalled, Value: rec.NextCalled},
+		Attribute{Key: AttributeSelfDuration, Value: int64(rec.SelfDuration)},
+		Attribute{Key: AttributeHTTPStatusCode, Value: rec.StatusCode},
+	)
+	if rec.Panicked {
+		span.SetAttributes(Attribute{Key: AttributeMiddlewarePanicked, Value: true})
+		span.SetStatus(true, "panic")
+	} else if rec.StatusCode >= 500 {
+		span.SetStatus(true, http.StatusText(rec.StatusCode))
+	}
+	span.End()
+	if i.Recorder != nil {
+		i.Recorder.Record(r, rec)
+	}
+}
+
+//Wrap wrap middleware with given name.
+//Return instrumented middleware.
+func (i *Instrumentation) Wrap(name string, m func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)) func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if m == nil {
+		return nil
+	}
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if !i.enabled(r) {
+			m(w, r, next)
+			return
+		}
+		ctx, span := i.tracer().Start(r.Context(), name)
+		rec := &Record{
+			Name:  name,
+			Start: time.Now(),
+		}
+		var nextDuration time.Duration
+		writer := newStatusWriter(w, rec)
+		finished := false
+		defer func() {
+			if finished {
+				return
+			}
+			rec.Duration = time.Since(rec.Start)
+			rec.SelfDuration = rec.Duration - nextDuration
+			rec.Panicked = true
+			i.finish(r, span, rec)
+		}()
+		m(writer, r.WithContext(ctx), func(w http.ResponseWriter, r *http.Request) {
+			rec.NextCalled = true
+			start := time.Now()
+			defer func() {
+				nextDuration = nextDuration + time.Since(start)
+			}()
+			next(w, r)
+		})
+		finished = true
+		rec.Duration = time.Since(rec.Start)
+		rec.SelfDuration = rec.Duration - nextDuration
+		i.finish(r, span, rec)
+	}
+}
+
+//WrapHandlers wrap all handlers in chain with names generated from function names.
+func (i *Instrumentation) WrapHandlers(chain middleware.HandlerChain) {
+	handlers := chain.Handlers()
+	result := make([]func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc), len(handlers))
+	for k := range handlers {
+		result[k] = i.Wrap(Name(handlers[k]), handlers[k])
+	}
+	chain.SetHandlers(result)
+}
+
+//App create new middleware app with given named middlewares.
+func (i *Instrumentation) App(middlewares ...*NamedMiddleware) *middleware.App {
+	app := middleware.New()
+	for k := range middlewares {
+		app.Use(i.Wrap(middlewares[k].Name, middlewares[k].Middleware))
+	}
+	return app
+}
+
+//NamedMiddleware middleware with name
+type NamedMiddleware struct {
+	Name       string
+	Middleware func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)
+}
+
+//Named create named middleware
+func Named(name string, m func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)) *NamedMiddleware {
+	return &NamedMiddleware{
+		Name:       name,
+		Middleware: m,
+	}
+}
+
+//Name return readable name of middleware function.
+func Name(m func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)) string {
+	if m == nil {
+		return ""
+	}
+	f := runtime.FuncForPC(reflect.ValueOf(m).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	return f.Name()
+}
+
+//New create new instrumentation.
+func New() *Instrumentation {
+	return &Instrumentation{}
+}
+
+type statusWriter struct {
+	writer http.ResponseWriter
+	record *Record
+}
+
+func (w *statusWriter) WriteHeader(statusCode int) {
+	if w.record.StatusCode == 0 {
+		w.record.StatusCode = statusCode
+	}
+	w.writer.WriteHeader(statusCode)
+}
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	if w.record.StatusCode == 0 {
+		w.record.StatusCode = http.StatusOK
+	}
+	return w.writer.Write(data)
+}
+
+func newStatusWriter(w http.ResponseWriter, rec *Record) http.ResponseWriter {
+	sw := &statusWriter{
+		writer: w,
+		record: rec,
+	}
+	writer := middleware.WrapResponseWriter(w)
+	f := writer.Functions()
+	f.WriteHeaderFunc = sw.WriteHeader
+	f.WriteFunc = sw.Write
+	return writer
+}
@@ -0,0 +1,168 @@
+package instrument
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+type testSpan struct {
+	name       string
+	parent     *testSpan
+	attributes map[string]interface{}
+	err        bool
+	ended      bool
+}
+
+func (s *testSpan) SetAttributes(attrs ...Attribute) {
+	for _, v := range attrs {
+		s.attributes[v.Key] = v.Value
+	}
+}
+
+func (s *testSpan) SetStatus(err bool, description string) {
+	s.err = err
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+type testSpanKey struct{}
+
+type testTracer struct {
+	locker sync.Mutex
+	spans  []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+	s := &testSpan{
+		name:       name,
+		attributes: map[string]interface{}{},
+	}
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	s.parent = parent
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, testSpanKey{}, s), s
+}
+
+func slowMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	time.Sleep(10 * time.Millisecond)
+	next(w, r)
+}
+
+func forbiddenMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	http.Error(w, http.StatusText(403), 403)
+}
+
+func TestInstrumentation(t *testing.T) {
+	tracer := &testTracer{}
+	records := []*Record{}
+	i := New()
+	i.Tracer = tracer
+	i.Recorder = RecorderFunc(func(r *http.Request, rec *Record) {
+		records = append(records, rec)
+	})
+	app := i.App(
+		Named("slow", slowMiddleware),
+		Named("forbidden", forbiddenMiddleware),
+	).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 403 {
+		t.Fatal(rec.Code)
+	}
+	if len(records) != 2 || len(tracer.spans) != 2 {
+		t.Fatal(records, tracer.spans)
+	}
+	forbidden := records[0]
+	slow := records[1]
+	if forbidden.Name != "forbidden" || forbidden.NextCalled || forbidden.StatusCode != 403 {
+		t.Fatal(forbidden)
+	}
+	if slow.Name != "slow" || !slow.NextCalled || slow.StatusCode != 403 {
+		t.Fatal(slow)
+	}
+	if slow.Duration < 10*time.Millisecond || slow.SelfDuration < 10*time.Millisecond || slow.SelfDuration > slow.Duration {
+		t.Fatal(slow)
+	}
+	slowSpan := tracer.spans[0]
+	forbiddenSpan := tracer.spans[1]
+	if slowSpan.name != "slow" || slowSpan.parent != nil || !slowSpan.ended {
+		t.Fatal(slowSpan)
+	}
+	if forbiddenSpan.parent != slowSpan || forbiddenSpan.attributes[AttributeHTTPStatusCode] != 403 || forbiddenSpan.attributes[AttributeNextCalled] != false {
+		t.Fatal(forbiddenSpan)
+	}
+}
+
+func TestInstrumentationPanic(t *testing.T) {
+	tracer := &testTracer{}
+	i := New()
+	i.Tracer = tracer
+	app := i.App(Named("panic", func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		panic("test")
+	}))
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal()
+			}
+		}()
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	if len(tracer.spans) != 1 || !tracer.spans[0].err || !tracer.spans[0].ended || tracer.spans[0].attributes[AttributeMiddlewarePanicked] != true {
+		t.Fatal(tracer.spans)
+	}
+}
+
+func TestInstrumentationPattern(t *testing.T) {
+	tracer := &testTracer{}
+	i := New()
+	i.Tracer = tracer
+	p := requestmatching.NewPlainPattern()
+	p.Paths.Add("/traced")
+	i.Pattern = p
+	app := middleware.New(slowMiddleware, nil).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	i.WrapHandlers(app)
+	if len(app.Handlers()) != 3 || app.Handlers()[1] != nil {
+		t.Fatal(app.Handlers())
+	}
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/untraced", nil))
+	if len(tracer.spans) != 0 {
+		t.Fatal(tracer.spans)
+	}
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/traced", nil))
+	if len(tracer.spans) != 2 {
+		t.Fatal(tracer.spans)
+	}
+	if tracer.spans[0].name != Name(slowMiddleware) || tracer.spans[1].attributes[AttributeHTTPStatusCode] != 200 {
+		t.Fatal(tracer.spans[0])
+	}
+}
+
+func TestNopTracer(t *testing.T) {
+	ctx := context.Background()
+	c, s := NopTracer.Start(ctx, "test")
+	if c != ctx || s != NopSpan {
+		t.Fatal(c, s)
+	}
+	s.SetAttributes(Attribute{Key: "key", Value: "value"})
+	s.SetStatus(true, "")
+	s.End()
+	if Name(nil) != "" {
+		t.Fatal(Name(nil))
+	}
+}
@@ -0,0 +1,50 @@
+package instrument
+
+import "context"
+
+//Attribute span attribute
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+//Span tracing span interface.
+//Span is designed to be easily adapted to OpenTelemetry span.
+type Span interface {
+	//SetAttributes set attributes to span.
+	SetAttributes(attrs ...Attribute)
+	//SetStatus set span status.
+	//Error should be true if span failed.
+	SetStatus(err bool, description string)
+	//End end span.
+	End()
+}
+
+//Tracer tracer interface.
+//Tracer is designed to be easily adapted to OpenTelemetry tracer.
+type Tracer interface {
+	//Start start span with given name as child of span in context.
+	//Return context which contains new span and span created.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type nopSpan struct{}
+
+func (s nopSpan) SetAttributes(attrs ...Attribute) {}
+
+func (s nopSpan) SetStatus(err bool, description string) {}
+
+func (s nopSpan) End() {}
+
+//NopSpan span which does nothing
+var NopSpan Span = nopSpan{}
+
+type nopTracer struct{}
+
+func (t nopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, NopSpan
+}
+
+//NopTracer tracer which does nothing.
+//NopTracer is the default tracer.
+var NopTracer Tracer = nopTracer{}
@@ -0,0 +1,103 @@
+package ipfilter
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+//ErrUnknownAction error raised when action name is unknown.
+var ErrUnknownAction = errors.New("ipfilter:unknown action")
+
+//ParseAction parse action name "allow" or "deny".
+//Return action and any error if raised.
+func ParseAction(name string) (Action, error) {
+	switch name {
+	case "allow":
+		return ActionAllow, nil
+	case "deny":
+		return ActionDeny, nil
+	}
+	return ActionDeny, ErrUnknownAction
+}
+
+//RuleConfig rule config
+type RuleConfig struct {
+	//Action action name,"allow" or "deny"
+	Action string
+	//IPList ip or cidr patterns
+	IPList []string
+	//File list file path.
+	//IPList will be ignored if file set.
+	File string
+}
+
+//Config ip filter config
+type Config struct {
+	//Rules ordered rules
+	Rules []*RuleConfig
+	//Default default action name,"allow" or "deny".
+	//"allow" will be used if empty.
+	Default string
+	//ReloadIntervalInSecond interval in second in which list files are checked.
+	ReloadIntervalInSecond int64
+	//Response rejection response config.
+	//Status 403 will be returned if nil.
+	Response *middlewarefactory.ResponseMiddleware
+}
+
+//CreateFilter create filter with config.
+//Return filter and any error if raised.
+func (c *Config) CreateFilter() (*Filter, error) {
+	f := New()
+	if c.Default != "" {
+		action, err := ParseAction(c.Default)
+		if err != nil {
+			return nil, err
+		}
+		f.DefaultAction = action
+	}
+	for _, v := range c.Rules {
+		action, err := ParseAction(v.Action)
+		if err != nil {
+			return nil, err
+		}
+		var r *Rule
+		if v.File != "" {
+			r, err = NewFileRule(action, v.File)
+		} else {
+			r, err = NewRule(action, v.IPList...)
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.Rules = append(f.Rules, r)
+	}
+	f.ReloadInterval = time.Duration(c.ReloadIntervalInSecond) * time.Second
+	if c.Response != nil {
+		resp := c.Response
+		f.OnReject = func(w http.ResponseWriter, r *http.Request) {
+			resp.ServeMiddleware(w, r, nil)
+		}
+	}
+	return f, nil
+}
+
+//NewFactory create new ip filter middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		f, err := c.CreateFilter()
+		if err != nil {
+			return nil, err
+		}
+		return f.ServeMiddleware, nil
+	}
+}
@@ -0,0 +1,213 @@
+//Package ipfilter provide ip access control middleware.
+package ipfilter
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//Action rule action
+type Action int
+
+//Rule actions.
+const (
+	//ActionDeny reject matched request.
+	ActionDeny = Action(0)
+	//ActionAllow allow matched request.
+	ActionAllow = Action(1)
+)
+
+//DefaultRejectHandler default handler serving rejected request.
+//Status 403 will be returned.
+var DefaultRejectHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+})
+
+//Rule ordered filter rule
+type Rule struct {
+	//Action action when ip matched
+	Action Action
+	//Path list file path.
+	//Rule is static if empty.
+	Path    string
+	list    atomic.Value
+	locker  sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+//List return current ip list of rule.
+func (r *Rule) List() *List {
+	l, _ := r.list.Load().(*List)
+	return l
+}
+
+//SetList replace ip list of rule.
+func (r *Rule) SetList(l *List) {
+	r.list.Store(l)
+}
+
+//Reload reload list from file if file changed since last load.
+//Return whether list reloaded and any error if raised.
+func (r *Rule) Reload() (bool, error) {
+	if r.Path == "" {
+		return false, nil
+	}
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	info, err := os.Stat(r.Path)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(r.modTime) && info.Size() == r.size && r.List() != nil {
+		return false, nil
+	}
+	file, err := os.Open(r.Path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	l, err := ParseList(file)
+	if err != nil {
+		return false, err
+	}
+	r.SetList(l)
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	return true, nil
+}
+
+//Match check if given ip is in rule list.
+func (r *Rule) Match(ip net.IP) bool {
+	l := r.List()
+	return l != nil && l.Contains(ip)
+}
+
+//NewRule create new static rule with given action and ip or cidr patterns.
+//Return rule and any error if raised.
+func NewRule(action Action, patterns ...string) (*Rule, error) {
+	l, err := NewList(patterns...)
+	if err != nil {
+		return nil, err
+	}
+	r := &Rule{Action: action}
+	r.SetList(l)
+	return r, nil
+}
+
+//NewFileRule create new rule with given action and list file path.
+//List will be loaded immediately.
+//Return rule and any error if raised.
+func NewFileRule(action Action, path string) (*Rule, error) {
+	r := &Rule{Action: action, Path: path}
+	_, err := r.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//Filter ip filter middleware struct
+type Filter struct {
+	//Rules ordered rules.
+	//Action of first matched rule will be used.
+	Rules []*Rule
+	//DefaultAction action used when no rule matched
+	DefaultAction Action
+	//OnReject handler serving rejected request.
+	//DefaultRejectHandler will be used if nil.
+	OnReject http.HandlerFunc
+	//ReloadInterval interval in which file rules are checked for changes while serving requests.
+	//Files will not be checked automatically if not greater than 0.
+	ReloadInterval time.Duration
+	//OnError func called when reloading failed.
+	//Previous list will be kept.
+	OnError   func(err error)
+	lastCheck int64
+	reloading int32
+}
+
+//Allowed check if given ip is allowed.
+//Invalid ip only matches default action.
+func (f *Filter) Allowed(ip net.IP) bool {
+	if ip != nil {
+		for _, r := range f.Rules {
+			if r.Match(ip) {
+				return r.Action == ActionAllow
+			}
+		}
+	}
+	return f.DefaultAction == ActionAllow
+}
+
+//Reload reload all file rules changed since last load.
+//Return any error if raised.
+func (f *Filter) Reload() error {
+	for _, r := range f.Rules {
+		_, err := r.Reload()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Filter) checkReload() {
+	if f.ReloadInterval <= 0 {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&f.lastCheck)
+	if now-last < int64(f.ReloadInterval) || !atomic.CompareAndSwapInt64(&f.lastCheck, last, now) {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&f.reloading, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&f.reloading, 0)
+		err := f.Reload()
+		if err != nil && f.OnError != nil {
+			f.OnError(err)
+		}
+	}()
+}
+
+//ServeMiddleware serve as middleware.
+func (f *Filter) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	f.checkReload()
+	if f.Allowed(ClientIP(r)) {
+		next(w, r)
+		return
+	}
+	if f.OnReject != nil {
+		f.OnReject(w, r)
+		return
+	}
+	DefaultRejectHandler(w, r)
+}
+
+//New create new filter which allows requests matching no rule.
+func New(rules ...*Rule) *Filter {
+	return &Filter{
+		Rules:         rules,
+		DefaultAction: ActionAllow,
+	}
+}
+
+//ClientIP return client ip of request.
+//Remote address rewritten by forwarded middleware is supported,
+//including unbracketed IPv6 address.
+//Return nil if remote address is invalid.
+func ClientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = strings.TrimSuffix(r.RemoteAddr, ":-1")
+	}
+	return net.ParseIP(strings.Trim(host, "[]"))
+}
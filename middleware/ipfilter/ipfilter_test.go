@@ -0,0 +1,260 @@
+package ipfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/forwarded"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+func TestList(t *testing.T) {
+	l, err := NewList("10.0.0.0/8", "192.168.1.1", "192.168.1.2", "2001:db8::/32", "::1", "10.1.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Len() != 4 {
+		t.Fatal(l.Len())
+	}
+	for _, ip := range []string{"10.0.0.0", "10.255.255.255", "10.1.2.3", "192.168.1.1", "192.168.1.2", "2001:db8::1", "2001:db8:ffff::1", "::1"} {
+		if !l.Contains(net.ParseIP(ip)) {
+			t.Fatal(ip)
+		}
+	}
+	for _, ip := range []string{"9.255.255.255", "11.0.0.0", "192.168.1.3", "2001:db9::1", "::2"} {
+		if l.Contains(net.ParseIP(ip)) {
+			t.Fatal(ip)
+		}
+	}
+	if l.Contains(nil) {
+		t.Fatal()
+	}
+	_, err = NewList("invalid")
+	if err != ErrInvalidPattern {
+		t.Fatal(err)
+	}
+	_, err = NewList("1.2.3.4/33")
+	if err != ErrInvalidPattern {
+		t.Fatal(err)
+	}
+}
+
+func TestListRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	patterns := []string{}
+	nets := []*net.IPNet{}
+	for i := 0; i < 2000; i++ {
+		ip := net.IPv4(10, byte(rnd.Intn(4)), byte(rnd.Intn(256)), byte(rnd.Intn(256)))
+		pattern := fmt.Sprintf("%s/%d", ip, 16+rnd.Intn(17))
+		_, n, _ := net.ParseCIDR(pattern)
+		patterns = append(patterns, pattern)
+		nets = append(nets, n)
+	}
+	l, err := NewList(patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10000; i++ {
+		ip := net.IPv4(10, byte(rnd.Intn(5)), byte(rnd.Intn(256)), byte(rnd.Intn(256)))
+		expected := false
+		for _, n := range nets {
+			if n.Contains(ip) {
+				expected = true
+				break
+			}
+		}
+		if l.Contains(ip) != expected {
+			t.Fatal(ip, expected)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	allow, err := NewRule(ActionAllow, "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deny, err := NewRule(ActionDeny, "10.0.0.0/8", "2001:db8::/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := New(allow, deny)
+	fw := forwarded.New()
+	fw.Enabled = true
+	fw.ForwardedForHeader = "X-Forwarded-For"
+	app := middleware.New(fw.ServeMiddleware, f.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for ip, code := range map[string]int{"10.0.0.1": 200, "10.0.0.2": 403, "127.0.0.1": 200, "2001:db8::1": 403, "2001:db9::1": 200} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Forwarded-For", ip+", 127.0.0.1")
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		if rec.Code != code {
+			t.Fatal(ip, rec.Code)
+		}
+	}
+	f.DefaultAction = ActionDeny
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 403 {
+		t.Fatal(rec.Code)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "2001:db9::1:-1"
+	if ClientIP(req).String() != "2001:db9::1" {
+		t.Fatal(ClientIP(req))
+	}
+}
+
+func TestForwardedIP(t *testing.T) {
+	deny, err := NewRule(ActionDeny, "10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := New(deny)
+	fw := forwarded.New()
+	fw.Enabled = true
+	fw.ForwardedForHeader = "X-Forwarded-For"
+	var address string
+	var ip net.IP
+	app := middleware.New(fw.ServeMiddleware, f.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		address = requestmatching.GetRequestIPAddress(r)
+		ip = ClientIP(r)
+	})
+	for _, forwardedIP := range []string{"192.168.0.1", "2001:db8::1"} {
+		address = ""
+		ip = nil
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Forwarded-For", forwardedIP+", 127.0.0.1")
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		if rec.Code != 200 || address != forwardedIP || ip.String() != forwardedIP {
+			t.Fatal(forwardedIP, rec.Code, address, ip)
+		}
+	}
+}
+
+func TestFileReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "deny.txt")
+	err = ioutil.WriteFile(path, []byte("# deny list\n1.1.1.1 # single\n\n2.2.0.0/16\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(&Config{
+		Rules:                  []*RuleConfig{{Action: "deny", File: path}},
+		ReloadIntervalInSecond: 0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Config{}
+	json.Unmarshal(data, c)
+	f, err := c.CreateFilter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Allowed(net.ParseIP("1.1.1.1")) || f.Allowed(net.ParseIP("2.2.3.4")) || !f.Allowed(net.ParseIP("3.3.3.3")) {
+		t.Fatal()
+	}
+	f.ReloadInterval = time.Millisecond
+	errs := make(chan error, 10)
+	f.OnError = func(err error) {
+		errs <- err
+	}
+	err = ioutil.WriteFile(path, []byte("3.3.3.3\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Hour))
+	app := middleware.New(f.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for i := 0; i < 1000; i++ {
+		time.Sleep(2 * time.Millisecond)
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		if !f.Allowed(net.ParseIP("3.3.3.3")) {
+			break
+		}
+	}
+	if !f.Allowed(net.ParseIP("1.1.1.1")) || f.Allowed(net.ParseIP("3.3.3.3")) {
+		t.Fatal()
+	}
+	err = ioutil.WriteFile(path, []byte("invalid\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, time.Now(), time.Now().Add(2*time.Hour))
+	err = f.Reload()
+	if err != ErrInvalidPattern || f.Allowed(net.ParseIP("3.3.3.3")) {
+		t.Fatal(err)
+	}
+}
+
+func TestFactory(t *testing.T) {
+	body := "denied"
+	data, err := json.Marshal(&Config{
+		Rules:    []*RuleConfig{{Action: "allow", IPList: []string{"192.0.2.0/24"}}},
+		Default:  "deny",
+		Response: &middlewarefactory.ResponseMiddleware{StatusCode: 401, Body: &body},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 401 || rec.Body.String() != "denied" {
+		t.Fatal(rec.Code, rec.Body.String())
+	}
+	_, err = (&Config{Default: "unknown"}).CreateFilter()
+	if err != ErrUnknownAction {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ErrUnknownAction.Error(), "ipfilter") {
+		t.Fatal(ErrUnknownAction)
+	}
+}
+
+func BenchmarkList(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	patterns := make([]string, 50000)
+	for k := range patterns {
+		patterns[k] = net.IPv4(byte(rnd.Intn(256)), byte(rnd.Intn(256)), byte(rnd.Intn(256)), byte(rnd.Intn(256))).String()
+	}
+	l, err := NewList(patterns...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ip := net.ParseIP("8.8.8.8")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Contains(ip)
+	}
+}
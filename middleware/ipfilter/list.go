@@ -0,0 +1,150 @@
+package ipfilter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+//ErrInvalidPattern error raised when ip pattern is neither ip nor cidr.
+var ErrInvalidPattern = errors.New("ipfilter:invalid ip pattern")
+
+type ipRange struct {
+	start [16]byte
+	end   [16]byte
+}
+
+//List immutable ip list.
+//Ranges are sorted and merged so lookup cost is logarithmic.
+type List struct {
+	ranges []ipRange
+}
+
+//Len return merged ranges count.
+func (l *List) Len() int {
+	return len(l.ranges)
+}
+
+//Contains check if given ip is in list.
+func (l *List) Contains(ip net.IP) bool {
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return false
+	}
+	var key [16]byte
+	copy(key[:], ip16)
+	i := sort.Search(len(l.ranges), func(i int) bool {
+		return bytes.Compare(l.ranges[i].start[:], key[:]) > 0
+	})
+	if i == 0 {
+		return false
+	}
+	return bytes.Compare(l.ranges[i-1].end[:], key[:]) >= 0
+}
+
+//parseRange parse ip or cidr pattern to range.
+//IPv4 addresses are converted to IPv4-mapped IPv6 addresses.
+func parseRange(pattern string) (ipRange, error) {
+	var r ipRange
+	if !strings.Contains(pattern, "/") {
+		ip := net.ParseIP(pattern)
+		if ip == nil {
+			return r, ErrInvalidPattern
+		}
+		copy(r.start[:], ip.To16())
+		r.end = r.start
+		return r, nil
+	}
+	ip, ipnet, err := net.ParseCIDR(pattern)
+	if err != nil {
+		return r, ErrInvalidPattern
+	}
+	ones, bits := ipnet.Mask.Size()
+	if ip.To4() != nil && bits == 32 {
+		ones = ones + 96
+	}
+	copy(r.start[:], ip.To16())
+	for i := 0; i < 16; i++ {
+		var mask byte
+		switch {
+		case ones >= (i+1)*8:
+			mask = 0xff
+		case ones > i*8:
+			mask = byte(0xff << uint(8-(ones-i*8)))
+		}
+		r.start[i] = r.start[i] & mask
+		r.end[i] = r.start[i] | ^mask
+	}
+	return r, nil
+}
+
+//NewList create new list with given ip or cidr patterns.
+//Return list and any error if raised.
+func NewList(patterns ...string) (*List, error) {
+	ranges := make([]ipRange, 0, len(patterns))
+	for _, v := range patterns {
+		r, err := parseRange(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].start[:], ranges[j].start[:]) < 0
+	})
+	merged := make([]ipRange, 0, len(ranges))
+	for _, r := range ranges {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			adjacent := next(last.end)
+			if bytes.Compare(r.start[:], adjacent[:]) <= 0 {
+				if bytes.Compare(r.end[:], last.end[:]) > 0 {
+					last.end = r.end
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return &List{ranges: merged}, nil
+}
+
+//next return next address,or same address if overflowed.
+func next(ip [16]byte) [16]byte {
+	result := ip
+	for i := 15; i >= 0; i-- {
+		result[i]++
+		if result[i] != 0 {
+			return result
+		}
+	}
+	return ip
+}
+
+//ParseList parse list from reader.
+//Each line contains one ip or cidr pattern.
+//Empty lines and content after "#" are ignored.
+//Return list and any error if raised.
+func ParseList(reader io.Reader) (*List, error) {
+	patterns := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	return NewList(patterns...)
+}
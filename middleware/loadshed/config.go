@@ -0,0 +1,90 @@
+package loadshed
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//ClassConfig priority class config
+type ClassConfig struct {
+	//Name class name
+	Name string
+	//Pattern request pattern
+	Pattern *requestmatching.PatternConfig
+	//Priority class priority
+	Priority int
+	//Unlimited whether requests bypass limiter
+	Unlimited bool
+}
+
+//Config limiter config
+type Config struct {
+	//MaxInflight max in-flight requests.
+	MaxInflight int
+	//MaxInflightPerIP max in-flight requests per client ip.
+	MaxInflightPerIP int
+	//QueueSize max queued requests count.
+	QueueSize int
+	//QueueTimeoutInMillisecond max queue wait in millisecond.
+	QueueTimeoutInMillisecond int64
+	//RetryAfterInSecond Retry-After value in second.
+	RetryAfterInSecond int64
+	//Classes priority classes
+	Classes []*ClassConfig
+	//Response shed response config.
+	//Status 503 will be returned if nil.
+	Response *middlewarefactory.ResponseMiddleware
+}
+
+//CreateLimiter create limiter with config.
+//Return limiter and any error if raised.
+func (c *Config) CreateLimiter() (*Limiter, error) {
+	l := New(c.MaxInflight)
+	l.MaxInflightPerKey = c.MaxInflightPerIP
+	l.QueueSize = c.QueueSize
+	l.QueueTimeout = time.Duration(c.QueueTimeoutInMillisecond) * time.Millisecond
+	l.RetryAfter = time.Duration(c.RetryAfterInSecond) * time.Second
+	for _, v := range c.Classes {
+		pc := v.Pattern
+		if pc == nil {
+			pc = &requestmatching.PatternConfig{}
+		}
+		p, err := pc.CreatePattern()
+		if err != nil {
+			return nil, err
+		}
+		l.Classes = append(l.Classes, &Class{
+			Name:      v.Name,
+			Pattern:   p,
+			Priority:  v.Priority,
+			Unlimited: v.Unlimited,
+		})
+	}
+	if c.Response != nil {
+		resp := c.Response
+		l.OnShed = func(w http.ResponseWriter, r *http.Request) {
+			resp.ServeMiddleware(w, r, nil)
+		}
+	}
+	return l, nil
+}
+
+//NewFactory create new limiter middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		l, err := c.CreateLimiter()
+		if err != nil {
+			return nil, err
+		}
+		return l.ServeMiddleware, nil
+	}
+}
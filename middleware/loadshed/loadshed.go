@@ -0,0 +1,328 @@
+//Package loadshed provide concurrency limiting and load shedding middleware.
+package loadshed
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/herb-go/herb/identifier"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//DefaultShedHandler default handler serving shed requests.
+//Status 503 will be returned.
+var DefaultShedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+})
+
+//IPIdentifier identifier which identify request by client ip.
+var IPIdentifier = identifier.IDFunc(func(r *http.Request) (string, error) {
+	return requestmatching.GetRequestIPAddress(r), nil
+})
+
+//DefaultClassName name of class used when no class matched.
+const DefaultClassName = "default"
+
+//Class priority class
+type Class struct {
+	//Name class name
+	Name string
+	//Pattern request pattern
+	Pattern requestmatching.Pattern
+	//Priority class priority.
+	//Queued requests with higher priority are admitted first,
+	//and lower priority requests are shed first when queue is full.
+	//Default class priority is 0.
+	Priority int
+	//Unlimited whether requests bypass limiter.
+	Unlimited bool
+}
+
+//ClassStats class statistics
+type ClassStats struct {
+	//Admitted admitted requests count
+	Admitted uint64
+	//Shed shed requests count,including timed out requests
+	Shed uint64
+	//TimedOut requests count shed because of queue timeout
+	TimedOut uint64
+	//Canceled requests count canceled by client while queued
+	Canceled uint64
+}
+
+//Stats limiter statistics
+type Stats struct {
+	//Inflight in-flight requests count
+	Inflight int
+	//Queued queued requests count
+	Queued int
+	//Classes statistics by class name
+	Classes map[string]ClassStats
+}
+
+type waiter struct {
+	key      string
+	priority int
+	class    string
+	ready    chan bool
+	done     bool
+}
+
+//Limiter concurrency limiter middleware struct
+type Limiter struct {
+	//MaxInflight max in-flight requests.
+	//Unlimited if not greater than 0.
+	MaxInflight int
+	//MaxInflightPerKey max in-flight requests per key.
+	//Unlimited if not greater than 0.
+	MaxInflightPerKey int
+	//Key identifier which creates key from request.
+	Key identifier.Identifier
+	//QueueSize max queued requests count.
+	//Excess requests are shed immediately if not greater than 0.
+	QueueSize int
+	//QueueTimeout max duration request waits in queue.
+	//Wait until admitted or canceled if not greater than 0.
+	QueueTimeout time.Duration
+	//Classes priority classes.
+	//First matched class will be used.
+	Classes []*Class
+	//RetryAfter Retry-After duration sent with shed response.
+	//Header will not be sent if not greater than 0.
+	RetryAfter time.Duration
+	//OnShed handler serving shed requests.
+	//DefaultShedHandler will be used if nil.
+	OnShed   http.HandlerFunc
+	locker   sync.Mutex
+	inflight int
+	perKey   map[string]int
+	queue    []*waiter
+	stats    map[string]*ClassStats
+}
+
+func (l *Limiter) class(r *http.Request) *Class {
+	for _, c := range l.Classes {
+		if requestmatching.MustMatch(r, c.Pattern) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (l *Limiter) classStats(name string) *ClassStats {
+	s := l.stats[name]
+	if s == nil {
+		s = &ClassStats{}
+		l.stats[name] = s
+	}
+	return s
+}
+
+func (l *Limiter) canRun(key string) bool {
+	if l.MaxInflight > 0 && l.inflight >= l.MaxInflight {
+		return false
+	}
+	if l.MaxInflightPerKey > 0 && l.perKey[key] >= l.MaxInflightPerKey {
+		return false
+	}
+	return true
+}
+
+func (l *Limiter) admit(key string, class string) {
+	l.inflight++
+	if l.MaxInflightPerKey > 0 {
+		l.perKey[key]++
+	}
+	l.classStats(class).Admitted++
+}
+
+func (l *Limiter) remove(w *waiter) {
+	for k, v := range l.queue {
+		if v == w {
+			l.queue = append(l.queue[:k], l.queue[k+1:]...)
+			return
+		}
+	}
+}
+
+//enqueue insert waiter into queue ordered by priority.
+//Waiters with same priority keep arriving order.
+func (l *Limiter) enqueue(w *waiter) {
+	i := len(l.queue)
+	for k, v := range l.queue {
+		if v.priority < w.priority {
+			i = k
+			break
+		}
+	}
+	l.queue = append(l.queue, nil)
+	copy(l.queue[i+1:], l.queue[i:])
+	l.queue[i] = w
+}
+
+func (l *Limiter) dispatch() {
+	for k := 0; k < len(l.queue); {
+		if l.MaxInflight > 0 && l.inflight >= l.MaxInflight {
+			return
+		}
+		w := l.queue[k]
+		if !l.canRun(w.key) {
+			k++
+			continue
+		}
+		l.queue = append(l.queue[:k], l.queue[k+1:]...)
+		l.admit(w.key, w.class)
+		w.done = true
+		w.ready <- true
+	}
+}
+
+//acquire acquire slot for request.
+//Return true if admitted.
+func (l *Limiter) acquire(r *http.Request, key string, class string, priority int) bool {
+	l.locker.Lock()
+	if l.canRun(key) {
+		l.admit(key, class)
+		l.locker.Unlock()
+		return true
+	}
+	if len(l.queue) >= l.QueueSize {
+		last := len(l.queue) - 1
+		if last < 0 || l.queue[last].priority >= priority {
+			l.classStats(class).Shed++
+			l.locker.Unlock()
+			return false
+		}
+		victim := l.queue[last]
+		l.queue = l.queue[:last]
+		victim.done = true
+		l.classStats(victim.class).Shed++
+		victim.ready <- false
+	}
+	w := &waiter{
+		key:      key,
+		priority: priority,
+		class:    class,
+		ready:    make(chan bool, 1),
+	}
+	l.enqueue(w)
+	l.locker.Unlock()
+	var timeout <-chan time.Time
+	if l.QueueTimeout > 0 {
+		timer := time.NewTimer(l.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case ok := <-w.ready:
+		return ok
+	case <-timeout:
+		l.locker.Lock()
+		defer l.locker.Unlock()
+		if w.done {
+			return <-w.ready
+		}
+		l.remove(w)
+		s := l.classStats(class)
+		s.Shed++
+		s.TimedOut++
+		return false
+	case <-r.Context().Done():
+		l.locker.Lock()
+		defer l.locker.Unlock()
+		if w.done {
+			if <-w.ready {
+				l.release(key)
+			}
+			return false
+		}
+		l.remove(w)
+		l.classStats(class).Canceled++
+		return false
+	}
+}
+
+func (l *Limiter) release(key string) {
+	l.inflight--
+	if l.MaxInflightPerKey > 0 {
+		l.perKey[key]--
+		if l.perKey[key] <= 0 {
+			delete(l.perKey, key)
+		}
+	}
+	l.dispatch()
+}
+
+func (l *Limiter) shed(w http.ResponseWriter, r *http.Request) {
+	if l.RetryAfter > 0 {
+		retry := (l.RetryAfter + time.Second - 1) / time.Second
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(retry), 10))
+	}
+	if l.OnShed != nil {
+		l.OnShed(w, r)
+		return
+	}
+	DefaultShedHandler(w, r)
+}
+
+//Stats return limiter statistics.
+func (l *Limiter) Stats() *Stats {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	s := &Stats{
+		Inflight: l.inflight,
+		Queued:   len(l.queue),
+		Classes:  make(map[string]ClassStats, len(l.stats)),
+	}
+	for k, v := range l.stats {
+		s.Classes[k] = *v
+	}
+	return s
+}
+
+//ServeMiddleware serve as middleware.
+func (l *Limiter) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	name := DefaultClassName
+	priority := 0
+	c := l.class(r)
+	if c != nil {
+		if c.Unlimited {
+			next(w, r)
+			return
+		}
+		name = c.Name
+		priority = c.Priority
+	}
+	var key string
+	if l.Key != nil && l.MaxInflightPerKey > 0 {
+		var err error
+		key, err = l.Key.IdentifyRequest(r)
+		if err != nil {
+			panic(err)
+		}
+	}
+	if !l.acquire(r, key, name, priority) {
+		if r.Context().Err() == nil {
+			l.shed(w, r)
+		}
+		return
+	}
+	defer func() {
+		l.locker.Lock()
+		l.release(key)
+		l.locker.Unlock()
+	}()
+	next(w, r)
+}
+
+//New create new limiter with given max in-flight requests count.
+func New(maxInflight int) *Limiter {
+	return &Limiter{
+		MaxInflight: maxInflight,
+		Key:         IPIdentifier,
+		perKey:      map[string]int{},
+		stats:       map[string]*ClassStats{},
+	}
+}
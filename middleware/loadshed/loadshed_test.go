@@ -0,0 +1,255 @@
+package loadshed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/identifier"
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+type testApp struct {
+	handler http.Handler
+	release chan bool
+	locker  sync.Mutex
+	order   []string
+	wg      sync.WaitGroup
+	results map[string]int
+}
+
+func newTestApp(l *Limiter) *testApp {
+	a := &testApp{
+		release: make(chan bool),
+		results: map[string]int{},
+	}
+	a.handler = middleware.New(l.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.locker.Lock()
+		a.order = append(a.order, r.URL.Path)
+		a.locker.Unlock()
+		if r.URL.Query().Get("block") != "" {
+			<-a.release
+		}
+	})
+	return a
+}
+
+func (a *testApp) serve(path string, header http.Header) {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		for k := range header {
+			req.Header[k] = header[k]
+		}
+		a.handler.ServeHTTP(rec, req)
+		a.locker.Lock()
+		a.results[path] = rec.Code
+		a.locker.Unlock()
+	}()
+}
+
+func waitFor(t *testing.T, l *Limiter, inflight int, queued int) {
+	for i := 0; i < 1000; i++ {
+		s := l.Stats()
+		if s.Inflight == inflight && s.Queued == queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal(l.Stats())
+}
+
+func TestShed(t *testing.T) {
+	l := New(1)
+	l.RetryAfter = 1500 * time.Millisecond
+	a := newTestApp(l)
+	a.serve("/1?block=1", nil)
+	waitFor(t, l, 1, 0)
+	rec := httptest.NewRecorder()
+	a.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/2", nil))
+	if rec.Code != 503 || rec.Header().Get("Retry-After") != "2" {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	close(a.release)
+	a.wg.Wait()
+	s := l.Stats()
+	if s.Inflight != 0 || s.Classes[DefaultClassName].Admitted != 1 || s.Classes[DefaultClassName].Shed != 1 {
+		t.Fatal(s)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	l := New(1)
+	l.QueueSize = 2
+	l.Classes = []*Class{
+		{Name: "health", Pattern: requestmatching.MustCreatePattern(&requestmatching.PatternConfig{URLList: []string{"/health"}}), Unlimited: true},
+		{Name: "admin", Pattern: requestmatching.MustCreatePattern(&requestmatching.PatternConfig{PrefixList: []string{"/admin"}}), Priority: 10},
+	}
+	a := newTestApp(l)
+	a.serve("/first?block=1", nil)
+	waitFor(t, l, 1, 0)
+	a.serve("/low1?block=1", nil)
+	waitFor(t, l, 1, 1)
+	a.serve("/low2?block=1", nil)
+	waitFor(t, l, 1, 2)
+	a.serve("/admin?block=1", nil)
+	for i := 0; i < 1000; i++ {
+		a.locker.Lock()
+		code := a.results["/low2?block=1"]
+		a.locker.Unlock()
+		if code == 503 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	waitFor(t, l, 1, 2)
+	rec := httptest.NewRecorder()
+	a.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	close(a.release)
+	a.wg.Wait()
+	expected := []string{"/first", "/health", "/admin", "/low1"}
+	if len(a.order) != len(expected) {
+		t.Fatal(a.order)
+	}
+	for k := range expected {
+		if a.order[k] != expected[k] {
+			t.Fatal(a.order)
+		}
+	}
+	if a.results["/low2?block=1"] != 503 || a.results["/admin?block=1"] != 200 {
+		t.Fatal(a.results)
+	}
+	s := l.Stats()
+	if s.Classes["admin"].Admitted != 1 || s.Classes[DefaultClassName].Shed != 1 || s.Classes[DefaultClassName].Admitted != 2 {
+		t.Fatal(s)
+	}
+}
+
+func TestQueueTimeout(t *testing.T) {
+	l := New(1)
+	l.QueueSize = 1
+	l.QueueTimeout = 20 * time.Millisecond
+	a := newTestApp(l)
+	a.serve("/1?block=1", nil)
+	waitFor(t, l, 1, 0)
+	rec := httptest.NewRecorder()
+	a.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/2", nil))
+	if rec.Code != 503 {
+		t.Fatal(rec.Code)
+	}
+	close(a.release)
+	a.wg.Wait()
+	s := l.Stats()
+	if s.Queued != 0 || s.Classes[DefaultClassName].TimedOut != 1 {
+		t.Fatal(s)
+	}
+}
+
+func TestPerKey(t *testing.T) {
+	l := New(0)
+	l.MaxInflightPerKey = 1
+	l.QueueSize = 10
+	l.Key = identifier.IDFunc(func(r *http.Request) (string, error) {
+		return r.Header.Get("user"), nil
+	})
+	a := newTestApp(l)
+	a.serve("/a1?block=1", http.Header{"User": []string{"a"}})
+	waitFor(t, l, 1, 0)
+	a.serve("/a2?block=1", http.Header{"User": []string{"a"}})
+	waitFor(t, l, 1, 1)
+	a.serve("/b1?block=1", http.Header{"User": []string{"b"}})
+	waitFor(t, l, 2, 1)
+	close(a.release)
+	a.wg.Wait()
+	if len(a.order) != 3 || a.results["/a2?block=1"] != 200 {
+		t.Fatal(a.order, a.results)
+	}
+	if l.Stats().Inflight != 0 || len(l.perKey) != 0 {
+		t.Fatal(l.Stats(), l.perKey)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	l := New(3)
+	l.QueueSize = 1000
+	var locker sync.Mutex
+	var current, max int
+	app := middleware.New(l.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		locker.Lock()
+		current++
+		if current > max {
+			max = current
+		}
+		locker.Unlock()
+		time.Sleep(time.Millisecond)
+		locker.Lock()
+		current--
+		locker.Unlock()
+	})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+			if rec.Code != 200 {
+				t.Error(rec.Code)
+			}
+		}()
+	}
+	wg.Wait()
+	if max > 3 || l.Stats().Classes[DefaultClassName].Admitted != 100 {
+		t.Fatal(max, l.Stats())
+	}
+}
+
+func TestFactory(t *testing.T) {
+	body := "busy"
+	data, err := json.Marshal(&Config{
+		MaxInflight:        1,
+		RetryAfterInSecond: 5,
+		Classes:            []*ClassConfig{{Name: "health", Unlimited: true, Pattern: &requestmatching.PatternConfig{URLList: []string{"/health"}}}},
+		Response:           &middlewarefactory.ResponseMiddleware{StatusCode: 503, Body: &body},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan bool)
+	started := make(chan bool)
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block" {
+			started <- true
+			<-release
+		}
+	})
+	go app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/block", nil))
+	<-started
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 503 || rec.Body.String() != "busy" || rec.Header().Get("Retry-After") != "5" {
+		t.Fatal(rec.Code, rec.Body.String(), rec.Header())
+	}
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	close(release)
+}
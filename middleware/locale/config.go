@@ -0,0 +1,82 @@
+package locale
+
+import (
+	"errors"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/httpcookie"
+)
+
+//ErrUnknownSource error raised when language source is unknown.
+var ErrUnknownSource = errors.New("locale:unknown source")
+
+//Config locale middleware config
+type Config struct {
+	//Sources language sources in order.
+	//DefaultSources will be used if empty.
+	Sources []string
+	//Languages available languages.
+	//Languages of ui.DefaultTranslations will be used if empty.
+	Languages []string
+	//Default language used if no language negotiated.
+	Default string
+	//StripPathPrefix whether language prefix is removed from url path.
+	StripPathPrefix bool
+	//ParamName router param name.
+	//DefaultParamName will be used if empty.
+	ParamName string
+	//QueryName query parameter name.
+	//DefaultQueryName will be used if empty.
+	QueryName string
+	//Cookie cookie config.
+	//Cookie source is disabled if nil.
+	Cookie *httpcookie.Config
+	//SaveCookie whether language from path,param or query is saved to cookie.
+	SaveCookie bool
+}
+
+//CreateLocale create locale middleware with config.
+//Return middleware and any error if raised.
+func (c *Config) CreateLocale() (*Locale, error) {
+	l := New()
+	if len(c.Sources) > 0 {
+		l.Sources = make([]Source, len(c.Sources))
+		for k, v := range c.Sources {
+			switch Source(v) {
+			case SourcePath, SourceParam, SourceQuery, SourceCookie, SourceHeader:
+				l.Sources[k] = Source(v)
+			default:
+				return nil, ErrUnknownSource
+			}
+		}
+	}
+	l.Languages = c.Languages
+	l.Default = c.Default
+	l.StripPathPrefix = c.StripPathPrefix
+	if c.ParamName != "" {
+		l.ParamName = c.ParamName
+	}
+	if c.QueryName != "" {
+		l.QueryName = c.QueryName
+	}
+	l.Cookie = c.Cookie
+	l.SaveCookie = c.SaveCookie
+	return l, nil
+}
+
+//NewFactory create new locale middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		l, err := c.CreateLocale()
+		if err != nil {
+			return nil, err
+		}
+		return l.ServeMiddleware, nil
+	}
+}
@@ -0,0 +1,310 @@
+//Package locale provide locale negotiation middleware.
+package locale
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/herb-go/herb/middleware/router"
+	"github.com/herb-go/herb/service/httpservice/httpcookie"
+	"github.com/herb-go/herb/ui"
+)
+
+//DefaultQueryName default query parameter name
+const DefaultQueryName = "lang"
+
+//DefaultParamName default router param name
+const DefaultParamName = "lang"
+
+//Source language source type
+type Source string
+
+//SourcePath language from first segment of url path,like "/zh-TW/page"
+const SourcePath = Source("path")
+
+//SourceParam language from router params
+const SourceParam = Source("param")
+
+//SourceQuery language from query parameter
+const SourceQuery = Source("query")
+
+//SourceCookie language from cookie
+const SourceCookie = Source("cookie")
+
+//SourceHeader language from Accept-Language header
+const SourceHeader = Source("header")
+
+//DefaultSources default language sources in order
+var DefaultSources = []Source{SourcePath, SourceParam, SourceQuery, SourceCookie, SourceHeader}
+
+//ContextName context name type
+type ContextName string
+
+//ContextNameLang language context name
+const ContextNameLang = ContextName("lang")
+
+//Locale locale negotiation middleware struct
+type Locale struct {
+	//Sources language sources in order.
+	Sources []Source
+	//Languages available languages.
+	//Languages of Translations will be used if empty.
+	//Languages should not be changed after serving requests.
+	Languages []string
+	//Translations translations which provides available languages.
+	//ui.DefaultTranslations will be used if nil.
+	Translations *ui.Translations
+	//Default language used if no language negotiated.
+	Default string
+	//StripPathPrefix whether language prefix is removed from url path.
+	StripPathPrefix bool
+	//ParamName router param name.
+	ParamName string
+	//QueryName query parameter name.
+	QueryName string
+	//Cookie cookie config.
+	//Cookie source is disabled if nil.
+	Cookie *httpcookie.Config
+	//SaveCookie whether language from path,param or query is saved to cookie.
+	SaveCookie    bool
+	languagesOnce sync.Once
+	languages     []string
+}
+
+//AvailableLanguages return normalized available languages.
+//Normalized Languages are computed once,
+//languages of Translations are cached until translations changed.
+//Result should not be modified.
+func (l *Locale) AvailableLanguages() []string {
+	if len(l.Languages) > 0 {
+		l.languagesOnce.Do(func() {
+			l.languages = make([]string, len(l.Languages))
+			for k := range l.Languages {
+				l.languages[k] = ui.NormalizeLang(l.Languages[k])
+			}
+		})
+		return l.languages
+	}
+	t := l.Translations
+	if t == nil {
+		t = ui.DefaultTranslations
+	}
+	return t.Languages()
+}
+
+//pathLang return language matched by first segment of escaped url path,
+//and rest of escaped path.
+func (l *Locale) pathLang(r *http.Request, available []string) (string, string) {
+	p := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+	segment := p
+	rest := "/"
+	if i := strings.Index(p, "/"); i >= 0 {
+		segment, rest = p[:i], p[i:]
+	}
+	segment, err := url.PathUnescape(segment)
+	if err != nil {
+		return "", rest
+	}
+	return Match(available, segment), rest
+}
+
+//stripPathLang return request with language prefix removed from url path.
+//Request will be returned unchanged if first path segment is not an available language.
+func (l *Locale) stripPathLang(r *http.Request, available []string) *http.Request {
+	lang, rest := l.pathLang(r, available)
+	if lang == "" {
+		return r
+	}
+	p, err := url.PathUnescape(rest)
+	if err != nil {
+		return r
+	}
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = p
+	r2.URL.RawPath = ""
+	if r2.URL.EscapedPath() != rest {
+		r2.URL.RawPath = rest
+	}
+	return r2
+}
+
+//Negotiate negotiate language of given request.
+//Return language and source.
+//Default language and empty source will be returned if no language negotiated.
+func (l *Locale) Negotiate(r *http.Request) (string, Source) {
+	available := l.AvailableLanguages()
+	for _, source := range l.Sources {
+		var lang string
+		switch source {
+		case SourcePath:
+			lang, _ = l.pathLang(r, available)
+		case SourceParam:
+			lang = Match(available, router.GetParams(r).Get(l.ParamName))
+		case SourceQuery:
+			lang = Match(available, r.URL.Query().Get(l.QueryName))
+		case SourceCookie:
+			if l.Cookie != nil {
+				c, err := r.Cookie(l.Cookie.Name)
+				if err == nil {
+					lang = Match(available, c.Value)
+				}
+			}
+		case SourceHeader:
+			for _, v := range ParseAcceptLanguage(r.Header.Get("Accept-Language")) {
+				lang = Match(available, v)
+				if lang != "" {
+					break
+				}
+			}
+		}
+		if lang != "" {
+			return lang, source
+		}
+	}
+	return l.Default, ""
+}
+
+func (l *Locale) hasSource(source Source) bool {
+	for _, v := range l.Sources {
+		if v == source {
+			return true
+		}
+	}
+	return false
+}
+
+//ServeMiddleware serve as middleware.
+//Negotiated language will be stored in context.
+//If StripPathPrefix is true,language prefix will be removed from url path whichever source decided the language.
+func (l *Locale) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if l.hasSource(SourceHeader) {
+		w.Header().Add("Vary", "Accept-Language")
+	}
+	if l.Cookie != nil && l.hasSource(SourceCookie) {
+		w.Header().Add("Vary", "Cookie")
+	}
+	lang, source := l.Negotiate(r)
+	if l.StripPathPrefix {
+		r = l.stripPathLang(r, l.AvailableLanguages())
+	}
+	if l.SaveCookie && l.Cookie != nil && (source == SourcePath || source == SourceParam || source == SourceQuery) {
+		c, err := r.Cookie(l.Cookie.Name)
+		if err != nil || c.Value != lang {
+			http.SetCookie(w, l.Cookie.CreateCookieWithValue(lang))
+		}
+	}
+	next(w, r.WithContext(WithLang(r.Context(), lang)))
+}
+
+//New create new locale middleware with default sources.
+func New() *Locale {
+	return &Locale{
+		Sources:   append([]Source{}, DefaultSources...),
+		ParamName: DefaultParamName,
+		QueryName: DefaultQueryName,
+	}
+}
+
+//Match match given language against available languages.
+//Language matches available language itself,its parent language,or first available language under it.
+//Return matched available language or empty string if not matched.
+func Match(available []string, lang string) string {
+	lang = ui.NormalizeLang(strings.TrimSpace(lang))
+	if lang == "" {
+		return ""
+	}
+	for l := lang; l != ""; {
+		for _, v := range available {
+			if v == l {
+				return v
+			}
+		}
+		i := strings.LastIndex(l, "-")
+		if i < 0 {
+			break
+		}
+		l = l[:i]
+	}
+	for _, v := range available {
+		if strings.HasPrefix(v, lang+"-") {
+			return v
+		}
+	}
+	return ""
+}
+
+//ParseAcceptLanguage parse Accept-Language header value.
+//Return languages sorted by quality,languages with zero quality and "*" are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type tag struct {
+		lang    string
+		quality float64
+	}
+	tags := []tag{}
+	for _, v := range strings.Split(header, ",") {
+		parts := strings.Split(v, ";")
+		lang := strings.TrimSpace(parts[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				f, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					f = 0
+				}
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, tag{lang: lang, quality: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+	result := make([]string, len(tags))
+	for k := range tags {
+		result[k] = tags[k].lang
+	}
+	return result
+}
+
+//WithLang return copy of given context with language.
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, ContextNameLang, lang)
+}
+
+//FromContext return language stored in given context.
+//Return empty string if not found.
+func FromContext(ctx context.Context) string {
+	v, _ := ctx.Value(ContextNameLang).(string)
+	return v
+}
+
+//Get return negotiated language of given request.
+//Return empty string if not found.
+func Get(r *http.Request) string {
+	return FromContext(r.Context())
+}
+
+//Apply set negotiated language of given request to translation language,
+//such as ui.Language or validator.Validator.
+//Language will not be changed if no language negotiated.
+func Apply(r *http.Request, l ui.TranslationLanguage) {
+	lang := Get(r)
+	if lang != "" {
+		l.SetLang(lang)
+	}
+}
@@ -0,0 +1,179 @@
+package locale
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/router"
+	"github.com/herb-go/herb/service/httpservice/httpcookie"
+	"github.com/herb-go/herb/ui"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	result := ParseAcceptLanguage("fr;q=0.5, zh-TW , *;q=0.1, en;q=0.8, de;q=0, ja;q=abc, ru;q=0.8")
+	if !reflect.DeepEqual(result, []string{"zh-TW", "en", "ru", "fr"}) {
+		t.Fatal(result)
+	}
+	if len(ParseAcceptLanguage("")) != 0 {
+		t.Fatal()
+	}
+}
+
+func TestMatch(t *testing.T) {
+	available := []string{"en", "zh-cn", "zh-tw"}
+	var tests = map[string]string{
+		"en":      "en",
+		"EN-us":   "en",
+		"zh_TW":   "zh-tw",
+		"zh-Hant": "",
+		"zh":      "zh-cn",
+		"fr":      "",
+		"":        "",
+	}
+	for k, v := range tests {
+		if result := Match(available, k); result != v {
+			t.Fatal(k, result)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	var lang, path, rawpath, param string
+	l := New()
+	l.Languages = []string{"en", "zh-CN", "zh-TW"}
+	l.Default = "en"
+	l.StripPathPrefix = true
+	l.Cookie = &httpcookie.Config{Name: "lang", Path: "/"}
+	l.SaveCookie = true
+	app := middleware.New(
+		func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			if param != "" {
+				router.GetParams(r).Set("lang", param)
+			}
+			next(w, r)
+		},
+		l.ServeMiddleware,
+	).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang = Get(r)
+		path = r.URL.Path
+		rawpath = r.URL.EscapedPath()
+	})
+	serve := func(target string, header string, cookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		if header != "" {
+			req.Header.Set("Accept-Language", header)
+		}
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "lang", Value: cookie})
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec
+	}
+	rec := serve("/zh-TW/page?lang=en", "en", "en")
+	if lang != "zh-tw" || path != "/page" || rec.Header().Get("Set-Cookie") != "lang=zh-tw; Path=/" || strings.Join(rec.Header().Values("Vary"), ",") != "Accept-Language,Cookie" {
+		t.Fatal(lang, path, rec.Header())
+	}
+	rec = serve("/zh-tw", "", "")
+	if lang != "zh-tw" || path != "/" {
+		t.Fatal(lang, path)
+	}
+	rec = serve("/page?lang=zh-cn", "en", "zh-cn")
+	if lang != "zh-cn" || path != "/page" || rec.Header().Get("Set-Cookie") != "" {
+		t.Fatal(lang, path, rec.Header())
+	}
+	param = "zh-TW"
+	serve("/page?lang=zh-cn", "", "")
+	if lang != "zh-tw" {
+		t.Fatal(lang)
+	}
+	param = ""
+	rec = serve("/page", "zh-CN", "zh-tw")
+	if lang != "zh-tw" || rec.Header().Get("Set-Cookie") != "" {
+		t.Fatal(lang, rec.Header())
+	}
+	serve("/page", "fr, zh;q=0.9, en;q=0.8", "")
+	if lang != "zh-cn" {
+		t.Fatal(lang)
+	}
+	serve("/fr/page", "fr", "fr")
+	if lang != "en" || path != "/fr/page" {
+		t.Fatal(lang, path)
+	}
+	serve("/en/a%2Fb", "", "")
+	if lang != "en" || path != "/a/b" || rawpath != "/a%2Fb" {
+		t.Fatal(lang, path, rawpath)
+	}
+	l.Sources = []Source{SourceQuery, SourcePath}
+	serve("/en/page?lang=zh-cn", "", "")
+	if lang != "zh-cn" || path != "/page" {
+		t.Fatal(lang, path)
+	}
+	l.Sources = []Source{SourceHeader, SourceQuery}
+	serve("/page?lang=zh-tw", "zh-cn", "")
+	if lang != "zh-cn" {
+		t.Fatal(lang)
+	}
+	l.Default = ""
+	serve("/page", "", "")
+	if lang != "" {
+		t.Fatal(lang)
+	}
+}
+
+func TestTranslationsLanguages(t *testing.T) {
+	translations := ui.NewTranslations()
+	translations.SetMessages("zh-CN", "app", ui.NewMessages())
+	l := New()
+	l.Translations = translations
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "en, zh")
+	lang, source := l.Negotiate(req)
+	if lang != "zh-cn" || source != SourceHeader {
+		t.Fatal(lang, source)
+	}
+	translations.SetMessages("en", "app", ui.NewMessages())
+	if lang, _ := l.Negotiate(req); lang != "en" {
+		t.Fatal(lang)
+	}
+	translations.DeleteMessages("en", "app")
+	v := &ui.Language{}
+	Apply(req, v)
+	if v.Lang() != "" {
+		t.Fatal(v.Lang())
+	}
+	Apply(req.WithContext(WithLang(context.Background(), lang)), v)
+	if v.Lang() != "zh-cn" {
+		t.Fatal(v.Lang())
+	}
+}
+
+func TestFactory(t *testing.T) {
+	data, err := json.Marshal(&Config{Sources: []string{"query"}, Languages: []string{"en", "zh"}, QueryName: "locale"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lang string
+	middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang = Get(r)
+	}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?locale=zh&lang=en", nil))
+	if lang != "zh" {
+		t.Fatal(lang)
+	}
+	_, err = (&Config{Sources: []string{"unknown"}}).CreateLocale()
+	if err != ErrUnknownSource {
+		t.Fatal(err)
+	}
+}
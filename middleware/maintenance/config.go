@@ -0,0 +1,92 @@
+package maintenance
+
+import (
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/basicauth"
+	"github.com/herb-go/herb/middleware/ipfilter"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/httpcookie"
+	"github.com/herb-go/herb/ui/render"
+)
+
+//Config maintenance config
+type Config struct {
+	//Name name maintenance registered with when created by factory.
+	//Maintenance will not be registered if empty.
+	Name string
+	//Enabled whether maintenance mode is turned on
+	Enabled bool
+	//FlagFile path of flag file
+	FlagFile string
+	//FlagCheckIntervalInSecond interval in second in which flag file existence is cached
+	FlagCheckIntervalInSecond int64
+	//Windows scheduled maintenance windows in unix timestamp
+	Windows []*middlewarefactory.TimeCondition
+	//RetryAfterInSecond Retry-After value in second
+	RetryAfterInSecond int64
+	//Message maintenance message
+	Message string
+	//BypassIPList ip or cidr patterns which bypass maintenance mode
+	BypassIPList []string
+	//BypassUsers basic auth users which bypass maintenance mode,username as key,password as value
+	BypassUsers map[string]string
+	//BypassCookie bypass cookie config
+	BypassCookie *httpcookie.Config
+	//BypassToken bypass cookie value
+	BypassToken string
+}
+
+//CreateMaintenance create maintenance with config and given view.
+//View can be nil.
+//Return maintenance and any error if raised.
+func (c *Config) CreateMaintenance(view *render.NamedView) (*Maintenance, error) {
+	m := New()
+	if c.Enabled {
+		m.Enable()
+	}
+	m.FlagFile = c.FlagFile
+	m.FlagCheckInterval = time.Duration(c.FlagCheckIntervalInSecond) * time.Second
+	m.Windows = c.Windows
+	m.RetryAfter = time.Duration(c.RetryAfterInSecond) * time.Second
+	m.Message = c.Message
+	m.View = view
+	if len(c.BypassIPList) > 0 {
+		l, err := ipfilter.NewList(c.BypassIPList...)
+		if err != nil {
+			return nil, err
+		}
+		m.BypassIPs = l
+	}
+	if len(c.BypassUsers) > 0 {
+		m.BypassUsers = &basicauth.Users{
+			Users: c.BypassUsers,
+		}
+	}
+	m.BypassCookie = c.BypassCookie
+	m.BypassToken = c.BypassToken
+	return m, nil
+}
+
+//NewFactory create new maintenance middleware factory with given view.
+//View can be nil.
+//Created maintenance will be registered with config name,
+//and can be toggled by Get(name).Enable() and Get(name).Disable().
+func NewFactory(view *render.NamedView) middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		m, err := c.CreateMaintenance(view)
+		if err != nil {
+			return nil, err
+		}
+		if c.Name != "" {
+			Register(c.Name, m)
+		}
+		return m.ServeMiddleware, nil
+	}
+}
@@ -0,0 +1,219 @@
+//Package maintenance provide maintenance mode middleware.
+package maintenance
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/herb-go/herb/middleware/basicauth"
+	"github.com/herb-go/herb/middleware/ipfilter"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/httpcookie"
+	"github.com/herb-go/herb/ui/render"
+)
+
+//DefaultMessage default maintenance message.
+const DefaultMessage = "Service is under maintenance."
+
+//Info maintenance info passed to view or encoded as json body.
+type Info struct {
+	//Message maintenance message
+	Message string
+	//RetryAfter seconds after which client should retry.
+	//0 if unknown.
+	RetryAfter int64
+	//Until time when maintenance window ends.
+	//Zero if unknown.
+	Until time.Time
+}
+
+//Maintenance maintenance mode middleware struct
+type Maintenance struct {
+	//FlagFile path of flag file.
+	//Maintenance mode is active while file exists.
+	FlagFile string
+	//FlagCheckInterval interval in which flag file existence is cached.
+	//File will be checked for every request if not greater than 0.
+	FlagCheckInterval time.Duration
+	//Windows scheduled maintenance windows.
+	//Windows with neither start nor end are ignored.
+	Windows []*middlewarefactory.TimeCondition
+	//RetryAfter Retry-After duration used when maintenance end time is unknown.
+	//Header will not be sent if not greater than 0.
+	RetryAfter time.Duration
+	//Message maintenance message.
+	//DefaultMessage will be used if empty.
+	Message string
+	//View view rendered with Info as maintenance page.
+	//Info will be encoded as json if nil or client accepts json.
+	View *render.NamedView
+	//BypassIPs requests from ip in list bypass maintenance mode.
+	BypassIPs *ipfilter.List
+	//BypassUsers requests with basic auth credentials authorized by authorizer bypass maintenance mode.
+	BypassUsers basicauth.Authorizer
+	//BypassCookie bypass cookie config.
+	BypassCookie *httpcookie.Config
+	//BypassToken requests with bypass cookie of token value bypass maintenance mode.
+	//Cookie is disabled if empty.
+	BypassToken string
+	enabled     int32
+	locker      sync.Mutex
+	flagChecked time.Time
+	flagExists  bool
+}
+
+//Enable turn maintenance mode on.
+func (m *Maintenance) Enable() {
+	atomic.StoreInt32(&m.enabled, 1)
+}
+
+//Disable turn runtime maintenance mode off.
+//Flag file and scheduled windows still take effect.
+func (m *Maintenance) Disable() {
+	atomic.StoreInt32(&m.enabled, 0)
+}
+
+//Enabled check if maintenance mode is turned on at runtime.
+func (m *Maintenance) Enabled() bool {
+	return atomic.LoadInt32(&m.enabled) == 1
+}
+
+func (m *Maintenance) flagged() bool {
+	if m.FlagFile == "" {
+		return false
+	}
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	now := time.Now()
+	if m.FlagCheckInterval > 0 && !m.flagChecked.IsZero() && now.Sub(m.flagChecked) < m.FlagCheckInterval {
+		return m.flagExists
+	}
+	_, err := os.Stat(m.FlagFile)
+	m.flagExists = err == nil
+	m.flagChecked = now
+	return m.flagExists
+}
+
+func (m *Maintenance) window(r *http.Request) *middlewarefactory.TimeCondition {
+	for _, w := range m.Windows {
+		if w.Start <= 0 && w.End <= 0 {
+			continue
+		}
+		ok, err := w.MatchRequest(r)
+		if err != nil {
+			panic(err)
+		}
+		if ok {
+			return w
+		}
+	}
+	return nil
+}
+
+//Active check if maintenance mode is active for request,
+//by runtime switch,flag file or scheduled windows.
+//Return active result and maintenance info.
+func (m *Maintenance) Active(r *http.Request) (bool, *Info) {
+	info := &Info{
+		Message: m.Message,
+	}
+	if info.Message == "" {
+		info.Message = DefaultMessage
+	}
+	if m.Enabled() || m.flagged() {
+		info.RetryAfter = seconds(m.RetryAfter)
+		return true, info
+	}
+	w := m.window(r)
+	if w == nil {
+		return false, nil
+	}
+	if w.End > 0 {
+		info.Until = time.Unix(w.End, 0)
+		info.RetryAfter = seconds(time.Until(info.Until))
+	} else {
+		info.RetryAfter = seconds(m.RetryAfter)
+	}
+	return true, info
+}
+
+func seconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+//Bypassed check if request bypasses maintenance mode.
+func (m *Maintenance) Bypassed(r *http.Request) bool {
+	if m.BypassIPs != nil && m.BypassIPs.Contains(ipfilter.ClientIP(r)) {
+		return true
+	}
+	if m.BypassUsers != nil {
+		username, password, ok := r.BasicAuth()
+		if ok {
+			result, err := m.BypassUsers.Authorize(username, password)
+			if err != nil {
+				panic(err)
+			}
+			if result {
+				basicauth.SetUsername(r, username)
+				return true
+			}
+		}
+	}
+	if m.BypassCookie != nil && m.BypassToken != "" {
+		c, err := r.Cookie(m.BypassCookie.Name)
+		if err == nil && subtle.ConstantTimeCompare([]byte(c.Value), []byte(m.BypassToken)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+//SetBypassCookie set bypass cookie to response.
+//Nothing will be done if cookie is disabled.
+func (m *Maintenance) SetBypassCookie(w http.ResponseWriter) {
+	if m.BypassCookie == nil || m.BypassToken == "" {
+		return
+	}
+	http.SetCookie(w, m.BypassCookie.CreateCookieWithValue(m.BypassToken))
+}
+
+func acceptJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+//Serve serve maintenance response with given info.
+func (m *Maintenance) Serve(w http.ResponseWriter, r *http.Request, info *Info) {
+	if info.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(info.RetryAfter, 10))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if m.View != nil && !acceptJSON(r) {
+		m.View.MustRenderError(w, info, http.StatusServiceUnavailable)
+		return
+	}
+	render.MustJSON(w, info, http.StatusServiceUnavailable)
+}
+
+//ServeMiddleware serve as middleware.
+func (m *Maintenance) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	active, info := m.Active(r)
+	if !active || m.Bypassed(r) {
+		next(w, r)
+		return
+	}
+	m.Serve(w, r, info)
+}
+
+//New create new maintenance middleware which is turned off.
+func New() *Maintenance {
+	return &Maintenance{}
+}
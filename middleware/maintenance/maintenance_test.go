@@ -0,0 +1,255 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/basicauth"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/httpcookie"
+	"github.com/herb-go/herb/ui/render"
+	"github.com/herb-go/herb/ui/render/engines/gotemplate"
+)
+
+func newApp(m *Maintenance) http.Handler {
+	return middleware.New(m.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+}
+
+func TestToggle(t *testing.T) {
+	m := New()
+	m.RetryAfter = 90 * time.Second
+	app := newApp(m)
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 || rec.Body.String() != "ok" {
+		t.Fatal(rec.Code)
+	}
+	m.Enable()
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 503 || rec.Header().Get("Retry-After") != "90" || rec.Header().Get(render.ContentType) != render.ContentJSON {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	info := &Info{}
+	err := json.Unmarshal(rec.Body.Bytes(), info)
+	if err != nil || info.Message != DefaultMessage || info.RetryAfter != 90 {
+		t.Fatal(err, info)
+	}
+	m.Disable()
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+}
+
+func TestFlagFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	m := New()
+	m.FlagFile = filepath.Join(dir, "maintenance.flag")
+	app := newApp(m)
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	err = ioutil.WriteFile(m.FlagFile, []byte{}, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 503 || rec.Header().Get("Retry-After") != "" {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	m.FlagCheckInterval = time.Hour
+	os.Remove(m.FlagFile)
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 503 {
+		t.Fatal(rec.Code)
+	}
+	m.FlagCheckInterval = 0
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	now := time.Now().Unix()
+	m := New()
+	m.Windows = []*middlewarefactory.TimeCondition{
+		{},
+		{Start: now - 100, End: now - 10},
+	}
+	app := newApp(m)
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	m.Windows = append(m.Windows, &middlewarefactory.TimeCondition{Start: now - 10, End: now + 600})
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 503 {
+		t.Fatal(rec.Code)
+	}
+	info := &Info{}
+	json.Unmarshal(rec.Body.Bytes(), info)
+	if info.Until.Unix() != now+600 || info.RetryAfter < 590 || info.RetryAfter > 600 {
+		t.Fatal(info)
+	}
+}
+
+func TestBypass(t *testing.T) {
+	l, err := (&Config{
+		Enabled:      true,
+		BypassIPList: []string{"10.0.0.0/8"},
+		BypassUsers:  map[string]string{"admin": "secret"},
+		BypassCookie: &httpcookie.Config{Name: "maintenance-bypass", Path: "/"},
+		BypassToken:  "token",
+	}).CreateMaintenance(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var username string
+	app := middleware.New(l.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		username = basicauth.GetUsername(r)
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.1.2.3:1234"
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("admin", "secret")
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 200 || username != "admin" {
+		t.Fatal(rec.Code, username)
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("admin", "wrong")
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 503 {
+		t.Fatal(rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	l.SetBypassCookie(rec)
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "token" {
+		t.Fatal(cookies)
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "maintenance-bypass", Value: "wrong"})
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 503 {
+		t.Fatal(rec.Code)
+	}
+}
+
+func TestView(t *testing.T) {
+	renderer := render.New()
+	option := render.NewOptionCommon()
+	option.Engine = gotemplate.New()
+	option.ViewRoot = "./testdata"
+	err := renderer.Init(option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := renderer.NewView("maintenance", render.NewViewConfig("maintenance.tmpl"))
+	data, err := json.Marshal(&Config{
+		Enabled: true,
+		Message: "upgrading",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory(view)(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 503 || rec.Body.String() != "<p>upgrading</p>" || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatal(rec.Code, rec.Body.String())
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != 503 || rec.Header().Get(render.ContentType) != render.ContentJSON {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	_, err = (&Config{BypassIPList: []string{"invalid"}}).CreateMaintenance(nil)
+	if err == nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFactoryRegister(t *testing.T) {
+	data, err := json.Marshal(&Config{
+		Name: "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Unregister("test")
+	mw, err := NewFactory(nil)(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Get("test")
+	if m == nil || Get("notexist") != nil {
+		t.Fatal(m)
+	}
+	app := middleware.New(mw).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 200 {
+		t.Fatal(rec.Code)
+	}
+	m.Enable()
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 503 {
+		t.Fatal(rec.Code)
+	}
+	Unregister("test")
+	if Get("test") != nil {
+		t.Fatal(Get("test"))
+	}
+}
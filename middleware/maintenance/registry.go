@@ -0,0 +1,32 @@
+package maintenance
+
+import (
+	"sync"
+)
+
+var registered = map[string]*Maintenance{}
+var registeredLock sync.RWMutex
+
+//Register register maintenance with given name,
+//so it can be looked up and toggled at runtime.
+//Maintenance registered with same name will be replaced.
+func Register(name string, m *Maintenance) {
+	registeredLock.Lock()
+	defer registeredLock.Unlock()
+	registered[name] = m
+}
+
+//Unregister unregister maintenance with given name.
+func Unregister(name string) {
+	registeredLock.Lock()
+	defer registeredLock.Unlock()
+	delete(registered, name)
+}
+
+//Get get registered maintenance by given name.
+//Return nil if not found.
+func Get(name string) *Maintenance {
+	registeredLock.RLock()
+	defer registeredLock.RUnlock()
+	return registered[name]
+}
@@ -0,0 +1,231 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//ContentType text exposition format content type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const labelSeparator = "\xff"
+
+type series struct {
+	labelValues []string
+	value       float64
+	buckets     []uint64
+	count       uint64
+}
+
+type metric struct {
+	locker  sync.Mutex
+	typ     string
+	opts    Opts
+	buckets []float64
+	series  map[string]*series
+}
+
+func (m *metric) get(labelValues []string) *series {
+	if len(labelValues) != len(m.opts.Labels) {
+		panic(fmt.Errorf("%w : %s %v", ErrLabelsNotMatch, m.opts.Name, labelValues))
+	}
+	key := strings.Join(labelValues, labelSeparator)
+	s := m.series[key]
+	if s == nil {
+		values := make([]string, len(labelValues))
+		copy(values, labelValues)
+		s = &series{
+			labelValues: values,
+			buckets:     make([]uint64, len(m.buckets)),
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+//Add add value to counter with given label values.
+func (m *metric) Add(value float64, labelValues ...string) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	m.get(labelValues).value += value
+}
+
+//Observe observe value with given label values.
+func (m *metric) Observe(value float64, labelValues ...string) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	s := m.get(labelValues)
+	for k := range m.buckets {
+		if value <= m.buckets[k] {
+			s.buckets[k]++
+		}
+	}
+	s.count++
+	s.value += value
+}
+
+func (m *metric) writeTo(w *bufio.Writer) {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	if m.opts.Help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.opts.Name, escapeHelp(m.opts.Help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.opts.Name, m.typ)
+	keys := make([]string, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := m.series[key]
+		if m.typ == "counter" {
+			fmt.Fprintf(w, "%s%s %s\n", m.opts.Name, formatLabels(m.opts.Labels, s.labelValues, "", ""), formatFloat(s.value))
+			continue
+		}
+		for k := range m.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", m.opts.Name, formatLabels(m.opts.Labels, s.labelValues, "le", formatFloat(m.buckets[k])), s.buckets[k])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", m.opts.Name, formatLabels(m.opts.Labels, s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", m.opts.Name, formatLabels(m.opts.Labels, s.labelValues, "", ""), formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", m.opts.Name, formatLabels(m.opts.Labels, s.labelValues, "", ""), s.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	if math.IsInf(v, -1) {
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(v string) string {
+	return helpReplacer.Replace(v)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatLabels(labels []string, values []string, extraLabel string, extraValue string) string {
+	if len(labels) == 0 && extraLabel == "" {
+		return ""
+	}
+	result := make([]string, 0, len(labels)+1)
+	for k := range labels {
+		result = append(result, labels[k]+`="`+labelReplacer.Replace(values[k])+`"`)
+	}
+	if extraLabel != "" {
+		result = append(result, extraLabel+`="`+labelReplacer.Replace(extraValue)+`"`)
+	}
+	return "{" + strings.Join(result, ",") + "}"
+}
+
+//MemoryRegistry in-process registry which exposes metrics in text exposition format.
+type MemoryRegistry struct {
+	//OnError func called when writing metrics to http response fails,
+	//such as client disconnected.
+	//Error will be ignored if nil.
+	OnError func(err error)
+	locker  sync.Mutex
+	metrics []*metric
+	names   map[string]bool
+}
+
+func (r *MemoryRegistry) register(m *metric) error {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	if r.names[m.opts.Name] {
+		return fmt.Errorf("%w : %s", ErrMetricRegistered, m.opts.Name)
+	}
+	r.names[m.opts.Name] = true
+	r.metrics = append(r.metrics, m)
+	return nil
+}
+
+//NewCounter create and register counter.
+//Return counter and any error if raised.
+func (r *MemoryRegistry) NewCounter(opts *Opts) (Counter, error) {
+	m := &metric{
+		typ:    "counter",
+		opts:   *opts,
+		series: map[string]*series{},
+	}
+	err := r.register(m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//NewHistogram create and register histogram.
+//Return histogram and any error if raised.
+func (r *MemoryRegistry) NewHistogram(opts *HistogramOpts) (Histogram, error) {
+	buckets := make([]float64, len(opts.Buckets))
+	copy(buckets, opts.Buckets)
+	sort.Float64s(buckets)
+	m := &metric{
+		typ:     "histogram",
+		opts:    opts.Opts,
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+	err := r.register(m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//WriteTo write all metrics to writer in text exposition format.
+//Return bytes written and any error if raised.
+func (r *MemoryRegistry) WriteTo(w io.Writer) (int64, error) {
+	r.locker.Lock()
+	metrics := make([]*metric, len(r.metrics))
+	copy(metrics, r.metrics)
+	r.locker.Unlock()
+	cw := &countWriter{writer: w}
+	bw := bufio.NewWriter(cw)
+	for k := range metrics {
+		metrics[k].writeTo(bw)
+	}
+	err := bw.Flush()
+	return cw.count, err
+}
+
+//ServeHTTP serve metrics as http handler.
+//Write error will be passed to OnError.
+func (r *MemoryRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_, err := r.WriteTo(w)
+	if err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+//NewMemoryRegistry create new memory registry.
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		names: map[string]bool{},
+	}
+}
+
+type countWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (w *countWriter) Write(data []byte) (int, error) {
+	n, err := w.writer.Write(data)
+	w.count += int64(n)
+	return n, err
+}
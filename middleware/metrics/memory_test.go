@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMemoryRegistry(t *testing.T) {
+	r := NewMemoryRegistry()
+	c, err := r.NewCounter(&Opts{
+		Name:   "test_total",
+		Help:   "Test\\help\ntext",
+		Labels: []string{"label"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := r.NewHistogram(&HistogramOpts{
+		Opts: Opts{
+			Name: "test_histogram",
+		},
+		Buckets: []float64{1, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add(1, "quote\"")
+	c.Add(1.5, "quote\"")
+	c.Add(1, "a")
+	h.Observe(1)
+	h.Observe(1.5)
+	h.Observe(3)
+	buf := bytes.NewBuffer(nil)
+	n, err := r.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatal(n)
+	}
+	expected := "# HELP test_total Test\\\\help\\ntext\n" +
+		"# TYPE test_total counter\n" +
+		"test_total{label=\"a\"} 1\n" +
+		"test_total{label=\"quote\\\"\"} 2.5\n" +
+		"# TYPE test_histogram histogram\n" +
+		"test_histogram_bucket{le=\"1\"} 1\n" +
+		"test_histogram_bucket{le=\"2\"} 2\n" +
+		"test_histogram_bucket{le=\"+Inf\"} 3\n" +
+		"test_histogram_sum 5.5\n" +
+		"test_histogram_count 3\n"
+	if buf.String() != expected {
+		t.Fatal(buf.String())
+	}
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok || !errors.Is(err, ErrLabelsNotMatch) || !strings.Contains(err.Error(), "test_total") {
+			t.Fatal(r)
+		}
+	}()
+	c.Add(1)
+}
+
+type errorResponseWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w errorResponseWriter) Write(data []byte) (int, error) {
+	return 0, errTestWrite
+}
+
+var errTestWrite = errors.New("write error")
+
+func TestMemoryRegistryWriteError(t *testing.T) {
+	r := NewMemoryRegistry()
+	c, err := r.NewCounter(&Opts{Name: "test_total"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add(1)
+	w := errorResponseWriter{httptest.NewRecorder()}
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	var reported error
+	r.OnError = func(err error) {
+		reported = err
+	}
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if reported != errTestWrite {
+		t.Fatal(reported)
+	}
+}
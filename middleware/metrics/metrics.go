@@ -0,0 +1,197 @@
+//Package metrics provide request metrics middleware with prometheus text exposition format output.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/herb-go/herb/middleware/httpinfo"
+	"github.com/herb-go/herb/middleware/httpinfo/httphook"
+	"github.com/herb-go/herb/middleware/router"
+)
+
+//Label names used by middleware
+const (
+	LabelMethod = "method"
+	LabelStatus = "status"
+	LabelRoute  = "route"
+)
+
+//RouteUnnamed route label value for requests without route name.
+const RouteUnnamed = "unnamed"
+
+//RouteOther route label value for route names over MaxRoutes limit.
+const RouteOther = "other"
+
+//MethodOther method label value for nonstandard methods.
+const MethodOther = "OTHER"
+
+//DefaultMaxRoutes default max route names count.
+const DefaultMaxRoutes = 100
+
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+type contextKey string
+
+const contextKeyStart = contextKey("start")
+
+//Config metrics middleware config
+type Config struct {
+	//Namespace metric name prefix.
+	//Default value is "herb".
+	Namespace string
+	//DurationBuckets request duration buckets in seconds.
+	//DefaultDurationBuckets will be used if empty.
+	DurationBuckets []float64
+	//SizeBuckets response size buckets in bytes.
+	//DefaultSizeBuckets will be used if empty.
+	SizeBuckets []float64
+	//MaxRoutes max distinct route names.
+	//Route names over limit will be recorded as "other".
+	//DefaultMaxRoutes will be used if not greater than 0.
+	MaxRoutes int
+}
+
+//Middleware metrics middleware.
+type Middleware struct {
+	requests  Counter
+	durations Histogram
+	sizes     Histogram
+	hook      *httphook.Hook
+	maxRoutes int
+	locker    sync.RWMutex
+	routes    map[string]bool
+}
+
+func (m *Middleware) routeLabel(name string) string {
+	if name == "" {
+		return RouteUnnamed
+	}
+	m.locker.RLock()
+	ok := m.routes[name]
+	count := len(m.routes)
+	m.locker.RUnlock()
+	if ok {
+		return name
+	}
+	if count >= m.maxRoutes {
+		return RouteOther
+	}
+	m.locker.Lock()
+	defer m.locker.Unlock()
+	if len(m.routes) >= m.maxRoutes {
+		return RouteOther
+	}
+	m.routes[name] = true
+	return name
+}
+
+func (m *Middleware) handle(r *http.Request, resp *httpinfo.Response) {
+	method := r.Method
+	if !knownMethods[method] {
+		method = MethodOther
+	}
+	labels := []string{method, StatusClass(resp.StatusCode), m.routeLabel(router.GetRoute(r).Name)}
+	m.requests.Add(1, labels...)
+	if start, ok := r.Context().Value(contextKeyStart).(time.Time); ok {
+		m.durations.Observe(time.Since(start).Seconds(), labels...)
+	}
+	m.sizes.Observe(float64(resp.ContentLength), labels...)
+}
+
+//ServeMiddleware serve as middleware.
+func (m *Middleware) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx := context.WithValue(r.Context(), contextKeyStart, time.Now())
+	*r = *r.WithContext(ctx)
+	router.GetRoute(r)
+	m.hook.ServeMiddleware(w, r, next)
+}
+
+//StatusClass return status class label value of given status code,"2xx" for example.
+func StatusClass(status int) string {
+	switch {
+	case status >= 100 && status < 200:
+		return "1xx"
+	case status >= 200 && status < 300:
+		return "2xx"
+	case status >= 300 && status < 400:
+		return "3xx"
+	case status >= 400 && status < 500:
+		return "4xx"
+	case status >= 500 && status < 600:
+		return "5xx"
+	}
+	return "unknown"
+}
+
+//New create new metrics middleware with given registry and config.
+//Return middleware and any error if raised.
+func New(registry Registry, c *Config) (*Middleware, error) {
+	var err error
+	namespace := c.Namespace
+	if namespace == "" {
+		namespace = "herb"
+	}
+	durationBuckets := c.DurationBuckets
+	if len(durationBuckets) == 0 {
+		durationBuckets = DefaultDurationBuckets
+	}
+	sizeBuckets := c.SizeBuckets
+	if len(sizeBuckets) == 0 {
+		sizeBuckets = DefaultSizeBuckets
+	}
+	m := &Middleware{
+		maxRoutes: c.MaxRoutes,
+		routes:    map[string]bool{},
+	}
+	if m.maxRoutes <= 0 {
+		m.maxRoutes = DefaultMaxRoutes
+	}
+	labels := []string{LabelMethod, LabelStatus, LabelRoute}
+	m.requests, err = registry.NewCounter(&Opts{
+		Name:   namespace + "_http_requests_total",
+		Help:   "Total number of http requests.",
+		Labels: labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.durations, err = registry.NewHistogram(&HistogramOpts{
+		Opts: Opts{
+			Name:   namespace + "_http_request_duration_seconds",
+			Help:   "Http request duration in seconds.",
+			Labels: labels,
+		},
+		Buckets: durationBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.sizes, err = registry.NewHistogram(&HistogramOpts{
+		Opts: Opts{
+			Name:   namespace + "_http_response_size_bytes",
+			Help:   "Http response body size in bytes.",
+			Labels: labels,
+		},
+		Buckets: sizeBuckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.hook = httphook.New().
+		WithValidator(httpinfo.ValidatorAlways).
+		WithHandler(httphook.HandlerFunc(m.handle))
+	return m, nil
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/router"
+	"github.com/herb-go/herb/middleware/router/muxrouter"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := NewMemoryRegistry()
+	m, err := New(registry, &Config{
+		DurationBuckets: []float64{1, 0.5},
+		SizeBuckets:     []float64{10},
+		MaxRoutes:       1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = New(registry, &Config{})
+	if !errors.Is(err, ErrMetricRegistered) {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/ok", middleware.New(router.NewRouteNameMiddleware("ok")).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	mux.Handle("/notmodified", middleware.New(router.NewRouteNameMiddleware("notmodified")).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	mux.Handle("/large", middleware.New().HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 20)))
+	}))
+	app := middleware.New(m.ServeMiddleware).Handle(mux)
+	for _, path := range []string{"/ok", "/ok", "/notmodified", "/large"} {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if path == "/notmodified" && rec.Code != http.StatusNotModified {
+			t.Fatal(rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("PURGE", "/ok", nil))
+	rec = httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Header().Get("Content-Type") != ContentType {
+		t.Fatal(rec.Header())
+	}
+	output := rec.Body.String()
+	expected := []string{
+		"# HELP herb_http_requests_total Total number of http requests.\n",
+		"# TYPE herb_http_requests_total counter\n",
+		`herb_http_requests_total{method="GET",status="2xx",route="ok"} 2` + "\n",
+		`herb_http_requests_total{method="GET",status="3xx",route="other"} 1` + "\n",
+		`herb_http_requests_total{method="GET",status="2xx",route="unnamed"} 1` + "\n",
+		`herb_http_requests_total{method="OTHER",status="2xx",route="ok"} 1` + "\n",
+		"# TYPE herb_http_request_duration_seconds histogram\n",
+		`herb_http_request_duration_seconds_bucket{method="GET",status="2xx",route="ok",le="0.5"} 2` + "\n",
+		`herb_http_request_duration_seconds_bucket{method="GET",status="2xx",route="ok",le="+Inf"} 2` + "\n",
+		`herb_http_request_duration_seconds_count{method="GET",status="2xx",route="ok"} 2` + "\n",
+		`herb_http_response_size_bytes_bucket{method="GET",status="2xx",route="ok",le="10"} 2` + "\n",
+		`herb_http_response_size_bytes_bucket{method="GET",status="2xx",route="unnamed",le="10"} 0` + "\n",
+		`herb_http_response_size_bytes_sum{method="GET",status="2xx",route="unnamed"} 20` + "\n",
+	}
+	for _, v := range expected {
+		if !strings.Contains(output, v) {
+			t.Fatal(v, output)
+		}
+	}
+}
+
+func TestStatusClass(t *testing.T) {
+	for status, class := range map[int]string{
+		101: "1xx",
+		200: "2xx",
+		302: "3xx",
+		404: "4xx",
+		503: "5xx",
+		0:   "unknown",
+		600: "unknown",
+	} {
+		if StatusClass(status) != class {
+			t.Fatal(status, StatusClass(status))
+		}
+	}
+}
+
+func TestRouterRouteLabel(t *testing.T) {
+	registry := NewMemoryRegistry()
+	m, err := New(registry, &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := muxrouter.New()
+	r.Handle("/users/").HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	app := middleware.New(m.ServeMiddleware).Handle(r)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/12", nil))
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `herb_http_requests_total{method="GET",status="2xx",route="/users/"} 1`) {
+		t.Fatal(rec.Body.String())
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import "errors"
+
+//ErrLabelsNotMatch error raised if label values count not match metric labels.
+var ErrLabelsNotMatch = errors.New("metrics:label values not match labels")
+
+//ErrMetricRegistered error raised if metric with same name registered.
+var ErrMetricRegistered = errors.New("metrics:metric registered")
+
+//Opts metric options
+type Opts struct {
+	//Name metric full name
+	Name string
+	//Help metric help text
+	Help string
+	//Labels metric label names
+	Labels []string
+}
+
+//HistogramOpts histogram options
+type HistogramOpts struct {
+	Opts
+	//Buckets histogram bucket upper bounds in increasing order.
+	Buckets []float64
+}
+
+//Counter counter metric interface
+type Counter interface {
+	//Add add value to counter with given label values.
+	Add(value float64, labelValues ...string)
+}
+
+//Histogram histogram metric interface
+type Histogram interface {
+	//Observe observe value with given label values.
+	Observe(value float64, labelValues ...string)
+}
+
+//Registry metrics registry interface.
+//Implement registry to send metrics to other backends.
+type Registry interface {
+	//NewCounter create and register counter.
+	//Return counter and any error if raised.
+	NewCounter(opts *Opts) (Counter, error)
+	//NewHistogram create and register histogram.
+	//Return histogram and any error if raised.
+	NewHistogram(opts *HistogramOpts) (Histogram, error)
+}
+
+//DefaultDurationBuckets default request duration buckets in seconds.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+//DefaultSizeBuckets default response size buckets in bytes.
+var DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
@@ -0,0 +1,217 @@
+package middlewarefactory
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+)
+
+//Source config list source interface used by reloadable middleware.
+type Source interface {
+	//LoadConfigList load fresh config list.
+	//Return config list and any error if raised.
+	LoadConfigList() (*ConfigList, error)
+}
+
+//SourceFunc source func type
+type SourceFunc func() (*ConfigList, error)
+
+//LoadConfigList load fresh config list.
+//Return config list and any error if raised.
+func (f SourceFunc) LoadConfigList() (*ConfigList, error) {
+	return f()
+}
+
+//Pipeline middleware chain built from config list by reloadable middleware.
+//Pipeline is immutable once built.
+type Pipeline struct {
+	//Version pipeline version.Version increases by one every successful reload.
+	Version int64
+	//LoadedAt time when pipeline built.
+	LoadedAt time.Time
+	//Middleware middleware chain
+	Middleware middleware.Middleware
+	locker     sync.Mutex
+	inflight   int64
+	retired    bool
+	drained    chan struct{}
+}
+
+func (p *Pipeline) acquire() bool {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	if p.retired {
+		return false
+	}
+	p.inflight++
+	return true
+}
+
+func (p *Pipeline) release() {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	p.inflight--
+	if p.retired && p.inflight == 0 {
+		close(p.drained)
+	}
+}
+
+func (p *Pipeline) retire() {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	if p.retired {
+		return
+	}
+	p.retired = true
+	if p.inflight == 0 {
+		close(p.drained)
+	}
+}
+
+//Inflight return count of requests served by pipeline now.
+func (p *Pipeline) Inflight() int64 {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	return p.inflight
+}
+
+//Retired check if pipeline is replaced by a newer one.
+func (p *Pipeline) Retired() bool {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	return p.retired
+}
+
+//Drained return a channel which will be closed after pipeline retired and all in-flight requests finished.
+func (p *Pipeline) Drained() <-chan struct{} {
+	return p.drained
+}
+
+func newPipeline(version int64, m middleware.Middleware) *Pipeline {
+	return &Pipeline{
+		Version:    version,
+		LoadedAt:   time.Now(),
+		Middleware: m,
+		drained:    make(chan struct{}),
+	}
+}
+
+//Reloadable middleware which can rebuild its chain from source without restarting server.
+//Requests always finish on the pipeline they started with.
+type Reloadable struct {
+	//Context context used to create middlewares and conditions.
+	Context *Context
+	//Source config list source
+	Source Source
+	//OnReload callback called after new pipeline actived.
+	OnReload func(current *Pipeline, previous *Pipeline)
+	//OnError callback called when reload triggered by watcher fail.
+	OnError func(err error)
+	locker  sync.Mutex
+	version int64
+	current atomic.Value
+}
+
+//Current return current actived pipeline.
+//Return nil if reloadable is never loaded successfully.
+func (r *Reloadable) Current() *Pipeline {
+	p, _ := r.current.Load().(*Pipeline)
+	return p
+}
+
+//Version return version of current actived pipeline.
+//Return 0 if reloadable is never loaded successfully.
+func (r *Reloadable) Version() int64 {
+	p := r.Current()
+	if p == nil {
+		return 0
+	}
+	return p.Version
+}
+
+//Reload load config list from source and build new pipeline.
+//Current pipeline will be kept if any error raised.
+//Previous pipeline will be retired and drained in background.
+//Return any error if raised.
+func (r *Reloadable) Reload() error {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	c, err := r.Source.LoadConfigList()
+	if err != nil {
+		return err
+	}
+	m, err := c.Middleware(r.Context)
+	if err != nil {
+		return err
+	}
+	r.version++
+	p := newPipeline(r.version, m)
+	previous := r.Current()
+	r.current.Store(p)
+	if previous != nil {
+		previous.retire()
+	}
+	if r.OnReload != nil {
+		r.OnReload(p, previous)
+	}
+	return nil
+}
+
+//MustReload reload middleware.
+//Panic if any error raised.
+func (r *Reloadable) MustReload() {
+	err := r.Reload()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *Reloadable) acquire() *Pipeline {
+	for {
+		p := r.Current()
+		if p == nil || p.acquire() {
+			return p
+		}
+	}
+}
+
+//ServeMiddleware serve as middleware.
+//Request will be passed to next directly if reloadable is never loaded successfully.
+func (r *Reloadable) ServeMiddleware(w http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	p := r.acquire()
+	if p == nil {
+		next(w, req)
+		return
+	}
+	defer p.release()
+	p.Middleware(w, req, next)
+}
+
+func (r *Reloadable) handleError(err error) {
+	if r.OnError != nil {
+		r.OnError(err)
+	}
+}
+
+//Watch start watcher which checks source every interval and reload when source changed.
+//Source must implement ChangeDetector interface,or watcher will reload every interval.
+func (r *Reloadable) Watch(interval time.Duration) *Watcher {
+	w := &Watcher{
+		reloadable: r,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+//NewReloadable create new reloadable middleware with given context and source.
+func NewReloadable(ctx *Context, s Source) *Reloadable {
+	return &Reloadable{
+		Context: ctx,
+		Source:  s,
+	}
+}
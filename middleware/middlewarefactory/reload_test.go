@@ -0,0 +1,227 @@
+package middlewarefactory_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+func newBodyConfigList(body string) *middlewarefactory.ConfigList {
+	return &middlewarefactory.ConfigList{
+		&middlewarefactory.Config{
+			Condition: &middlewarefactory.ConditionConfig{},
+			Middlewares: []*middlewarefactory.MiddlewareConfig{
+				&middlewarefactory.MiddlewareConfig{
+					Type: "response",
+					Config: mustNewLoader(middlewarefactory.ResponseMiddleware{
+						StatusCode: 503,
+						Body:       &body,
+					}),
+				},
+			},
+		},
+	}
+}
+
+func getBody(t *testing.T, url string) string {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+var errTestSource = errors.New("test source error")
+
+func TestReloadable(t *testing.T) {
+	var body string
+	var sourceErr error
+	ctx := middlewarefactory.NewContext()
+	ctx.RegisterFactory("response", middlewarefactory.NewResponseFactory())
+	r := middlewarefactory.NewReloadable(ctx, middlewarefactory.SourceFunc(func() (*middlewarefactory.ConfigList, error) {
+		if sourceErr != nil {
+			return nil, sourceErr
+		}
+		return newBodyConfigList(body), nil
+	}))
+	app := middleware.New(r.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	s := httptest.NewServer(app)
+	defer s.Close()
+	if r.Current() != nil || r.Version() != 0 {
+		t.Fatal(r)
+	}
+	if b := getBody(t, s.URL); b != "ok" {
+		t.Fatal(b)
+	}
+	body = "maintenance"
+	r.MustReload()
+	if r.Version() != 1 {
+		t.Fatal(r.Version())
+	}
+	if b := getBody(t, s.URL); b != "maintenance" {
+		t.Fatal(b)
+	}
+	first := r.Current()
+	body = "updated"
+	r.MustReload()
+	if r.Version() != 2 {
+		t.Fatal(r.Version())
+	}
+	if !first.Retired() {
+		t.Fatal(first)
+	}
+	select {
+	case <-first.Drained():
+	case <-time.After(time.Second):
+		t.Fatal(first)
+	}
+	if b := getBody(t, s.URL); b != "updated" {
+		t.Fatal(b)
+	}
+	sourceErr = errTestSource
+	err := r.Reload()
+	if err != errTestSource {
+		t.Fatal(err)
+	}
+	sourceErr = nil
+	ctx.Middlewarefactories = map[string]middlewarefactory.Factory{}
+	err = r.Reload()
+	if !errors.Is(err, middlewarefactory.ErrFactoryNotRegistered) {
+		t.Fatal(err)
+	}
+	if r.Version() != 2 {
+		t.Fatal(r.Version())
+	}
+	if b := getBody(t, s.URL); b != "updated" {
+		t.Fatal(b)
+	}
+}
+
+func TestReloadableDrain(t *testing.T) {
+	var body = "first"
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	ctx := middlewarefactory.NewContext()
+	ctx.RegisterFactory("response", func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		m := &middlewarefactory.ResponseMiddleware{}
+		err := loader(m)
+		if err != nil {
+			return nil, err
+		}
+		return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			if r.URL.Path == "/slow" {
+				close(entered)
+				<-release
+			}
+			m.ServeMiddleware(w, r, next)
+		}, nil
+	})
+	r := middlewarefactory.NewReloadable(ctx, middlewarefactory.SourceFunc(func() (*middlewarefactory.ConfigList, error) {
+		return newBodyConfigList(body), nil
+	}))
+	r.MustReload()
+	first := r.Current()
+	done := make(chan string)
+	go func() {
+		rec := httptest.NewRecorder()
+		r.ServeMiddleware(rec, httptest.NewRequest("GET", "/slow", nil), nil)
+		done <- rec.Body.String()
+	}()
+	<-entered
+	if first.Inflight() != 1 {
+		t.Fatal(first.Inflight())
+	}
+	body = "second"
+	r.MustReload()
+	select {
+	case <-first.Drained():
+		t.Fatal(first)
+	default:
+	}
+	rec := httptest.NewRecorder()
+	r.ServeMiddleware(rec, httptest.NewRequest("GET", "/", nil), nil)
+	if rec.Body.String() != "second" {
+		t.Fatal(rec.Body.String())
+	}
+	close(release)
+	if b := <-done; b != "first" {
+		t.Fatal(b)
+	}
+	select {
+	case <-first.Drained():
+	case <-time.After(time.Second):
+		t.Fatal(first)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	err = ioutil.WriteFile(path, []byte("first"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unmarshaler := func(data []byte, v interface{}) error {
+		*(v.(*middlewarefactory.ConfigList)) = *newBodyConfigList(string(data))
+		return nil
+	}
+	ctx := middlewarefactory.NewContext()
+	ctx.RegisterFactory("response", middlewarefactory.NewResponseFactory())
+	source := middlewarefactory.NewFileSource(path, unmarshaler)
+	r := middlewarefactory.NewReloadable(ctx, source)
+	errs := make(chan error, 10)
+	reloaded := make(chan int64, 10)
+	r.OnError = func(err error) {
+		errs <- err
+	}
+	r.OnReload = func(current *middlewarefactory.Pipeline, previous *middlewarefactory.Pipeline) {
+		reloaded <- current.Version
+	}
+	r.MustReload()
+	<-reloaded
+	changed, err := source.Changed()
+	if changed || err != nil {
+		t.Fatal(changed, err)
+	}
+	w := r.Watch(10 * time.Millisecond)
+	defer w.Stop()
+	err = ioutil.WriteFile(path, []byte("second file"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-reloaded:
+		if v != 2 {
+			t.Fatal(v)
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal(r.Version())
+	}
+	rec := httptest.NewRecorder()
+	r.ServeMiddleware(rec, httptest.NewRequest("GET", "/", nil), nil)
+	if rec.Body.String() != "second file" {
+		t.Fatal(rec.Body.String())
+	}
+	w.Stop()
+}
@@ -0,0 +1,116 @@
+package middlewarefactory
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+//ChangeDetector interface which report whether source changed since last load.
+type ChangeDetector interface {
+	//Changed check if source changed since last load.
+	//Return result and any error if raised.
+	Changed() (bool, error)
+}
+
+//FileSource config list source which loads config from file.
+type FileSource struct {
+	//Path config file path
+	Path string
+	//Unmarshaler func which unmarshal file content to config list.
+	Unmarshaler func(data []byte, v interface{}) error
+	locker      sync.Mutex
+	modTime     time.Time
+	size        int64
+}
+
+//LoadConfigList load fresh config list from file.
+//Return config list and any error if raised.
+func (s *FileSource) LoadConfigList() (*ConfigList, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	c := &ConfigList{}
+	err = s.Unmarshaler(data, c)
+	if err != nil {
+		return nil, err
+	}
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return c, nil
+}
+
+//Changed check if file modified since last load.
+//Return result and any error if raised.
+func (s *FileSource) Changed() (bool, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return false, err
+	}
+	return !info.ModTime().Equal(s.modTime) || info.Size() != s.size, nil
+}
+
+//NewFileSource create new file source with given path and unmarshaler.
+func NewFileSource(path string, unmarshaler func(data []byte, v interface{}) error) *FileSource {
+	return &FileSource{
+		Path:        path,
+		Unmarshaler: unmarshaler,
+	}
+}
+
+//Watcher reloadable source watcher
+type Watcher struct {
+	reloadable *Reloadable
+	stop       chan struct{}
+	done       chan struct{}
+	once       sync.Once
+}
+
+func (w *Watcher) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer close(w.done)
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+func (w *Watcher) check() {
+	if d, ok := w.reloadable.Source.(ChangeDetector); ok {
+		changed, err := d.Changed()
+		if err != nil {
+			w.reloadable.handleError(err)
+			return
+		}
+		if !changed {
+			return
+		}
+	}
+	err := w.reloadable.Reload()
+	if err != nil {
+		w.reloadable.handleError(err)
+	}
+}
+
+//Stop stop watcher and wait until watcher goroutine exited.
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
@@ -0,0 +1,69 @@
+package pagecache
+
+import (
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+//Config cache middleware config
+type Config struct {
+	//TTLInSecond default fresh time in second.
+	TTLInSecond int64
+	//StaleWhileRevalidateInSecond default stale while revalidate time in second.
+	StaleWhileRevalidateInSecond int64
+	//Methods cacheable request methods.
+	//DefaultMethods will be used if empty.
+	Methods []string
+	//StatusCodes cacheable response status codes.
+	//DefaultStatusCodes will be used if empty.
+	StatusCodes []int
+	//MaxBodySize max cacheable body size.
+	MaxBodySize int
+	//IgnoreHost whether request host is not part of key.
+	IgnoreHost bool
+	//IgnoreQuery whether request query is not part of key.
+	IgnoreQuery bool
+	//Headers request header names which are part of key.
+	Headers []string
+	//StatusHeader header name which reports cache status.
+	//DefaultStatusHeader will be used if empty.
+	StatusHeader string
+}
+
+//CreateCache create cache middleware with given store.
+func (c *Config) CreateCache(s Store) *Cache {
+	cache := New(s)
+	cache.TTL = time.Duration(c.TTLInSecond) * time.Second
+	cache.StaleWhileRevalidate = time.Duration(c.StaleWhileRevalidateInSecond) * time.Second
+	if len(c.Methods) > 0 {
+		cache.Methods = c.Methods
+	}
+	if len(c.StatusCodes) > 0 {
+		cache.StatusCodes = c.StatusCodes
+	}
+	cache.MaxBodySize = c.MaxBodySize
+	key := NewKeyBuilder()
+	key.Host = !c.IgnoreHost
+	key.Query = !c.IgnoreQuery
+	key.Headers = c.Headers
+	cache.Key = key
+	if c.StatusHeader != "" {
+		cache.StatusHeader = c.StatusHeader
+	}
+	return cache
+}
+
+//NewFactory create new cache middleware factory with given store.
+//Use middlewarefactory.Config conditions to choose cached routes.
+func NewFactory(s Store) middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.CreateCache(s).ServeMiddleware, nil
+	}
+}
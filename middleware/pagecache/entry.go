@@ -0,0 +1,104 @@
+package pagecache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Entry cached response entry
+type Entry struct {
+	//StatusCode response status code
+	StatusCode int
+	//Header response header
+	Header http.Header
+	//Body response body
+	Body []byte
+	//Vary request header names response varies by.
+	//Entry with vary is a vary marker,real entries are stored by variant keys.
+	Vary []string
+	//StoredAt time when entry stored
+	StoredAt time.Time
+	//Expires time after which entry is stale
+	Expires time.Time
+	//StaleUntil time until which stale entry can be served while revalidating
+	StaleUntil time.Time
+}
+
+//Fresh check if entry is fresh at given time.
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+//Usable check if entry is fresh or can be served while revalidating at given time.
+func (e *Entry) Usable(now time.Time) bool {
+	return now.Before(e.Expires) || now.Before(e.StaleUntil)
+}
+
+//CacheControl parsed cache control directives.
+//Directive names are converted to lower.
+type CacheControl map[string]string
+
+//Has check if directive exists.
+func (c CacheControl) Has(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+//Seconds return directive value in seconds.
+//Return false if directive not exists or value is invalid.
+func (c CacheControl) Seconds(name string) (time.Duration, bool) {
+	v, ok := c[name]
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return time.Duration(i) * time.Second, true
+}
+
+//ParseCacheControl parse cache control directives from given header.
+func ParseCacheControl(h http.Header) CacheControl {
+	c := CacheControl{}
+	for _, line := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(line, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+			kv := strings.SplitN(directive, "=", 2)
+			name := strings.ToLower(strings.TrimSpace(kv[0]))
+			if len(kv) == 2 {
+				c[name] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+			} else {
+				c[name] = ""
+			}
+		}
+	}
+	return c
+}
+
+//ParseVary parse canonical header names from vary header.
+func ParseVary(h http.Header) []string {
+	result := []string{}
+	for _, line := range h.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				result = append(result, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return result
+}
+
+func variantKey(key string, vary []string, r *http.Request) string {
+	values := make([]string, len(vary))
+	for k := range vary {
+		values[k] = vary[k] + ":" + strings.Join(r.Header.Values(vary[k]), ",")
+	}
+	return key + "\n" + strings.Join(values, "\n")
+}
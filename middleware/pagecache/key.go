@@ -0,0 +1,66 @@
+package pagecache
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/herb-go/herb/identifier"
+)
+
+//KeyBuilder identifier which build cache key from request.
+type KeyBuilder struct {
+	//Method whether request method is part of key.
+	Method bool
+	//Host whether request host is part of key.
+	Host bool
+	//Path whether request path is part of key.
+	Path bool
+	//Query whether request query is part of key.
+	//Query is normalized by sorting before used.
+	Query bool
+	//Headers request header names which are part of key.
+	Headers []string
+	//Identifier extra identifier which is part of key,
+	//for example to cache pages per user.
+	//Request with empty identification will still be cached.
+	Identifier identifier.Identifier
+}
+
+//IdentifyRequest identify http request
+//return cache key and any error if rasied.
+func (b *KeyBuilder) IdentifyRequest(r *http.Request) (string, error) {
+	fields := []string{}
+	if b.Method {
+		fields = append(fields, r.Method)
+	}
+	if b.Host {
+		fields = append(fields, strings.ToLower(r.Host))
+	}
+	if b.Path {
+		fields = append(fields, r.URL.EscapedPath())
+	}
+	if b.Query {
+		fields = append(fields, r.URL.Query().Encode())
+	}
+	for _, name := range b.Headers {
+		fields = append(fields, strings.Join(r.Header.Values(name), ","))
+	}
+	if b.Identifier != nil {
+		id, err := b.Identifier.IdentifyRequest(r)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, id)
+	}
+	return strings.Join(fields, "\n"), nil
+}
+
+//NewKeyBuilder create new key builder which builds key by method,host,path and query.
+func NewKeyBuilder() *KeyBuilder {
+	return &KeyBuilder{
+		Method: true,
+		Host:   true,
+		Path:   true,
+		Query:  true,
+	}
+}
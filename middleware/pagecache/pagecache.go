@@ -0,0 +1,384 @@
+//Package pagecache provide response caching middleware.
+package pagecache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/herb-go/herb/identifier"
+	"github.com/herb-go/herb/middleware/httpinfo"
+)
+
+//DefaultStatusHeader default header name which reports cache status.
+const DefaultStatusHeader = "X-Cache"
+
+//Cache status values written to status header.
+const (
+	StatusHit   = "HIT"
+	StatusStale = "STALE"
+	StatusMiss  = "MISS"
+)
+
+//DefaultMethods default cacheable request methods.
+var DefaultMethods = []string{http.MethodGet, http.MethodHead}
+
+//DefaultStatusCodes default cacheable response status codes.
+var DefaultStatusCodes = []int{http.StatusOK}
+
+//Cache response cache middleware struct
+type Cache struct {
+	//Store entry store.
+	Store Store
+	//Key identifier which creates cache key from request.
+	Key identifier.Identifier
+	//TTL default fresh time used when response has no max-age,s-maxage or expires.
+	//Response without explicit freshness will not be cached if TTL not greater than 0.
+	TTL time.Duration
+	//StaleWhileRevalidate default time in which stale entry can be served while revalidating.
+	//Overwritten by stale-while-revalidate directive of response.
+	StaleWhileRevalidate time.Duration
+	//Methods cacheable request methods.
+	Methods []string
+	//StatusCodes cacheable response status codes.
+	StatusCodes []int
+	//MaxBodySize max cacheable body size.
+	//Responses larger than MaxBodySize are streamed without caching.
+	//Unlimited if not greater than 0.
+	MaxBodySize int
+	//StatusHeader header name which reports cache status.
+	//Cache status will not be reported if empty.
+	StatusHeader string
+	//OnError func called when store or identifier error raised,
+	//or revalidation panicked.
+	//Request will be served without cache when error raised.
+	OnError func(err error)
+	locker  sync.Mutex
+	calls   map[string]*call
+}
+
+type call struct {
+	done chan struct{}
+}
+
+func (c *Cache) onError(err error) {
+	if c.OnError != nil {
+		c.OnError(err)
+	}
+}
+
+func (c *Cache) join(key string) (*call, bool) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	if cl := c.calls[key]; cl != nil {
+		return cl, false
+	}
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	return cl, true
+}
+
+func (c *Cache) leave(key string, cl *call) {
+	c.locker.Lock()
+	delete(c.calls, key)
+	c.locker.Unlock()
+	close(cl.done)
+}
+
+func (c *Cache) cacheableMethod(method string) bool {
+	for _, v := range c.Methods {
+		if v == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cache) cacheableStatus(statusCode int) bool {
+	for _, v := range c.StatusCodes {
+		if v == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cache) load(key string, r *http.Request) *Entry {
+	e, err := c.Store.Load(key)
+	if err == nil && len(e.Vary) > 0 {
+		e, err = c.Store.Load(variantKey(key, e.Vary, r))
+	}
+	if err != nil {
+		if err != ErrEntryNotFound {
+			c.onError(err)
+		}
+		return nil
+	}
+	return e
+}
+
+func (c *Cache) save(key string, r *http.Request, e *Entry) {
+	var err error
+	if len(e.Vary) > 0 {
+		marker := &Entry{
+			Vary:       e.Vary,
+			StoredAt:   e.StoredAt,
+			Expires:    e.Expires,
+			StaleUntil: e.StaleUntil,
+		}
+		err = c.Store.Save(key, marker)
+		if err == nil {
+			err = c.Store.Save(variantKey(key, e.Vary, r), e)
+		}
+	} else {
+		err = c.Store.Save(key, e)
+	}
+	if err != nil {
+		c.onError(err)
+	}
+}
+
+//CreateEntry create cache entry from given request and response.
+//Response to request with Authorization header is cacheable only if it has public,s-maxage or must-revalidate directive.
+//Return nil if response is not cacheable.
+func (c *Cache) CreateEntry(r *http.Request, statusCode int, header http.Header, body []byte) *Entry {
+	if !c.cacheableStatus(statusCode) {
+		return nil
+	}
+	if c.MaxBodySize > 0 && len(body) > c.MaxBodySize {
+		return nil
+	}
+	if len(header.Values("Set-Cookie")) > 0 {
+		return nil
+	}
+	cc := ParseCacheControl(header)
+	if cc.Has("no-store") || cc.Has("private") || cc.Has("no-cache") {
+		return nil
+	}
+	//Responses to authorized requests are not stored in shared cache unless explicitly allowed.
+	//See RFC 9111 section 3.5.
+	if r.Header.Get("Authorization") != "" && !cc.Has("public") && !cc.Has("s-maxage") && !cc.Has("must-revalidate") {
+		return nil
+	}
+	vary := ParseVary(header)
+	for _, v := range vary {
+		if v == "*" {
+			return nil
+		}
+	}
+	now := time.Now()
+	ttl, ok := cc.Seconds("s-maxage")
+	if !ok {
+		ttl, ok = cc.Seconds("max-age")
+	}
+	if !ok {
+		ttl = c.TTL
+		if expires := header.Get("Expires"); expires != "" {
+			t, err := http.ParseTime(expires)
+			if err != nil {
+				return nil
+			}
+			ttl = t.Sub(now)
+		}
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	swr, ok := cc.Seconds("stale-while-revalidate")
+	if !ok {
+		swr = c.StaleWhileRevalidate
+	}
+	e := &Entry{
+		StatusCode: statusCode,
+		Header:     header.Clone(),
+		Body:       append([]byte{}, body...),
+		Vary:       vary,
+		StoredAt:   now,
+		Expires:    now.Add(ttl),
+	}
+	e.StaleUntil = e.Expires.Add(swr)
+	return e
+}
+
+func (c *Cache) serve(w http.ResponseWriter, r *http.Request, e *Entry, status string) {
+	h := w.Header()
+	for k := range e.Header {
+		h[k] = append([]string{}, e.Header[k]...)
+	}
+	h.Set("Age", strconv.FormatInt(int64(time.Since(e.StoredAt)/time.Second), 10))
+	if c.StatusHeader != "" {
+		h.Set(c.StatusHeader, status)
+	}
+	w.WriteHeader(e.StatusCode)
+	if r.Method != http.MethodHead {
+		w.Write(e.Body)
+	}
+}
+
+func (c *Cache) bufferable(r *http.Request, resp *httpinfo.Response) (bool, error) {
+	if !c.cacheableStatus(resp.StatusCode) {
+		return false, nil
+	}
+	return c.MaxBodySize <= 0 || resp.ContentLength <= c.MaxBodySize, nil
+}
+
+func (c *Cache) fetch(w http.ResponseWriter, r *http.Request, next http.HandlerFunc, key string) {
+	resp := httpinfo.NewResponse()
+	resp.UpdateAutocommit(false)
+	resp.UpdateController(httpinfo.NewCommitController(r, resp).WithChecker(httpinfo.ValidatorFunc(c.bufferable)))
+	if c.StatusHeader != "" {
+		w.Header().Set(c.StatusHeader, StatusMiss)
+	}
+	next(resp.WrapWriter(w), r)
+	if !resp.Autocommit() {
+		e := c.CreateEntry(r, resp.StatusCode, resp.Header(), resp.UncommittedData())
+		if e != nil {
+			c.save(key, r, e)
+		}
+	}
+	resp.Commit()
+}
+
+func (c *Cache) revalidate(key string, r *http.Request, next http.HandlerFunc) {
+	cl, leader := c.join(key)
+	if !leader {
+		return
+	}
+	req := r.Clone(detachedContext{r.Context()})
+	go func() {
+		defer c.leave(key, cl)
+		defer func() {
+			if p := recover(); p != nil {
+				c.onError(fmt.Errorf("pagecache:revalidation panic: %v", p))
+			}
+		}()
+		rec := newRecorder()
+		next(rec, req)
+		e := c.CreateEntry(req, rec.statusCode, rec.header, rec.body.Bytes())
+		if e != nil {
+			c.save(key, req, e)
+		}
+	}()
+}
+
+//ServeMiddleware serve as middleware.
+//Fresh entry will be served directly.
+//Stale entry will be served while revalidating in background.
+//Concurrent misses on same key are coalesced,only first request will call next.
+func (c *Cache) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !c.cacheableMethod(r.Method) {
+		next(w, r)
+		return
+	}
+	reqcc := ParseCacheControl(r.Header)
+	if reqcc.Has("no-store") {
+		next(w, r)
+		return
+	}
+	key, err := c.Key.IdentifyRequest(r)
+	if err != nil {
+		c.onError(err)
+		next(w, r)
+		return
+	}
+	if !reqcc.Has("no-cache") {
+		e := c.load(key, r)
+		if e != nil {
+			if e.Fresh(time.Now()) {
+				c.serve(w, r, e, StatusHit)
+				return
+			}
+			c.revalidate(key, r, next)
+			c.serve(w, r, e, StatusStale)
+			return
+		}
+	}
+	cl, leader := c.join(key)
+	if !leader {
+		select {
+		case <-cl.done:
+		case <-r.Context().Done():
+			return
+		}
+		e := c.load(key, r)
+		if e != nil {
+			c.serve(w, r, e, StatusHit)
+			return
+		}
+		c.fetch(w, r, next, key)
+		return
+	}
+	defer c.leave(key, cl)
+	c.fetch(w, r, next, key)
+}
+
+//New create new cache middleware with given store.
+//Responses without explicit freshness will not be cached by default.
+func New(s Store) *Cache {
+	return &Cache{
+		Store:        s,
+		Key:          NewKeyBuilder(),
+		Methods:      DefaultMethods,
+		StatusCodes:  DefaultStatusCodes,
+		StatusHeader: DefaultStatusHeader,
+		calls:        map[string]*call{},
+	}
+}
+
+//detachedContext context which keeps values of parent but never canceled.
+//Used by background revalidation after original request finished.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+type recorder struct {
+	header      http.Header
+	statusCode  int
+	wroteHeader bool
+	body        *bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.statusCode = statusCode
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(data)
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		header:     http.Header{},
+		statusCode: http.StatusOK,
+		body:       bytes.NewBuffer(nil),
+	}
+}
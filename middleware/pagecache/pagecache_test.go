@@ -0,0 +1,308 @@
+package pagecache
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/persist"
+)
+
+func get(app http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	for k := range header {
+		req.Header[k] = header[k]
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCache(t *testing.T) {
+	var count int64
+	c := New(NewMemoryStore())
+	c.TTL = time.Minute
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&count, 1)
+		switch r.URL.Path {
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/cookie":
+			http.SetCookie(w, &http.Cookie{Name: "test", Value: "test"})
+		case "/notfound":
+			w.WriteHeader(404)
+		case "/expired":
+			w.Header().Set("Cache-Control", "max-age=0")
+		}
+		w.Header().Set("test", "test")
+		w.Write([]byte(strconv.FormatInt(n, 10)))
+	})
+	rec := get(app, "/page", nil)
+	if rec.Code != 200 || rec.Body.String() != "1" || rec.Header().Get(DefaultStatusHeader) != StatusMiss || rec.Header().Get("test") != "test" {
+		t.Fatal(rec.Code, rec.Body.String(), rec.Header())
+	}
+	rec = get(app, "/page", nil)
+	if rec.Code != 200 || rec.Body.String() != "1" || rec.Header().Get(DefaultStatusHeader) != StatusHit || rec.Header().Get("test") != "test" || rec.Header().Get("Age") != "0" {
+		t.Fatal(rec.Code, rec.Body.String(), rec.Header())
+	}
+	rec = get(app, "/page?a=1", nil)
+	if rec.Body.String() != "2" {
+		t.Fatal(rec.Body.String())
+	}
+	rec = get(app, "/page", http.Header{"Cache-Control": []string{"no-cache"}})
+	if rec.Body.String() != "3" {
+		t.Fatal(rec.Body.String())
+	}
+	rec = get(app, "/page", nil)
+	if rec.Body.String() != "3" {
+		t.Fatal(rec.Body.String())
+	}
+	for _, path := range []string{"/nostore", "/cookie", "/notfound", "/expired"} {
+		first := get(app, path, nil).Body.String()
+		second := get(app, path, nil).Body.String()
+		if first == second {
+			t.Fatal(path, first, second)
+		}
+	}
+	req := httptest.NewRequest("POST", "/page", nil)
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Body.String() == "3" || rec.Header().Get(DefaultStatusHeader) != "" {
+		t.Fatal(rec.Body.String())
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	var count int64
+	c := New(NewMemoryStore())
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&count, 1)
+		switch r.URL.Path {
+		case "/public":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/shared":
+			w.Header().Set("Cache-Control", "s-maxage=60")
+		case "/revalidate":
+			w.Header().Set("Cache-Control", "max-age=60, must-revalidate")
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		w.Write([]byte(r.Header.Get("Authorization") + strconv.FormatInt(n, 10)))
+	})
+	auth := http.Header{"Authorization": []string{"Basic user"}}
+	rec := get(app, "/page", auth)
+	if rec.Body.String() != "Basic user1" {
+		t.Fatal(rec.Body.String())
+	}
+	rec = get(app, "/page", nil)
+	if rec.Body.String() != "2" || rec.Header().Get(DefaultStatusHeader) != StatusMiss {
+		t.Fatal(rec.Body.String(), rec.Header())
+	}
+	for _, path := range []string{"/public", "/shared", "/revalidate"} {
+		first := get(app, path, auth).Body.String()
+		rec = get(app, path, nil)
+		if rec.Body.String() != first || rec.Header().Get(DefaultStatusHeader) != StatusHit {
+			t.Fatal(path, first, rec.Body.String())
+		}
+	}
+}
+
+func TestVary(t *testing.T) {
+	var count int64
+	c := New(NewMemoryStore())
+	c.TTL = time.Minute
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.Header().Set("Vary", "Accept-Language")
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	})
+	for i := 0; i < 2; i++ {
+		for _, lang := range []string{"en", "zh"} {
+			rec := get(app, "/", http.Header{"Accept-Language": []string{lang}})
+			if rec.Body.String() != lang {
+				t.Fatal(lang, rec.Body.String())
+			}
+		}
+	}
+	if count != 2 {
+		t.Fatal(count)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var count int64
+	revalidated := make(chan bool, 1)
+	c := New(NewMemoryStore())
+	c.TTL = 50 * time.Millisecond
+	c.StaleWhileRevalidate = time.Minute
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&count, 1)
+		w.Write([]byte(strconv.FormatInt(n, 10)))
+		if n > 1 {
+			revalidated <- true
+		}
+	})
+	rec := get(app, "/", nil)
+	if rec.Body.String() != "1" {
+		t.Fatal(rec.Body.String())
+	}
+	time.Sleep(100 * time.Millisecond)
+	rec = get(app, "/", nil)
+	if rec.Body.String() != "1" || rec.Header().Get(DefaultStatusHeader) != StatusStale {
+		t.Fatal(rec.Body.String(), rec.Header())
+	}
+	<-revalidated
+	time.Sleep(10 * time.Millisecond)
+	rec = get(app, "/", nil)
+	if rec.Body.String() != "2" || rec.Header().Get(DefaultStatusHeader) != StatusHit {
+		t.Fatal(rec.Body.String(), rec.Header())
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	var count int64
+	release := make(chan bool)
+	c := New(NewMemoryStore())
+	c.TTL = time.Minute
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		<-release
+		w.Write([]byte("ok"))
+	})
+	wg := sync.WaitGroup{}
+	results := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- get(app, "/", nil).Body.String()
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+	for result := range results {
+		if result != "ok" {
+			t.Fatal(result)
+		}
+	}
+	if count != 1 {
+		t.Fatal(count)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	var count int64
+	c := New(NewMemoryStore())
+	c.TTL = time.Minute
+	c.MaxBodySize = 4
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.Write([]byte("123"))
+		w.Write([]byte("456"))
+		w.Write([]byte("789"))
+	})
+	for i := 0; i < 2; i++ {
+		rec := get(app, "/", nil)
+		if rec.Body.String() != "123456789" {
+			t.Fatal(rec.Body.String())
+		}
+	}
+	if count != 2 {
+		t.Fatal(count)
+	}
+}
+
+func TestPersistStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	ps := persist.FolderStore(filepath.Join(dir, "cache"))
+	err = ps.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ps.Stop()
+	s := NewPersistStore(ps)
+	_, err = s.Load("key")
+	if err != ErrEntryNotFound {
+		t.Fatal(err)
+	}
+	var count int64
+	data, err := json.Marshal(&Config{IgnoreQuery: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory(s)(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Write([]byte("ok"))
+	})
+	get(app, "/?a=1", nil)
+	rec := get(app, "/?a=2", nil)
+	if rec.Body.String() != "ok" || rec.Header().Get(DefaultStatusHeader) != StatusHit || count != 1 {
+		t.Fatal(rec.Body.String(), rec.Header(), count)
+	}
+}
+
+func TestParseCacheControl(t *testing.T) {
+	h := http.Header{}
+	h.Add("Cache-Control", `Public, max-age="60"`)
+	h.Add("Cache-Control", "stale-while-revalidate=abc")
+	c := ParseCacheControl(h)
+	if !c.Has("public") || c.Has("private") {
+		t.Fatal(c)
+	}
+	if d, ok := c.Seconds("max-age"); !ok || d != time.Minute {
+		t.Fatal(d, ok)
+	}
+	if _, ok := c.Seconds("stale-while-revalidate"); ok {
+		t.Fatal(ok)
+	}
+}
+
+func TestCoalesceCanceled(t *testing.T) {
+	release := make(chan bool)
+	defer close(release)
+	c := New(NewMemoryStore())
+	c.TTL = time.Minute
+	app := middleware.New(c.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("ok"))
+	})
+	go get(app, "/", nil)
+	time.Sleep(50 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	done := make(chan bool)
+	go func() {
+		app.ServeHTTP(rec, req)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiter not returned after request canceled")
+	}
+}
@@ -0,0 +1,145 @@
+package pagecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/herb-go/herb/persist"
+)
+
+//ErrEntryNotFound error raised when entry not found in store.
+var ErrEntryNotFound = errors.New("pagecache:entry not found")
+
+//Store cache entry store interface
+type Store interface {
+	//Load load entry by given key.
+	//Return entry and any error if raised.
+	//ErrEntryNotFound should be returned if entry not found or expired.
+	Load(key string) (*Entry, error)
+	//Save save entry with given key.
+	//Entry can be discarded after its StaleUntil.
+	//Return any error if raised.
+	Save(key string, e *Entry) error
+}
+
+//MemoryStore in-memory entry store
+type MemoryStore struct {
+	//MaxEntries max entries count.
+	//Unlimited if not greater than 0.
+	MaxEntries int
+	locker     sync.Mutex
+	entries    map[string]*Entry
+}
+
+//Load load entry by given key.
+//Return entry and any error if raised.
+func (s *MemoryStore) Load(key string) (*Entry, error) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	e := s.entries[key]
+	if e == nil {
+		return nil, ErrEntryNotFound
+	}
+	if !e.Usable(time.Now()) {
+		delete(s.entries, key)
+		return nil, ErrEntryNotFound
+	}
+	return e, nil
+}
+
+//Save save entry with given key.
+//Return any error if raised.
+func (s *MemoryStore) Save(key string, e *Entry) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	if s.MaxEntries > 0 && len(s.entries) >= s.MaxEntries && s.entries[key] == nil {
+		s.evict()
+	}
+	s.entries[key] = e
+	return nil
+}
+
+func (s *MemoryStore) evict() {
+	now := time.Now()
+	for k := range s.entries {
+		if !s.entries[k].Usable(now) {
+			delete(s.entries, k)
+		}
+	}
+	for k := range s.entries {
+		if len(s.entries) < s.MaxEntries {
+			return
+		}
+		delete(s.entries, k)
+	}
+}
+
+//Len return entries count in store.
+func (s *MemoryStore) Len() int {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+	return len(s.entries)
+}
+
+//NewMemoryStore create new memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*Entry{},
+	}
+}
+
+//PersistStore entry store which saves entries to persist store.
+//Keys are hashed before saving.
+type PersistStore struct {
+	Store persist.Store
+}
+
+func (s *PersistStore) key(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+//Load load entry by given key.
+//Return entry and any error if raised.
+func (s *PersistStore) Load(key string) (*Entry, error) {
+	data, err := s.Store.LoadBytes(s.key(key))
+	if err != nil {
+		if err == persist.ErrNotFound {
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrEntryNotFound
+	}
+	e := &Entry{}
+	err = json.Unmarshal(data, e)
+	if err != nil {
+		return nil, err
+	}
+	if !e.Usable(time.Now()) {
+		return nil, ErrEntryNotFound
+	}
+	return e, nil
+}
+
+//Save save entry with given key.
+//Return any error if raised.
+func (s *PersistStore) Save(key string, e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.Store.SaveBytes(s.key(key), data)
+}
+
+//NewPersistStore create new persist store with given persist.Store.
+func NewPersistStore(s persist.Store) *PersistStore {
+	return &PersistStore{
+		Store: s,
+	}
+}
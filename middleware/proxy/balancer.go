@@ -0,0 +1,90 @@
+package proxy
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+//ErrUnknownBalancer error raised when balancer name is unknown.
+var ErrUnknownBalancer = errors.New("proxy:unknown balancer")
+
+//Balancer upstream selecting strategy
+type Balancer interface {
+	//Select select one of given available upstreams.
+	//Upstreams is never empty.
+	Select(upstreams []*Upstream) *Upstream
+}
+
+//RoundRobin round robin balancer
+type RoundRobin struct {
+	counter uint64
+}
+
+//Select select one of given available upstreams.
+func (b *RoundRobin) Select(upstreams []*Upstream) *Upstream {
+	n := atomic.AddUint64(&b.counter, 1) - 1
+	return upstreams[n%uint64(len(upstreams))]
+}
+
+//LeastConnections balancer which selects upstream with least active requests.
+//Upstreams with same active requests count are selected in round robin order.
+type LeastConnections struct {
+	counter uint64
+}
+
+//Select select one of given available upstreams.
+func (b *LeastConnections) Select(upstreams []*Upstream) *Upstream {
+	n := int(atomic.AddUint64(&b.counter, 1) % uint64(len(upstreams)))
+	var result *Upstream
+	for i := range upstreams {
+		u := upstreams[(n+i)%len(upstreams)]
+		if result == nil || u.Active() < result.Active() {
+			result = u
+		}
+	}
+	return result
+}
+
+//Weighted smooth weighted round robin balancer
+type Weighted struct {
+	locker  sync.Mutex
+	current map[*Upstream]int
+}
+
+//Select select one of given available upstreams.
+func (b *Weighted) Select(upstreams []*Upstream) *Upstream {
+	b.locker.Lock()
+	defer b.locker.Unlock()
+	if b.current == nil {
+		b.current = map[*Upstream]int{}
+	}
+	var total int
+	var result *Upstream
+	for _, u := range upstreams {
+		w := u.weight()
+		total = total + w
+		b.current[u] = b.current[u] + w
+		if result == nil || b.current[u] > b.current[result] {
+			result = u
+		}
+	}
+	b.current[result] = b.current[result] - total
+	return result
+}
+
+//NewBalancer create new balancer by name.
+//Available names are "roundrobin","leastconn" and "weighted".
+//Round robin balancer will be returned if name is empty.
+//Return balancer and any error if raised.
+func NewBalancer(name string) (Balancer, error) {
+	switch name {
+	case "", "roundrobin":
+		return &RoundRobin{}, nil
+	case "leastconn":
+		return &LeastConnections{}, nil
+	case "weighted":
+		return &Weighted{}, nil
+	}
+	return nil, ErrUnknownBalancer
+}
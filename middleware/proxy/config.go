@@ -0,0 +1,125 @@
+package proxy
+
+import (
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//UpstreamConfig upstream config
+type UpstreamConfig struct {
+	//URL upstream base url
+	URL string
+	//Weight upstream weight
+	Weight int
+}
+
+//TargetConfig target config
+type TargetConfig struct {
+	//Pattern request pattern.
+	//All requests will be matched if nil.
+	Pattern *requestmatching.PatternConfig
+	//Upstreams upstreams config
+	Upstreams []*UpstreamConfig
+	//Balancer balancer name,"roundrobin","leastconn" or "weighted".
+	Balancer string
+	//StripPrefix prefix removed from request path.
+	StripPrefix string
+	//PreserveHost whether incoming host header is sent to upstream.
+	PreserveHost bool
+}
+
+//CreateTarget create target with config.
+//Return target and any error if raised.
+func (c *TargetConfig) CreateTarget() (*Target, error) {
+	var err error
+	t := NewTarget(nil)
+	if c.Pattern != nil {
+		t.Pattern, err = c.Pattern.CreatePattern()
+		if err != nil {
+			return nil, err
+		}
+	}
+	t.Balancer, err = NewBalancer(c.Balancer)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range c.Upstreams {
+		u, err := NewUpstream(v.URL)
+		if err != nil {
+			return nil, err
+		}
+		u.Weight = v.Weight
+		t.Upstreams = append(t.Upstreams, u)
+	}
+	t.StripPrefix = c.StripPrefix
+	t.PreserveHost = c.PreserveHost
+	return t, nil
+}
+
+//Config proxy config
+type Config struct {
+	//Targets targets config
+	Targets []*TargetConfig
+	//Retries max retries count for idempotent requests.
+	Retries int
+	//MaxFails consecutive failures count after which upstream will be ejected.
+	MaxFails int
+	//EjectDurationInSecond eject duration in second.
+	EjectDurationInSecond int64
+	//FailStatusCodes upstream status codes treated as failure.
+	//DefaultFailStatusCodes will be used if empty.
+	FailStatusCodes []int
+	//TrustForwarded whether incoming X-Forwarded-* headers are kept.
+	TrustForwarded bool
+	//ForwardedTokenHeader header name which token is sent in.
+	ForwardedTokenHeader string
+	//ForwardedTokenValue token value.
+	ForwardedTokenValue string
+	//FlushIntervalInMillisecond flush interval in millisecond.
+	//Negative value means flush immediately after each write.
+	FlushIntervalInMillisecond int64
+}
+
+//CreateProxy create proxy with config.
+//Return proxy and any error if raised.
+func (c *Config) CreateProxy() (*Proxy, error) {
+	p := New()
+	for _, v := range c.Targets {
+		t, err := v.CreateTarget()
+		if err != nil {
+			return nil, err
+		}
+		p.Targets = append(p.Targets, t)
+	}
+	p.Retries = c.Retries
+	p.MaxFails = c.MaxFails
+	p.EjectDuration = time.Duration(c.EjectDurationInSecond) * time.Second
+	if len(c.FailStatusCodes) > 0 {
+		p.FailStatusCodes = c.FailStatusCodes
+	}
+	p.TrustForwarded = c.TrustForwarded
+	p.ForwardedTokenHeader = c.ForwardedTokenHeader
+	p.ForwardedTokenValue = c.ForwardedTokenValue
+	p.FlushInterval = time.Duration(c.FlushIntervalInMillisecond) * time.Millisecond
+	return p, nil
+}
+
+//NewFactory create new proxy middleware factory.
+//Unmatched requests will be passed to next middleware.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		p, err := c.CreateProxy()
+		if err != nil {
+			return nil, err
+		}
+		return p.ServeMiddleware, nil
+	}
+}
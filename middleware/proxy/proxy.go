@@ -0,0 +1,402 @@
+//Package proxy provide reverse proxy middleware.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//ErrNoAvailableUpstream error raised when all upstreams of target are ejected.
+var ErrNoAvailableUpstream = errors.New("proxy:no available upstream")
+
+//DefaultFailStatusCodes default upstream status codes treated as failure.
+var DefaultFailStatusCodes = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+//DefaultErrorHandler default handler serving proxy errors.
+//Status 502 will be returned.
+var DefaultErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+}
+
+type contextKey string
+
+const contextKeyAttempt = contextKey("attempt")
+
+//Target proxy target which proxies matched requests to upstreams.
+type Target struct {
+	//Pattern request pattern.
+	//All requests will be matched if nil.
+	Pattern requestmatching.Pattern
+	//Upstreams target upstreams
+	Upstreams []*Upstream
+	//Balancer upstream selecting strategy
+	Balancer Balancer
+	//StripPrefix prefix removed from request path before proxying.
+	//Prefix is only removed at path segment boundary.
+	StripPrefix string
+	//PreserveHost whether incoming host header is sent to upstream.
+	PreserveHost bool
+}
+
+//Available return upstreams which are not ejected and not excluded.
+func (t *Target) Available(exclude map[*Upstream]bool) []*Upstream {
+	now := time.Now()
+	result := make([]*Upstream, 0, len(t.Upstreams))
+	for _, u := range t.Upstreams {
+		if !exclude[u] && u.Available(now) {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+func (t *Target) pick(exclude map[*Upstream]bool) *Upstream {
+	available := t.Available(exclude)
+	if len(available) == 0 {
+		return nil
+	}
+	return t.Balancer.Select(available)
+}
+
+//NewTarget create new round robin target with given pattern and upstreams.
+func NewTarget(p requestmatching.Pattern, upstreams ...*Upstream) *Target {
+	return &Target{
+		Pattern:   p,
+		Upstreams: upstreams,
+		Balancer:  &RoundRobin{},
+	}
+}
+
+type attempt struct {
+	target   *Target
+	upstream *Upstream
+	tried    map[*Upstream]bool
+	retries  int
+	in       *http.Request
+}
+
+//Proxy reverse proxy middleware struct
+type Proxy struct {
+	//Targets proxy targets.
+	//First matched target will be used.
+	Targets []*Target
+	//Transport transport used to send requests to upstream.
+	//http.DefaultTransport will be used if nil.
+	Transport http.RoundTripper
+	//Retries max retries count for idempotent requests
+	//when upstream fails.
+	Retries int
+	//MaxFails consecutive failures count after which upstream will be ejected.
+	//Passive health checking is disabled if not greater than 0.
+	MaxFails int
+	//EjectDuration duration for which ejected upstream will not be selected.
+	EjectDuration time.Duration
+	//FailStatusCodes upstream status codes treated as failure.
+	FailStatusCodes []int
+	//TrustForwarded whether incoming X-Forwarded-* headers are kept.
+	//Incoming headers are replaced if false,to prevent client ip spoofing.
+	TrustForwarded bool
+	//ForwardedTokenHeader header name which token is sent in,
+	//compatible with forwarded middleware token verification.
+	//Token will not be sent if empty.
+	ForwardedTokenHeader string
+	//ForwardedTokenValue token value.
+	ForwardedTokenValue string
+	//FlushInterval flush interval when copying response body.
+	FlushInterval time.Duration
+	//ErrorHandler handler serving proxy errors.
+	//DefaultErrorHandler will be used if nil.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	//OnEject func called when upstream is ejected.
+	OnEject func(t *Target, u *Upstream)
+}
+
+//Match return first target matching given request.
+//Return nil if no target matched.
+func (p *Proxy) Match(r *http.Request) *Target {
+	for _, t := range p.Targets {
+		if t.Pattern == nil || requestmatching.MustMatch(r, t.Pattern) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (p *Proxy) serveError(w http.ResponseWriter, r *http.Request, err error) {
+	if p.ErrorHandler != nil {
+		p.ErrorHandler(w, r, err)
+		return
+	}
+	DefaultErrorHandler(w, r, err)
+}
+
+func (p *Proxy) transport() http.RoundTripper {
+	if p.Transport != nil {
+		return p.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (p *Proxy) failStatus(statusCode int) bool {
+	for _, v := range p.FailStatusCodes {
+		if v == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+//stripPathPrefix remove prefix from escaped path.
+//Prefix is only removed at segment boundary,so prefix "/api" does not match "/apiv2".
+func stripPathPrefix(p string, prefix string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(p, prefix) {
+		return p
+	}
+	rest := p[len(prefix):]
+	if rest != "" && !strings.HasPrefix(rest, "/") {
+		return p
+	}
+	if rest == "" {
+		return "/"
+	}
+	return rest
+}
+
+//joinURLPath join target path and request path,keeping escaped characters.
+//Same as joinURLPath in net/http/httputil.
+func joinURLPath(a, b *url.URL) (path, rawpath string) {
+	if a.RawPath == "" && b.RawPath == "" {
+		return singleJoiningSlash(a.Path, b.Path), ""
+	}
+	apath := a.EscapedPath()
+	bpath := b.EscapedPath()
+	aslash := strings.HasSuffix(apath, "/")
+	bslash := strings.HasPrefix(bpath, "/")
+	switch {
+	case aslash && bslash:
+		return a.Path + b.Path[1:], apath + bpath[1:]
+	case !aslash && !bslash:
+		return a.Path + "/" + b.Path, apath + "/" + bpath
+	}
+	return a.Path + b.Path, apath + bpath
+}
+
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
+
+func rewriteURL(out *http.Request, in *http.Request, t *Target, u *Upstream) {
+	rawpath := in.URL.EscapedPath()
+	if t.StripPrefix != "" {
+		rawpath = stripPathPrefix(rawpath, (&url.URL{Path: t.StripPrefix}).EscapedPath())
+	}
+	if !strings.HasPrefix(rawpath, "/") {
+		rawpath = "/" + rawpath
+	}
+	requested := &url.URL{Path: rawpath}
+	if unescaped, err := url.PathUnescape(rawpath); err == nil {
+		requested.Path = unescaped
+		if requested.EscapedPath() != rawpath {
+			requested.RawPath = rawpath
+		}
+	}
+	target := u.URL
+	out.URL.Scheme = target.Scheme
+	out.URL.Host = target.Host
+	if target.Path == "" && target.RawPath == "" {
+		out.URL.Path = requested.Path
+		out.URL.RawPath = requested.RawPath
+	} else {
+		out.URL.Path, out.URL.RawPath = joinURLPath(target, requested)
+	}
+	if target.RawQuery == "" || in.URL.RawQuery == "" {
+		out.URL.RawQuery = target.RawQuery + in.URL.RawQuery
+	} else {
+		out.URL.RawQuery = target.RawQuery + "&" + in.URL.RawQuery
+	}
+}
+
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	a := pr.In.Context().Value(contextKeyAttempt).(*attempt)
+	rewriteURL(pr.Out, pr.In, a.target, a.upstream)
+	if a.target.PreserveHost {
+		pr.Out.Host = pr.In.Host
+	} else {
+		pr.Out.Host = ""
+	}
+	in := pr.In.Header
+	out := pr.Out.Header
+	if p.TrustForwarded && len(in["X-Forwarded-For"]) > 0 {
+		out["X-Forwarded-For"] = append([]string{}, in["X-Forwarded-For"]...)
+	}
+	pr.SetXForwarded()
+	if pr.In.URL.Scheme != "" {
+		out.Set("X-Forwarded-Proto", pr.In.URL.Scheme)
+	}
+	if p.TrustForwarded {
+		if v := in.Get("X-Forwarded-Host"); v != "" {
+			out.Set("X-Forwarded-Host", v)
+		}
+		if v := in.Get("X-Forwarded-Proto"); v != "" {
+			out.Set("X-Forwarded-Proto", v)
+		}
+	}
+	if p.ForwardedTokenHeader != "" {
+		out.Set(p.ForwardedTokenHeader, p.ForwardedTokenValue)
+	}
+}
+
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+//roundTrip send request to selected upstream.
+//Upstream health will be reported,and idempotent requests will be retried on other upstreams
+//if failed.
+func (p *Proxy) roundTrip(req *http.Request) (*http.Response, error) {
+	a := req.Context().Value(contextKeyAttempt).(*attempt)
+	for {
+		u := a.upstream
+		u.begin()
+		resp, err := p.transport().RoundTrip(req)
+		failed := err != nil || p.failStatus(resp.StatusCode)
+		if req.Context().Err() == nil {
+			if u.report(failed, p.MaxFails, p.EjectDuration) && p.OnEject != nil {
+				p.OnEject(a.target, u)
+			}
+		}
+		var next *Upstream
+		if failed && a.retries < p.Retries && retryable(req) && req.Context().Err() == nil {
+			next = a.target.pick(a.tried)
+		}
+		if next == nil {
+			if err != nil {
+				u.end()
+				return nil, err
+			}
+			resp.Body = wrapBody(resp.Body, u.end)
+			return resp, nil
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		u.end()
+		a.retries++
+		a.tried[next] = true
+		a.upstream = next
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			retry.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+		rewriteURL(retry, a.in, a.target, next)
+		req = retry
+	}
+}
+
+//ServeMiddleware serve as middleware.
+//Matched requests will be proxied to upstream,
+//others will be passed to next.
+func (p *Proxy) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	t := p.Match(r)
+	if t == nil {
+		next(w, r)
+		return
+	}
+	u := t.pick(nil)
+	if u == nil {
+		p.serveError(w, r, ErrNoAvailableUpstream)
+		return
+	}
+	a := &attempt{
+		target:   t,
+		upstream: u,
+		tried:    map[*Upstream]bool{u: true},
+		in:       r,
+	}
+	rp := &httputil.ReverseProxy{
+		Rewrite:       p.rewrite,
+		Transport:     roundTripperFunc(p.roundTrip),
+		FlushInterval: p.FlushInterval,
+		ErrorHandler:  p.serveError,
+	}
+	rp.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKeyAttempt, a)))
+}
+
+//ServeHTTP serve as http handler.
+//Status 404 will be returned if no target matched.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.ServeMiddleware(w, r, http.NotFound)
+}
+
+//New create new proxy with given targets.
+func New(targets ...*Target) *Proxy {
+	return &Proxy{
+		Targets:         targets,
+		FailStatusCodes: DefaultFailStatusCodes,
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type body struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+type upgradedBody struct {
+	*body
+	writer io.Writer
+}
+
+func (b *upgradedBody) Write(data []byte) (int, error) {
+	return b.writer.Write(data)
+}
+
+//wrapBody wrap response body to call done when closed.
+//Upgraded connection body keeps its io.ReadWriteCloser interface.
+func wrapBody(rc io.ReadCloser, done func()) io.ReadCloser {
+	b := &body{ReadCloser: rc, done: done}
+	if rwc, ok := rc.(io.ReadWriteCloser); ok {
+		return &upgradedBody{body: b, writer: rwc}
+	}
+	return b
+}
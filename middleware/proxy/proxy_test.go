@@ -0,0 +1,304 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/forwarded"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+func newUpstream(t *testing.T, s *httptest.Server) *Upstream {
+	u, err := NewUpstream(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func get(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func TestProxy(t *testing.T) {
+	fw := forwarded.New()
+	fw.Enabled = true
+	fw.ForwardedForHeader = "X-Forwarded-For"
+	fw.ForwardedHostHeader = "X-Forwarded-Host"
+	fw.ForwardedProtoHeader = "X-Forwarded-Proto"
+	fw.ForwardedTokenHeader = "X-Forwarded-Token"
+	fw.ForwardedTokenValue = "token"
+	backend := httptest.NewServer(middleware.New(fw.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s|%s|%s|%s", r.URL.Path, r.URL.RawQuery, r.RemoteAddr, r.Host, r.URL.Scheme)
+	}))
+	defer backend.Close()
+	u := newUpstream(t, backend)
+	u.URL.Path = "/base"
+	target := NewTarget(requestmatching.MustCreatePattern(&requestmatching.PatternConfig{PrefixList: []string{"/api/"}}), u)
+	target.StripPrefix = "/api"
+	p := New(target)
+	p.ForwardedTokenHeader = "X-Forwarded-Token"
+	p.ForwardedTokenValue = "token"
+	edge := httptest.NewServer(middleware.New(p.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("local"))
+	}))
+	defer edge.Close()
+	status, body := get(t, edge.URL+"/api/users?id=1")
+	host := strings.TrimPrefix(edge.URL, "http://")
+	if status != 200 || body != "/base/users|id=1|127.0.0.1:-1|"+host+"|http" {
+		t.Fatal(status, body)
+	}
+	status, body = get(t, edge.URL+"/other")
+	if status != 200 || body != "local" {
+		t.Fatal(status, body)
+	}
+	req, _ := http.NewRequest("GET", edge.URL+"/api/", nil)
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(data), "|127.0.0.1:-1|") {
+		t.Fatal(string(data))
+	}
+	p.TrustForwarded = true
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(data), "|1.2.3.4:-1|") {
+		t.Fatal(string(data))
+	}
+}
+
+func TestBalancers(t *testing.T) {
+	upstreams := []*Upstream{{Weight: 3}, {Weight: 1}}
+	counts := map[*Upstream]int{}
+	b := &Weighted{}
+	for i := 0; i < 8; i++ {
+		counts[b.Select(upstreams)]++
+	}
+	if counts[upstreams[0]] != 6 || counts[upstreams[1]] != 2 {
+		t.Fatal(counts)
+	}
+	counts = map[*Upstream]int{}
+	rr := &RoundRobin{}
+	for i := 0; i < 8; i++ {
+		counts[rr.Select(upstreams)]++
+	}
+	if counts[upstreams[0]] != 4 || counts[upstreams[1]] != 4 {
+		t.Fatal(counts)
+	}
+	upstreams[0].begin()
+	lc := &LeastConnections{}
+	for i := 0; i < 4; i++ {
+		if lc.Select(upstreams) != upstreams[1] {
+			t.Fatal(i)
+		}
+	}
+	upstreams[0].end()
+	_, err := NewBalancer("unknown")
+	if err != ErrUnknownBalancer {
+		t.Fatal(err)
+	}
+}
+
+func TestRetryAndEject(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	var hits int
+	var locker sync.Mutex
+	alive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locker.Lock()
+		hits++
+		locker.Unlock()
+		w.Write([]byte("alive"))
+	}))
+	defer alive.Close()
+	deadUpstream := newUpstream(t, dead)
+	aliveUpstream := newUpstream(t, alive)
+	p := New(NewTarget(nil, deadUpstream, aliveUpstream))
+	p.Retries = 1
+	p.MaxFails = 1
+	p.EjectDuration = time.Minute
+	var ejected *Upstream
+	p.OnEject = func(t *Target, u *Upstream) {
+		ejected = u
+	}
+	edge := httptest.NewServer(p)
+	defer edge.Close()
+	status, body := get(t, edge.URL)
+	if status != 200 || body != "alive" || ejected != deadUpstream {
+		t.Fatal(status, body, ejected)
+	}
+	if deadUpstream.Available(time.Now()) || !aliveUpstream.Available(time.Now()) {
+		t.Fatal()
+	}
+	for i := 0; i < 3; i++ {
+		status, body = get(t, edge.URL)
+		if status != 200 || body != "alive" {
+			t.Fatal(status, body)
+		}
+	}
+	if hits != 4 || aliveUpstream.Active() != 0 {
+		t.Fatal(hits, aliveUpstream.Active())
+	}
+	p = New(NewTarget(nil, newUpstream(t, dead)))
+	p.Retries = 3
+	edge2 := httptest.NewServer(p)
+	defer edge2.Close()
+	resp, err := http.Post(edge2.URL, "text/plain", strings.NewReader("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 502 {
+		t.Fatal(resp.StatusCode)
+	}
+	p.MaxFails = 1
+	p.EjectDuration = time.Minute
+	get(t, edge2.URL)
+	status, _ = get(t, edge2.URL)
+	if status != 502 {
+		t.Fatal(status)
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "bad request", 400)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		rw.WriteString("echo:" + line)
+		rw.Flush()
+	}))
+	defer backend.Close()
+	u := newUpstream(t, backend)
+	edge := httptest.NewServer(middleware.New().HandleFunc(New(NewTarget(nil, u)).ServeHTTP))
+	defer edge.Close()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(edge.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 101 {
+		t.Fatal(resp.StatusCode)
+	}
+	if u.Active() != 1 {
+		t.Fatal(u.Active())
+	}
+	conn.Write([]byte("hello\n"))
+	line, err := reader.ReadString('\n')
+	if err != nil || line != "echo:hello\n" {
+		t.Fatal(line, err)
+	}
+}
+
+func TestFactory(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer backend.Close()
+	data, err := json.Marshal(&Config{
+		Targets: []*TargetConfig{
+			{
+				Pattern:     &requestmatching.PatternConfig{PrefixList: []string{"/static/"}},
+				Upstreams:   []*UpstreamConfig{{URL: backend.URL, Weight: 2}},
+				Balancer:    "weighted",
+				StripPrefix: "/static",
+			},
+		},
+		Retries: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edge := httptest.NewServer(middleware.New(m).HandleFunc(http.NotFound))
+	defer edge.Close()
+	status, body := get(t, edge.URL+"/static/a.js")
+	if status != 200 || body != "/a.js" {
+		t.Fatal(status, body)
+	}
+	status, _ = get(t, edge.URL+"/other")
+	if status != 404 {
+		t.Fatal(status)
+	}
+	_, err = (&Config{Targets: []*TargetConfig{{Balancer: "unknown"}}}).CreateProxy()
+	if err != ErrUnknownBalancer {
+		t.Fatal(err)
+	}
+}
+
+func TestRewriteURL(t *testing.T) {
+	for _, v := range []struct {
+		target   string
+		prefix   string
+		path     string
+		expected string
+	}{
+		{"http://backend", "/files", "/files/a%2Fb", "http://backend/a%2Fb"},
+		{"http://backend/base", "/files", "/files/a%2Fb?c=d", "http://backend/base/a%2Fb?c=d"},
+		{"http://backend/base%2Fx/", "", "/a%20b", "http://backend/base%2Fx/a%20b"},
+		{"http://backend", "/api", "/apiv2/x", "http://backend/apiv2/x"},
+		{"http://backend", "/api", "/api", "http://backend/"},
+		{"http://backend", "/api/", "/api/x", "http://backend/x"},
+		{"http://backend/base", "/api", "/api/x", "http://backend/base/x"},
+	} {
+		u, err := NewUpstream(v.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := NewTarget(nil, u)
+		target.StripPrefix = v.prefix
+		in := httptest.NewRequest("GET", v.path, nil)
+		out := in.Clone(in.Context())
+		rewriteURL(out, in, target, u)
+		if out.URL.String() != v.expected {
+			t.Fatal(v.path, out.URL.String())
+		}
+	}
+}
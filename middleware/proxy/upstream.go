@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//Upstream proxy upstream server
+type Upstream struct {
+	//URL upstream base url
+	URL *url.URL
+	//Weight upstream weight used by weighted balancer.
+	//Treated as 1 if not greater than 0.
+	Weight   int
+	active   int64
+	locker   sync.Mutex
+	fails    int
+	ejected  time.Time
+	requests int64
+}
+
+//Active return active requests count of upstream.
+func (u *Upstream) Active() int64 {
+	return atomic.LoadInt64(&u.active)
+}
+
+//Requests return total requests count sent to upstream.
+func (u *Upstream) Requests() int64 {
+	return atomic.LoadInt64(&u.requests)
+}
+
+//Available check if upstream is not ejected at given time.
+func (u *Upstream) Available(now time.Time) bool {
+	u.locker.Lock()
+	defer u.locker.Unlock()
+	return !now.Before(u.ejected)
+}
+
+func (u *Upstream) weight() int {
+	if u.Weight <= 0 {
+		return 1
+	}
+	return u.Weight
+}
+
+func (u *Upstream) begin() {
+	atomic.AddInt64(&u.active, 1)
+	atomic.AddInt64(&u.requests, 1)
+}
+
+func (u *Upstream) end() {
+	atomic.AddInt64(&u.active, -1)
+}
+
+//report report request result.
+//Upstream will be ejected for given duration after maxFails consecutive failures.
+//Return true if upstream is ejected by this report.
+func (u *Upstream) report(failed bool, maxFails int, eject time.Duration) bool {
+	u.locker.Lock()
+	defer u.locker.Unlock()
+	if !failed {
+		u.fails = 0
+		return false
+	}
+	u.fails++
+	if maxFails > 0 && u.fails >= maxFails {
+		u.fails = 0
+		u.ejected = time.Now().Add(eject)
+		return true
+	}
+	return false
+}
+
+//NewUpstream create new upstream with given url.
+//Return upstream and any error if raised.
+func NewUpstream(rawurl string) (*Upstream, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return &Upstream{
+		URL:    u,
+		Weight: 1,
+	}, nil
+}
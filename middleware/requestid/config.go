@@ -0,0 +1,63 @@
+package requestid
+
+import (
+	"errors"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+//ErrUnknownGenerator error raised when generator name is unknown.
+var ErrUnknownGenerator = errors.New("requestid:unknown generator")
+
+//Generators registered generators by name.
+var Generators = map[string]Generator{
+	"uuid": GenerateUUID,
+	"ulid": GenerateULID,
+}
+
+//Config request id middleware config
+type Config struct {
+	//Header header name.
+	//DefaultHeader will be used if empty.
+	Header string
+	//Generator generator name in Generators.
+	//"uuid" will be used if empty.
+	Generator string
+	//TrustIncoming whether valid incoming request id is used.
+	TrustIncoming bool
+}
+
+//CreateRequestID create request id middleware with config.
+//Return middleware and any error if raised.
+func (c *Config) CreateRequestID() (*RequestID, error) {
+	i := New()
+	if c.Header != "" {
+		i.Header = c.Header
+	}
+	if c.Generator != "" {
+		g := Generators[c.Generator]
+		if g == nil {
+			return nil, ErrUnknownGenerator
+		}
+		i.Generator = g
+	}
+	i.TrustIncoming = c.TrustIncoming
+	return i, nil
+}
+
+//NewFactory create new request id middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		i, err := c.CreateRequestID()
+		if err != nil {
+			return nil, err
+		}
+		return i.ServeMiddleware, nil
+	}
+}
@@ -0,0 +1,162 @@
+//Package requestid provide request id middleware.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/herb-go/herb/identifier"
+	"github.com/herb-go/herb/middleware/httpinfo"
+)
+
+//DefaultHeader default request id header name
+const DefaultHeader = "X-Request-Id"
+
+//MaxIDLength max length of trusted incoming request id.
+var MaxIDLength = 128
+
+//ContextName context name type
+type ContextName string
+
+//ContextNameRequestID request id context name
+const ContextNameRequestID = ContextName("requestID")
+
+//Generator request id generator.
+//Return new id and any error if raised.
+type Generator func() (string, error)
+
+//GenerateUUID generate random UUID version 4.
+func GenerateUUID() (string, error) {
+	data := make([]byte, 16)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	data[6] = (data[6] & 0x0f) | 0x40
+	data[8] = (data[8] & 0x3f) | 0x80
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], data[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], data[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], data[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], data[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], data[10:])
+	return string(buf), nil
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+//GenerateULID generate ULID with current time and random bits.
+//ULIDs are lexicographically sortable by creation time in millisecond.
+func GenerateULID() (string, error) {
+	data := make([]byte, 16)
+	binary.BigEndian.PutUint64(data[0:8], uint64(time.Now().UnixNano()/int64(time.Millisecond))<<16)
+	_, err := rand.Read(data[6:])
+	if err != nil {
+		return "", err
+	}
+	hi := binary.BigEndian.Uint64(data[0:8])
+	lo := binary.BigEndian.Uint64(data[8:16])
+	buf := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi = hi >> 5
+	}
+	return string(buf), nil
+}
+
+//RequestID request id middleware struct
+type RequestID struct {
+	//Header request and response header name.
+	Header string
+	//Generator id generator.
+	Generator Generator
+	//TrustIncoming whether valid request id from request header is used instead of generating new one.
+	TrustIncoming bool
+}
+
+//ServeMiddleware serve as middleware.
+//Request id will be stored in context,set to request header and echoed in response header.
+func (i *RequestID) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	var id string
+	if i.TrustIncoming {
+		id = r.Header.Get(i.Header)
+		if !ValidateID(id) {
+			id = ""
+		}
+	}
+	if id == "" {
+		var err error
+		id, err = i.Generator()
+		if err != nil {
+			panic(err)
+		}
+	}
+	r.Header.Set(i.Header, id)
+	w.Header().Set(i.Header, id)
+	next(w, r.WithContext(WithID(r.Context(), id)))
+}
+
+//New create new request id middleware which generates UUIDs with DefaultHeader.
+func New() *RequestID {
+	return &RequestID{
+		Header:    DefaultHeader,
+		Generator: GenerateUUID,
+	}
+}
+
+//ValidateID check if given incoming id is not empty,not too long and only contains safe characters.
+func ValidateID(id string) bool {
+	if id == "" || len(id) > MaxIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':' || c == '+' || c == '/' || c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+//WithID return copy of given context with request id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextNameRequestID, id)
+}
+
+//FromContext return request id stored in given context.
+//Return empty string if not found.
+func FromContext(ctx context.Context) string {
+	v, _ := ctx.Value(ContextNameRequestID).(string)
+	return v
+}
+
+//Get return request id of given request.
+//Return empty string if not found.
+func Get(r *http.Request) string {
+	return FromContext(r.Context())
+}
+
+//Identifier identifier which identify request by request id.
+var Identifier = identifier.IDFunc(func(r *http.Request) (string, error) {
+	return Get(r), nil
+})
+
+//Field request info field which loads request id.
+var Field = httpinfo.FieldFunc(func(r *http.Request) ([]byte, bool, error) {
+	id := Get(r)
+	if id == "" {
+		return nil, false, nil
+	}
+	return []byte(id), true, nil
+})
@@ -0,0 +1,110 @@
+package requestid
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+var ulidRegexp = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+
+func TestGenerators(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		id, err := GenerateUUID()
+		if err != nil || !uuidRegexp.MatchString(id) {
+			t.Fatal(id, err)
+		}
+	}
+	ids := []string{}
+	for i := 0; i < 3; i++ {
+		id, err := GenerateULID()
+		if err != nil || !ulidRegexp.MatchString(id) {
+			t.Fatal(id, err)
+		}
+		ids = append(ids, id)
+		time.Sleep(2 * time.Millisecond)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Fatal(ids)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var id, identified, field, upstream string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream = r.Header.Get(DefaultHeader)
+	}))
+	defer downstream.Close()
+	client := &http.Client{Transport: NewTransport(nil)}
+	i := New()
+	app := middleware.New(i.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = Get(r)
+		identified, _ = Identifier.IdentifyRequest(r)
+		data, ok, _ := Field.LoadInfo(r)
+		if ok {
+			field = string(data)
+		}
+		req, _ := http.NewRequestWithContext(r.Context(), "GET", downstream.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(DefaultHeader, "incoming")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if !uuidRegexp.MatchString(id) || identified != id || field != id || upstream != id || rec.Header().Get(DefaultHeader) != id {
+		t.Fatal(id, identified, field, upstream, rec.Header())
+	}
+	i.TrustIncoming = true
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(DefaultHeader, "incoming")
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if id != "incoming" || upstream != id || rec.Header().Get(DefaultHeader) != id {
+		t.Fatal(id, upstream, rec.Header())
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(DefaultHeader, "bad id\n")
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if !uuidRegexp.MatchString(id) {
+		t.Fatal(id)
+	}
+	_, ok, _ := Field.LoadInfo(httptest.NewRequest("GET", "/", nil))
+	if ok {
+		t.Fatal(ok)
+	}
+}
+
+func TestFactory(t *testing.T) {
+	data, err := json.Marshal(&Config{Header: "X-Trace", Generator: "ulid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if !ulidRegexp.MatchString(rec.Header().Get("X-Trace")) {
+		t.Fatal(rec.Header())
+	}
+	_, err = (&Config{Generator: "unknown"}).CreateRequestID()
+	if err != ErrUnknownGenerator {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,37 @@
+package requestid
+
+import (
+	"net/http"
+)
+
+//Transport round tripper which propagates request id in request context to downstream calls.
+type Transport struct {
+	//Base base round tripper.
+	//http.DefaultTransport will be used if nil.
+	Base http.RoundTripper
+	//Header request header name.
+	Header string
+}
+
+//RoundTrip execute a single HTTP transaction.
+//Request id header will be set if request context has request id and header is not set.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	id := FromContext(req.Context())
+	if id != "" && req.Header.Get(t.Header) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(t.Header, id)
+	}
+	return base.RoundTrip(req)
+}
+
+//NewTransport create new transport with given base round tripper and DefaultHeader.
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{
+		Base:   base,
+		Header: DefaultHeader,
+	}
+}
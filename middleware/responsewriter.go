@@ -17,6 +17,7 @@ type WriterFunctions struct {
 type WrappedWriter struct {
 	functions     *WriterFunctions
 	headerWritten bool
+	writer        http.ResponseWriter
 }
 
 func (w *WrappedWriter) Functions() *WriterFunctions {
@@ -44,6 +45,12 @@ func (w *WrappedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return w.functions.HijackFunc()
 }
 
+//Unwrap return wrapped response writer,
+//so http.ResponseController can reach underlying connection.
+func (w *WrappedWriter) Unwrap() http.ResponseWriter {
+	return w.writer
+}
+
 type ResponseWriter interface {
 	Functions() *WriterFunctions
 	Header() http.Header
@@ -59,22 +66,32 @@ type Hijacker interface {
 	Hijack() (net.Conn, *bufio.ReadWriter, error)
 }
 
+//Unwrapper interface which returns wrapped response writer.
+//Used by http.ResponseController.
+type Unwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
 type WrappedResponseWriter struct {
 	ResponseWriter
+	Unwrapper
 }
 
 type WrappedResponseWriterHijacker struct {
 	ResponseWriter
 	Hijacker
+	Unwrapper
 }
 type WrappedResponseWriterFlusher struct {
 	ResponseWriter
 	Flusher
+	Unwrapper
 }
 type WrappedResponseWriterFlusherHijacker struct {
 	ResponseWriter
 	Flusher
 	Hijacker
+	Unwrapper
 }
 
 func WrapResponseWriter(rw http.ResponseWriter) ResponseWriter {
@@ -86,6 +103,7 @@ func WrapResponseWriter(rw http.ResponseWriter) ResponseWriter {
 			HeaderFunc:      rw.Header,
 			WriteHeaderFunc: rw.WriteHeader,
 		},
+		writer: rw,
 	}
 	if f, ok := rw.(Flusher); ok {
 		isFlusher = true
@@ -100,22 +118,26 @@ func WrapResponseWriter(rw http.ResponseWriter) ResponseWriter {
 			ResponseWriter: w,
 			Flusher:        w,
 			Hijacker:       w,
+			Unwrapper:      w,
 		}
 	}
 	if isFlusher {
 		return &WrappedResponseWriterFlusher{
 			ResponseWriter: w,
 			Flusher:        w,
+			Unwrapper:      w,
 		}
 	}
 	if isHijacker {
 		return &WrappedResponseWriterHijacker{
 			ResponseWriter: w,
 			Hijacker:       w,
+			Unwrapper:      w,
 		}
 	}
 	return &WrappedResponseWriter{
 		ResponseWriter: w,
+		Unwrapper:      w,
 	}
 }
 
@@ -47,3 +47,17 @@ func TestResponsewriter(t *testing.T) {
 		t.Fatal(rw)
 	}
 }
+
+func TestResponsewriterUnwrap(t *testing.T) {
+	rw := &responsewriter{}
+	w := WrapResponseWriter(rw)
+	u, ok := w.(Unwrapper)
+	if !ok || u.Unwrap() != rw {
+		t.Fatal(w)
+	}
+	w = WrapResponseWriter(w)
+	u, ok = w.(Unwrapper)
+	if !ok || u.Unwrap().(Unwrapper).Unwrap() != rw {
+		t.Fatal(w)
+	}
+}
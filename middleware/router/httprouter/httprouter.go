@@ -24,8 +24,9 @@ func New() *Router {
 	}
 	return &router
 }
-func wrap(f http.Handler) httprouter.Handle {
+func wrap(f http.Handler, path string) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		router.SetRouteName(r, path)
 		SetParams(r, params)
 		f.ServeHTTP(w, r)
 	}
@@ -34,7 +35,7 @@ func wrap(f http.Handler) httprouter.Handle {
 //Handle return app which will response to given method and path.
 func (r *Router) Handle(method, path string) *middleware.App {
 	app := middleware.New()
-	r.router.Handle(method, path, wrap(app))
+	r.router.Handle(method, path, wrap(app, path))
 	return app
 }
 
@@ -100,7 +101,7 @@ func (r *Router) SetNotFoundHandler(h http.Handler) {
 //ALL return app which will response to all method and path.
 func (r *Router) ALL(path string) *middleware.App {
 	app := middleware.New()
-	handler := wrap(app)
+	handler := wrap(app, path)
 	r.router.GET(path, handler)
 	r.router.POST(path, handler)
 	r.router.PUT(path, handler)
@@ -114,8 +115,8 @@ func (r *Router) ALL(path string) *middleware.App {
 //StripPrefix strip request prefix and server as a middleware app
 func (r *Router) StripPrefix(path string) *middleware.App {
 	app := middleware.New(stripPrefixfunc)
-	handler := wrap(app)
 	p := path + "/*filepath"
+	handler := wrap(app, p)
 	r.router.GET(p, handler)
 	r.router.POST(p, handler)
 	r.router.PUT(p, handler)
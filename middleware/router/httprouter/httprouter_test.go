@@ -161,3 +161,27 @@ func TestRouterParam(t *testing.T) {
 		t.Error(string(content))
 	}
 }
+
+func TestRouteName(t *testing.T) {
+	r := New()
+	var name = func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(router.GetRoute(req).Name))
+	}
+	r.GET("/users/:id").HandleFunc(name)
+	r.ALL("/all").HandleFunc(name)
+	r.StripPrefix("/api").HandleFunc(name)
+	r.GET("/named").Use(router.NewRouteNameMiddleware("named")).HandleFunc(name)
+	var tests = map[string]string{
+		"/users/12":  "/users/:id",
+		"/all":       "/all",
+		"/api/users": "/api/*filepath",
+		"/named":     "named",
+	}
+	for path, expected := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Body.String() != expected {
+			t.Fatal(path, rec.Body.String())
+		}
+	}
+}
@@ -18,12 +18,14 @@ type Router struct {
 func (muxrouter *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path == "/" {
 		if muxrouter.homepage != nil {
+			router.SetRouteName(r, "/")
 			muxrouter.homepage.ServeHTTP(w, r)
 			return
 		}
 	} else {
 		h, p := muxrouter.mux.Handler(r)
 		if p != "" {
+			router.SetRouteName(r, p)
 			h.ServeHTTP(w, r)
 			return
 		}
@@ -112,3 +112,27 @@ func TestMuxRouter(t *testing.T) {
 	}
 
 }
+
+func TestRouteName(t *testing.T) {
+	r := New()
+	var name = func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(router.GetRoute(req).Name))
+	}
+	r.Handle("/users/").HandleFunc(name)
+	r.StripPrefix("/api").HandleFunc(name)
+	r.HandleHomepage().HandleFunc(name)
+	r.Handle("/named/").Use(router.NewRouteNameMiddleware("named")).HandleFunc(name)
+	var tests = map[string]string{
+		"/users/12":  "/users/",
+		"/api/users": "/api/",
+		"/":          "/",
+		"/named/1":   "named",
+	}
+	for path, expected := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Body.String() != expected {
+			t.Fatal(path, rec.Body.String())
+		}
+	}
+}
@@ -15,6 +15,9 @@ type ContextName string
 //ContextNameRouterParams router params context name
 const ContextNameRouterParams = ContextName("routerParams")
 
+//ContextNameRoute route context name
+const ContextNameRoute = ContextName("route")
+
 //Router router interface
 type Router interface {
 	//ServeHTTP serve as http handler
@@ -78,6 +81,45 @@ func GetParams(r *http.Request) *Params {
 	return params
 }
 
+//Route matched route info.
+type Route struct {
+	//Name route name.
+	//Routers set name to matched route pattern,such as "/users/:id".
+	//Name set by NewRouteNameMiddleware inside route overrides it.
+	Name string
+}
+
+//GetRoute get route info from http request.
+//If route info does not exist,new route info will be create and add to request.
+//Call GetRoute before serving next to read route info set by inner middlewares.
+func GetRoute(r *http.Request) *Route {
+	var route *Route
+	v := r.Context().Value(ContextNameRoute)
+	if v != nil {
+		route = v.(*Route)
+	}
+	if route == nil {
+		route = &Route{}
+		ctx := context.WithValue(r.Context(), ContextNameRoute, route)
+		*r = *r.WithContext(ctx)
+	}
+	return route
+}
+
+//SetRouteName set route name of given request.
+//Routers should call it with matched route pattern before serving route.
+func SetRouteName(r *http.Request, name string) {
+	GetRoute(r).Name = name
+}
+
+//NewRouteNameMiddleware create middleware which set route name to given name.
+func NewRouteNameMiddleware(name string) func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		SetRouteName(r, name)
+		next(w, r)
+	}
+}
+
 func NewStripPrefixMiddleware(prefix string) func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 		r2 := new(http.Request)
@@ -88,3 +88,18 @@ func TestNilParams(t *testing.T) {
 		t.Error(p.Get("test"))
 	}
 }
+
+func TestRoute(t *testing.T) {
+	var route *Route
+	app := middleware.New(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		route = GetRoute(r)
+		next(w, r)
+	}, NewStripPrefixMiddleware("/prefix"), NewRouteNameMiddleware("test")).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(GetRoute(r).Name))
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/prefix/path", nil))
+	if rec.Body.String() != "test" || route.Name != "test" {
+		t.Fatal(rec.Body.String(), route)
+	}
+}
@@ -0,0 +1,91 @@
+package secureheaders
+
+import (
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+//FeatureConfig permissions policy feature config
+type FeatureConfig struct {
+	//Feature feature name
+	Feature string
+	//Allowlist feature allowlist.
+	//Feature is disabled if empty.
+	Allowlist []string
+}
+
+//Config security headers middleware config
+type Config struct {
+	//HSTSMaxAgeInSecond strict transport security max age in second.
+	//Header will not be sent if not greater than 0.
+	HSTSMaxAgeInSecond int64
+	//HSTSIncludeSubDomains whether includeSubDomains directive is sent.
+	HSTSIncludeSubDomains bool
+	//HSTSPreload whether preload directive is sent.
+	HSTSPreload bool
+	//NoSniff whether X-Content-Type-Options:nosniff is sent.
+	NoSniff bool
+	//FrameOptions X-Frame-Options value.
+	FrameOptions string
+	//ReferrerPolicy Referrer-Policy value.
+	ReferrerPolicy string
+	//PermissionsPolicy permissions policy features
+	PermissionsPolicy []*FeatureConfig
+	//CSP enforced content security policy directives
+	CSP []*Directive
+	//CSPReportOnly report only content security policy directives
+	CSPReportOnly []*Directive
+}
+
+func createPolicy(directives []*Directive) *Policy {
+	if len(directives) == 0 {
+		return nil
+	}
+	p := NewPolicy()
+	for _, v := range directives {
+		p.Add(v.Name, v.Sources...)
+		if v.Nonce {
+			p.WithNonce(v.Name)
+		}
+	}
+	return p
+}
+
+//CreateHeaders create security headers middleware with config.
+func (c *Config) CreateHeaders() *Headers {
+	h := &Headers{
+		NoSniff:        c.NoSniff,
+		FrameOptions:   c.FrameOptions,
+		ReferrerPolicy: c.ReferrerPolicy,
+		CSP:            createPolicy(c.CSP),
+		CSPReportOnly:  createPolicy(c.CSPReportOnly),
+	}
+	if c.HSTSMaxAgeInSecond > 0 {
+		h.HSTS = &HSTS{
+			MaxAge:            time.Duration(c.HSTSMaxAgeInSecond) * time.Second,
+			IncludeSubDomains: c.HSTSIncludeSubDomains,
+			Preload:           c.HSTSPreload,
+		}
+	}
+	if len(c.PermissionsPolicy) > 0 {
+		h.PermissionsPolicy = NewPermissionsPolicy()
+		for _, v := range c.PermissionsPolicy {
+			h.PermissionsPolicy.Allow(v.Feature, v.Allowlist...)
+		}
+	}
+	return h
+}
+
+//NewFactory create new security headers middleware factory.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.CreateHeaders().ServeMiddleware, nil
+	}
+}
@@ -0,0 +1,224 @@
+package secureheaders
+
+import (
+	"strings"
+)
+
+//Common content security policy source expressions.
+const (
+	SourceSelf          = "'self'"
+	SourceNone          = "'none'"
+	SourceUnsafeInline  = "'unsafe-inline'"
+	SourceUnsafeEval    = "'unsafe-eval'"
+	SourceStrictDynamic = "'strict-dynamic'"
+	SourceData          = "data:"
+	SourceBlob          = "blob:"
+	SourceHTTPS         = "https:"
+)
+
+//Common content security policy directive names.
+const (
+	DirectiveDefaultSrc              = "default-src"
+	DirectiveScriptSrc               = "script-src"
+	DirectiveStyleSrc                = "style-src"
+	DirectiveImgSrc                  = "img-src"
+	DirectiveConnectSrc              = "connect-src"
+	DirectiveFontSrc                 = "font-src"
+	DirectiveObjectSrc               = "object-src"
+	DirectiveMediaSrc                = "media-src"
+	DirectiveFrameSrc                = "frame-src"
+	DirectiveFrameAncestors          = "frame-ancestors"
+	DirectiveBaseURI                 = "base-uri"
+	DirectiveFormAction              = "form-action"
+	DirectiveReportURI               = "report-uri"
+	DirectiveReportTo                = "report-to"
+	DirectiveUpgradeInsecureRequests = "upgrade-insecure-requests"
+)
+
+//Directive content security policy directive
+type Directive struct {
+	//Name directive name
+	Name string
+	//Sources directive values
+	Sources []string
+	//Nonce whether request nonce should be added to directive
+	Nonce bool
+}
+
+//Policy content security policy builder.
+//Directives are rendered in adding order.
+type Policy struct {
+	//Directives policy directives
+	Directives []*Directive
+}
+
+//Directive return directive by given name.
+//Directive will be created if not exists.
+func (p *Policy) Directive(name string) *Directive {
+	name = strings.ToLower(name)
+	for _, d := range p.Directives {
+		if d.Name == name {
+			return d
+		}
+	}
+	d := &Directive{Name: name}
+	p.Directives = append(p.Directives, d)
+	return d
+}
+
+//Add add sources to directive with given name.
+//Return policy self.
+func (p *Policy) Add(name string, sources ...string) *Policy {
+	d := p.Directive(name)
+	d.Sources = append(d.Sources, sources...)
+	return p
+}
+
+//DefaultSrc add sources to default-src directive.
+func (p *Policy) DefaultSrc(sources ...string) *Policy {
+	return p.Add(DirectiveDefaultSrc, sources...)
+}
+
+//ScriptSrc add sources to script-src directive.
+func (p *Policy) ScriptSrc(sources ...string) *Policy {
+	return p.Add(DirectiveScriptSrc, sources...)
+}
+
+//StyleSrc add sources to style-src directive.
+func (p *Policy) StyleSrc(sources ...string) *Policy {
+	return p.Add(DirectiveStyleSrc, sources...)
+}
+
+//ImgSrc add sources to img-src directive.
+func (p *Policy) ImgSrc(sources ...string) *Policy {
+	return p.Add(DirectiveImgSrc, sources...)
+}
+
+//ConnectSrc add sources to connect-src directive.
+func (p *Policy) ConnectSrc(sources ...string) *Policy {
+	return p.Add(DirectiveConnectSrc, sources...)
+}
+
+//FontSrc add sources to font-src directive.
+func (p *Policy) FontSrc(sources ...string) *Policy {
+	return p.Add(DirectiveFontSrc, sources...)
+}
+
+//ObjectSrc add sources to object-src directive.
+func (p *Policy) ObjectSrc(sources ...string) *Policy {
+	return p.Add(DirectiveObjectSrc, sources...)
+}
+
+//FrameAncestors add sources to frame-ancestors directive.
+func (p *Policy) FrameAncestors(sources ...string) *Policy {
+	return p.Add(DirectiveFrameAncestors, sources...)
+}
+
+//BaseURI add sources to base-uri directive.
+func (p *Policy) BaseURI(sources ...string) *Policy {
+	return p.Add(DirectiveBaseURI, sources...)
+}
+
+//FormAction add sources to form-action directive.
+func (p *Policy) FormAction(sources ...string) *Policy {
+	return p.Add(DirectiveFormAction, sources...)
+}
+
+//ReportURI add report-uri directive.
+func (p *Policy) ReportURI(uri string) *Policy {
+	return p.Add(DirectiveReportURI, uri)
+}
+
+//UpgradeInsecureRequests add upgrade-insecure-requests directive.
+func (p *Policy) UpgradeInsecureRequests() *Policy {
+	p.Directive(DirectiveUpgradeInsecureRequests)
+	return p
+}
+
+//WithNonce add request nonce to directives with given names.
+//Script-src and style-src will be used if no name given.
+func (p *Policy) WithNonce(names ...string) *Policy {
+	if len(names) == 0 {
+		names = []string{DirectiveScriptSrc, DirectiveStyleSrc}
+	}
+	for _, name := range names {
+		p.Directive(name).Nonce = true
+	}
+	return p
+}
+
+//NeedNonce check if any directive uses request nonce.
+func (p *Policy) NeedNonce() bool {
+	for _, d := range p.Directives {
+		if d.Nonce {
+			return true
+		}
+	}
+	return false
+}
+
+//Build build policy header value with given nonce.
+func (p *Policy) Build(nonce string) string {
+	result := make([]string, 0, len(p.Directives))
+	for _, d := range p.Directives {
+		values := append([]string{d.Name}, d.Sources...)
+		if d.Nonce && nonce != "" {
+			values = append(values, "'nonce-"+nonce+"'")
+		}
+		result = append(result, strings.Join(values, " "))
+	}
+	return strings.Join(result, "; ")
+}
+
+//NewPolicy create new content security policy.
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+//PermissionsPolicy permissions policy builder.
+//Features are rendered in adding order.
+type PermissionsPolicy struct {
+	//Features feature names
+	Features []string
+	//Allowlists allowlist of features in same order
+	Allowlists [][]string
+}
+
+//Allow set allowlist of feature.
+//Feature is disabled if allowlist is empty.
+//Allowlist items are rendered as is,so origins should be quoted,for example self or "https://example.com".
+//Return policy self.
+func (p *PermissionsPolicy) Allow(feature string, allowlist ...string) *PermissionsPolicy {
+	for k, v := range p.Features {
+		if v == feature {
+			p.Allowlists[k] = allowlist
+			return p
+		}
+	}
+	p.Features = append(p.Features, feature)
+	p.Allowlists = append(p.Allowlists, allowlist)
+	return p
+}
+
+//Disable disable given features.
+//Return policy self.
+func (p *PermissionsPolicy) Disable(features ...string) *PermissionsPolicy {
+	for _, feature := range features {
+		p.Allow(feature)
+	}
+	return p
+}
+
+//String return permissions policy header value.
+func (p *PermissionsPolicy) String() string {
+	result := make([]string, len(p.Features))
+	for k, v := range p.Features {
+		result[k] = v + "=(" + strings.Join(p.Allowlists[k], " ") + ")"
+	}
+	return strings.Join(result, ", ")
+}
+
+//NewPermissionsPolicy create new permissions policy.
+func NewPermissionsPolicy() *PermissionsPolicy {
+	return &PermissionsPolicy{}
+}
@@ -0,0 +1,156 @@
+//Package secureheaders provide security headers middleware.
+package secureheaders
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ContextName context name type
+type ContextName string
+
+//ContextNameNonce content security policy nonce context name
+const ContextNameNonce = ContextName("cspNonce")
+
+//Frame options values.
+const (
+	FrameOptionsDeny       = "DENY"
+	FrameOptionsSameOrigin = "SAMEORIGIN"
+)
+
+//NonceSize nonce size in bytes before encoding.
+var NonceSize = 16
+
+//HSTS strict transport security settings
+type HSTS struct {
+	//MaxAge max age.
+	MaxAge time.Duration
+	//IncludeSubDomains whether includeSubDomains directive is sent.
+	IncludeSubDomains bool
+	//Preload whether preload directive is sent.
+	Preload bool
+}
+
+//String return header value.
+func (h *HSTS) String() string {
+	value := "max-age=" + strconv.FormatInt(int64(h.MaxAge/time.Second), 10)
+	if h.IncludeSubDomains {
+		value = value + "; includeSubDomains"
+	}
+	if h.Preload {
+		value = value + "; preload"
+	}
+	return value
+}
+
+//Headers security headers middleware struct
+type Headers struct {
+	//HSTS strict transport security settings.
+	//Header is only sent to https requests.
+	//Header will not be sent if nil.
+	HSTS *HSTS
+	//NoSniff whether X-Content-Type-Options:nosniff is sent.
+	NoSniff bool
+	//FrameOptions X-Frame-Options value.
+	//Header will not be sent if empty.
+	FrameOptions string
+	//ReferrerPolicy Referrer-Policy value.
+	//Header will not be sent if empty.
+	ReferrerPolicy string
+	//PermissionsPolicy permissions policy.
+	//Header will not be sent if nil.
+	PermissionsPolicy *PermissionsPolicy
+	//CSP enforced content security policy.
+	//Header will not be sent if nil.
+	CSP *Policy
+	//CSPReportOnly report only content security policy.
+	//Header will not be sent if nil.
+	CSPReportOnly *Policy
+}
+
+func (h *Headers) needNonce() bool {
+	return (h.CSP != nil && h.CSP.NeedNonce()) || (h.CSPReportOnly != nil && h.CSPReportOnly.NeedNonce())
+}
+
+//ServeMiddleware serve as middleware.
+//Nonce will be generated and stored in request context if any policy uses nonce.
+func (h *Headers) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	header := w.Header()
+	if h.HSTS != nil && IsHTTPS(r) {
+		header.Set("Strict-Transport-Security", h.HSTS.String())
+	}
+	if h.NoSniff {
+		header.Set("X-Content-Type-Options", "nosniff")
+	}
+	if h.FrameOptions != "" {
+		header.Set("X-Frame-Options", h.FrameOptions)
+	}
+	if h.ReferrerPolicy != "" {
+		header.Set("Referrer-Policy", h.ReferrerPolicy)
+	}
+	if h.PermissionsPolicy != nil {
+		header.Set("Permissions-Policy", h.PermissionsPolicy.String())
+	}
+	var nonce string
+	if h.needNonce() {
+		var err error
+		nonce, err = NewNonce()
+		if err != nil {
+			panic(err)
+		}
+		ctx := context.WithValue(r.Context(), ContextNameNonce, nonce)
+		r = r.WithContext(ctx)
+	}
+	if h.CSP != nil {
+		header.Set("Content-Security-Policy", h.CSP.Build(nonce))
+	}
+	if h.CSPReportOnly != nil {
+		header.Set("Content-Security-Policy-Report-Only", h.CSPReportOnly.Build(nonce))
+	}
+	next(w, r)
+}
+
+//New create new security headers middleware with nosniff,frame options SAMEORIGIN
+//and strict-origin-when-cross-origin referrer policy.
+func New() *Headers {
+	return &Headers{
+		NoSniff:        true,
+		FrameOptions:   FrameOptionsSameOrigin,
+		ReferrerPolicy: "strict-origin-when-cross-origin",
+	}
+}
+
+//NewNonce create new random nonce.
+//Return nonce and any error if raised.
+func NewNonce() (string, error) {
+	data := make([]byte, NonceSize)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+//Nonce return content security policy nonce of given request.
+//Return empty string if no nonce generated.
+func Nonce(r *http.Request) string {
+	return NonceFromContext(r.Context())
+}
+
+//NonceFromContext return content security policy nonce stored in given context.
+//Return empty string if no nonce generated.
+func NonceFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(ContextNameNonce).(string)
+	return v
+}
+
+//IsHTTPS check if request is served over https.
+//Url scheme rewritten by forwarded middleware is respected.
+func IsHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.URL.Scheme, "https")
+}
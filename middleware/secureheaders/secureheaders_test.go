@@ -0,0 +1,136 @@
+package secureheaders
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/ui/render"
+	"github.com/herb-go/herb/ui/render/engines/gotemplate"
+)
+
+func TestHeaders(t *testing.T) {
+	h := New()
+	h.HSTS = &HSTS{MaxAge: time.Hour, IncludeSubDomains: true, Preload: true}
+	h.PermissionsPolicy = NewPermissionsPolicy().Disable("camera").Allow("geolocation", "self", `"https://example.com"`)
+	h.CSP = NewPolicy().DefaultSrc(SourceSelf).ScriptSrc(SourceSelf, SourceStrictDynamic).WithNonce(DirectiveScriptSrc).UpgradeInsecureRequests()
+	h.CSPReportOnly = NewPolicy().DefaultSrc(SourceNone).ReportURI("/csp")
+	var nonce string
+	app := middleware.New(h.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = Nonce(r)
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	header := rec.Header()
+	if header.Get("Strict-Transport-Security") != "" {
+		t.Fatal(header)
+	}
+	if header.Get("X-Content-Type-Options") != "nosniff" || header.Get("X-Frame-Options") != "SAMEORIGIN" || header.Get("Referrer-Policy") != "strict-origin-when-cross-origin" {
+		t.Fatal(header)
+	}
+	if header.Get("Permissions-Policy") != `camera=(), geolocation=(self "https://example.com")` {
+		t.Fatal(header.Get("Permissions-Policy"))
+	}
+	if nonce == "" || header.Get("Content-Security-Policy") != "default-src 'self'; script-src 'self' 'strict-dynamic' 'nonce-"+nonce+"'; upgrade-insecure-requests" {
+		t.Fatal(nonce, header.Get("Content-Security-Policy"))
+	}
+	if header.Get("Content-Security-Policy-Report-Only") != "default-src 'none'; report-uri /csp" {
+		t.Fatal(header.Get("Content-Security-Policy-Report-Only"))
+	}
+	lastnonce := nonce
+	req := httptest.NewRequest("GET", "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Header().Get("Strict-Transport-Security") != "max-age=3600; includeSubDomains; preload" {
+		t.Fatal(rec.Header())
+	}
+	if nonce == lastnonce {
+		t.Fatal(nonce)
+	}
+}
+
+func TestNoNonce(t *testing.T) {
+	h := &Headers{CSP: NewPolicy().DefaultSrc(SourceSelf)}
+	var nonce = "unset"
+	app := middleware.New(h.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = Nonce(r)
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if nonce != "" || rec.Header().Get("Content-Security-Policy") != "default-src 'self'" || rec.Header().Get("X-Frame-Options") != "" {
+		t.Fatal(nonce, rec.Header())
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	engine := gotemplate.New()
+	engine.SetViewRoot("./testdata")
+	err := RegisterTemplateFunc(engine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := engine.Compile(render.NewViewConfig("nonce.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Headers{CSP: NewPolicy().WithNonce()}
+	var output []byte
+	var nonce string
+	app := middleware.New(h.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = Nonce(r)
+		data := render.NewData()
+		data.Set("Request", r)
+		output, err = view.Execute(data)
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != `<script nonce="`+nonce+`"></script>` {
+		t.Fatal(string(output))
+	}
+	if rec.Header().Get("Content-Security-Policy") != "script-src 'nonce-"+nonce+"'; style-src 'nonce-"+nonce+"'" {
+		t.Fatal(rec.Header())
+	}
+	if TemplateNonce("string") != "" {
+		t.Fatal()
+	}
+}
+
+func TestFactory(t *testing.T) {
+	data, err := json.Marshal(&Config{
+		HSTSMaxAgeInSecond: 60,
+		NoSniff:            true,
+		PermissionsPolicy:  []*FeatureConfig{{Feature: "camera"}},
+		CSP:                []*Directive{{Name: "default-src", Sources: []string{"'self'"}}, {Name: "script-src", Nonce: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nonce string
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = Nonce(r)
+	})
+	req := httptest.NewRequest("GET", "https://example.com/", nil)
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	header := rec.Header()
+	if header.Get("Strict-Transport-Security") != "max-age=60" || header.Get("X-Content-Type-Options") != "nosniff" || header.Get("Permissions-Policy") != "camera=()" {
+		t.Fatal(header)
+	}
+	if header.Get("Content-Security-Policy") != "default-src 'self'; script-src 'nonce-"+nonce+"'" {
+		t.Fatal(header)
+	}
+}
@@ -0,0 +1,31 @@
+package secureheaders
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/herb-go/herb/ui/render"
+)
+
+//DefaultTemplateFuncName default template func name of nonce func.
+const DefaultTemplateFuncName = "cspnonce"
+
+//TemplateNonce template func which returns nonce of given *http.Request or context.Context.
+//Return empty string if value is neither request nor context.
+func TemplateNonce(v interface{}) string {
+	switch value := v.(type) {
+	case *http.Request:
+		return Nonce(value)
+	case context.Context:
+		return NonceFromContext(value)
+	}
+	return ""
+}
+
+//RegisterTemplateFunc register TemplateNonce to render engine with DefaultTemplateFuncName.
+//Views can tag inline scripts with request in render data,
+//for example <script nonce="{{cspnonce .Request}}">.
+//Return any error if raised.
+func RegisterTemplateFunc(e render.Engine) error {
+	return e.RegisterFunc(DefaultTemplateFuncName, TemplateNonce)
+}
@@ -0,0 +1,43 @@
+package timeout
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+//Config timeout middleware config
+type Config struct {
+	//TimeoutInMillisecond timeout duration in millisecond.
+	TimeoutInMillisecond int64
+	//Response timeout response config.
+	//Status 503 will be returned if nil.
+	Response *middlewarefactory.ResponseMiddleware
+}
+
+//CreateTimeout create timeout middleware with config.
+func (c *Config) CreateTimeout() *Timeout {
+	t := New(time.Duration(c.TimeoutInMillisecond) * time.Millisecond)
+	if c.Response != nil {
+		resp := c.Response
+		t.Handler = func(w http.ResponseWriter, r *http.Request) {
+			resp.ServeMiddleware(w, r, nil)
+		}
+	}
+	return t
+}
+
+//NewFactory create new timeout middleware factory.
+//Use middlewarefactory.Config conditions to apply different timeouts to routes.
+func NewFactory() middlewarefactory.Factory {
+	return func(loader func(v interface{}) error) (middleware.Middleware, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		return c.CreateTimeout().ServeMiddleware, nil
+	}
+}
@@ -0,0 +1,194 @@
+//Package timeout provide per request timeout middleware.
+package timeout
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+)
+
+//DefaultTimeoutHandler default handler serving timed out request.
+//Status 503 will be returned.
+var DefaultTimeoutHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+})
+
+//Timeout timeout middleware struct
+type Timeout struct {
+	//Duration timeout duration.
+	//Timeout is disabled if not greater than 0.
+	Duration time.Duration
+	//Handler handler serving timed out request.
+	//DefaultTimeoutHandler will be used if nil.
+	Handler http.HandlerFunc
+}
+
+func (t *Timeout) serveTimeout(w http.ResponseWriter, r *http.Request) {
+	if t.Handler != nil {
+		t.Handler(w, r)
+		return
+	}
+	DefaultTimeoutHandler(w, r)
+}
+
+//ServeMiddleware serve as middleware.
+//Request context will be canceled when timeout.
+//If nothing written to response when timeout,timeout response will be written,
+//and any later writes from next will fail with http.ErrHandlerTimeout.
+//If response is already started,middleware will wait until next finished.
+func (t *Timeout) ServeMiddleware(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if t.Duration <= 0 {
+		next(w, r)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), t.Duration)
+	defer cancel()
+	req := r.WithContext(ctx)
+	tw := newTimeoutWriter(w, ctx)
+	done := make(chan struct{})
+	panicChan := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicChan <- p
+				return
+			}
+			close(done)
+		}()
+		next(tw.wrapped, req)
+	}()
+	select {
+	case p := <-panicChan:
+		panic(p)
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+	tw.locker.Lock()
+	if tw.wroteHeader || tw.hijacked {
+		tw.locker.Unlock()
+		select {
+		case p := <-panicChan:
+			panic(p)
+		case <-done:
+		}
+		return
+	}
+	tw.timedOut = true
+	tw.locker.Unlock()
+	if ctx.Err() == context.DeadlineExceeded {
+		t.serveTimeout(w, r)
+	}
+}
+
+//New create new timeout middleware with given duration.
+func New(d time.Duration) *Timeout {
+	return &Timeout{
+		Duration: d,
+	}
+}
+
+type timeoutWriter struct {
+	locker      sync.Mutex
+	ctx         context.Context
+	writer      http.ResponseWriter
+	wrapped     middleware.ResponseWriter
+	header      http.Header
+	flush       func()
+	hijack      func() (net.Conn, *bufio.ReadWriter, error)
+	wroteHeader bool
+	timedOut    bool
+	hijacked    bool
+}
+
+//check check if response can be written.
+//Response can not be started after context done,
+//no matter whether middleware noticed it or not.
+func (tw *timeoutWriter) check() bool {
+	if !tw.timedOut && !tw.wroteHeader && !tw.hijacked && tw.ctx.Err() != nil {
+		tw.timedOut = true
+	}
+	return !tw.timedOut
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) writeHeader(statusCode int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	h := tw.writer.Header()
+	for k := range tw.header {
+		h[k] = tw.header[k]
+	}
+	tw.writer.WriteHeader(statusCode)
+}
+
+func (tw *timeoutWriter) WriteHeader(statusCode int) {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if !tw.check() || tw.hijacked {
+		return
+	}
+	tw.writeHeader(statusCode)
+}
+
+func (tw *timeoutWriter) Write(data []byte) (int, error) {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if !tw.check() {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeader(http.StatusOK)
+	return tw.writer.Write(data)
+}
+
+func (tw *timeoutWriter) Flush() {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if !tw.check() {
+		return
+	}
+	tw.writeHeader(http.StatusOK)
+	tw.flush()
+}
+
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if !tw.check() {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	tw.hijacked = true
+	return tw.hijack()
+}
+
+func newTimeoutWriter(w http.ResponseWriter, ctx context.Context) *timeoutWriter {
+	tw := &timeoutWriter{
+		ctx:    ctx,
+		writer: w,
+		header: w.Header().Clone(),
+	}
+	tw.wrapped = middleware.WrapResponseWriter(w)
+	f := tw.wrapped.Functions()
+	f.HeaderFunc = tw.Header
+	f.WriteHeaderFunc = tw.WriteHeader
+	f.WriteFunc = tw.Write
+	if f.FlushFunc != nil {
+		tw.flush = f.FlushFunc
+		f.FlushFunc = tw.Flush
+	}
+	if f.HijackFunc != nil {
+		tw.hijack = f.HijackFunc
+		f.HijackFunc = tw.Hijack
+	}
+	return tw
+}
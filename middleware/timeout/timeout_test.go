@@ -0,0 +1,160 @@
+package timeout
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+func TestTimeout(t *testing.T) {
+	lateErr := make(chan error, 1)
+	var deadline time.Time
+	var hasDeadline bool
+	app := middleware.New(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if r.URL.Path == "/slow" {
+			New(50*time.Millisecond).ServeMiddleware(w, r, next)
+			return
+		}
+		New(10*time.Second).ServeMiddleware(w, r, next)
+	}).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, hasDeadline = r.Context().Deadline()
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			w.Header().Set("late", "late")
+			_, err := w.Write([]byte("late"))
+			lateErr <- err
+			return
+		}
+		w.Header().Set("fast", "fast")
+		w.Write([]byte("ok"))
+	})
+	s := httptest.NewServer(app)
+	defer s.Close()
+	resp, err := http.Get(s.URL + "/fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(data) != "ok" || resp.Header.Get("fast") != "fast" {
+		t.Fatal(resp.StatusCode, string(data))
+	}
+	if !hasDeadline || deadline.IsZero() {
+		t.Fatal(deadline)
+	}
+	resp, err = http.Get(s.URL + "/slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 503 || string(data) != http.StatusText(503)+"\n" || resp.Header.Get("late") != "" {
+		t.Fatal(resp.StatusCode, string(data))
+	}
+	if err := <-lateErr; err != http.ErrHandlerTimeout {
+		t.Fatal(err)
+	}
+}
+
+func TestStartedResponse(t *testing.T) {
+	app := middleware.New(New(50 * time.Millisecond).ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("start"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		w.Write([]byte("end"))
+	})
+	s := httptest.NewServer(app)
+	defer s.Close()
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(data) != "startend" {
+		t.Fatal(resp.StatusCode, string(data))
+	}
+}
+
+func TestWriterInterfaces(t *testing.T) {
+	var flusher, hijacker bool
+	app := middleware.New(New(time.Second).ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, flusher = w.(http.Flusher)
+		_, hijacker = w.(http.Hijacker)
+	})
+	s := httptest.NewServer(app)
+	defer s.Close()
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !flusher || !hijacker {
+		t.Fatal(flusher, hijacker)
+	}
+	app.ServeHTTP(&plainWriter{header: http.Header{}}, httptest.NewRequest("GET", "/", nil))
+	if flusher || hijacker {
+		t.Fatal(flusher, hijacker)
+	}
+}
+
+type plainWriter struct {
+	header http.Header
+}
+
+func (w *plainWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *plainWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *plainWriter) WriteHeader(int) {
+}
+
+func TestPanic(t *testing.T) {
+	app := middleware.New(New(time.Second).ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test")
+	})
+	defer func() {
+		if r := recover(); r != "test" {
+			t.Fatal(r)
+		}
+	}()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestFactory(t *testing.T) {
+	body := "timeout"
+	data, err := json.Marshal(&Config{
+		TimeoutInMillisecond: 10,
+		Response: &middlewarefactory.ResponseMiddleware{
+			StatusCode: 504,
+			Body:       &body,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewFactory()(func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := middleware.New(m).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != 504 || rec.Body.String() != "timeout" {
+		t.Fatal(rec.Code, rec.Body.String())
+	}
+}
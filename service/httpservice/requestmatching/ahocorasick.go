@@ -0,0 +1,95 @@
+package requestmatching
+
+//ahoCorasick Aho-Corasick automaton finding all keywords contained in text.
+type ahoCorasick struct {
+	nodes []acNode
+	//empty terms of empty keywords which are contained in any text
+	empty []int
+}
+
+type acNode struct {
+	next map[byte]int
+	fail int
+	//out terms of keywords ending at node,including terms of fail links
+	out []int
+}
+
+func newAhoCorasick() *ahoCorasick {
+	return &ahoCorasick{nodes: []acNode{{}}}
+}
+
+func (a *ahoCorasick) add(keyword string, term int) {
+	if keyword == "" {
+		a.empty = append(a.empty, term)
+		return
+	}
+	n := 0
+	for i := 0; i < len(keyword); i++ {
+		next, ok := a.nodes[n].next[keyword[i]]
+		if !ok {
+			if a.nodes[n].next == nil {
+				a.nodes[n].next = map[byte]int{}
+			}
+			next = len(a.nodes)
+			a.nodes[n].next[keyword[i]] = next
+			a.nodes = append(a.nodes, acNode{})
+		}
+		n = next
+	}
+	a.nodes[n].out = append(a.nodes[n].out, term)
+}
+
+//build build fail links.
+//Automaton should be built after all keywords added.
+func (a *ahoCorasick) build() {
+	queue := []int{}
+	for _, child := range a.nodes[0].next {
+		a.nodes[child].fail = 0
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for c, child := range a.nodes[n].next {
+			f := a.nodes[n].fail
+			for {
+				if next, ok := a.nodes[f].next[c]; ok {
+					a.nodes[child].fail = next
+					break
+				}
+				if f == 0 {
+					a.nodes[child].fail = 0
+					break
+				}
+				f = a.nodes[f].fail
+			}
+			a.nodes[child].out = append(a.nodes[child].out, a.nodes[a.nodes[child].fail].out...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+//search emit terms of all keywords contained in text.
+//Term may be emitted more than once.
+func (a *ahoCorasick) search(text string, emit func(term int)) {
+	for _, term := range a.empty {
+		emit(term)
+	}
+	n := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		for {
+			if next, ok := a.nodes[n].next[c]; ok {
+				n = next
+				break
+			}
+			if n == 0 {
+				break
+			}
+			n = a.nodes[n].fail
+		}
+		for _, term := range a.nodes[n].out {
+			emit(term)
+		}
+	}
+}
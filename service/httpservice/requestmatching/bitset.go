@@ -0,0 +1,31 @@
+package requestmatching
+
+//bitset fixed size bit set
+type bitset []uint64
+
+func newBitset(size int) bitset {
+	return make(bitset, (size+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i>>6] |= 1 << uint(i&63)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i>>6]&(1<<uint(i&63)) != 0
+}
+
+func (b bitset) clear() {
+	for k := range b {
+		b[k] = 0
+	}
+}
+
+func (b bitset) empty() bool {
+	for k := range b {
+		if b[k] != 0 {
+			return false
+		}
+	}
+	return true
+}
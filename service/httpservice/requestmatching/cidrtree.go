@@ -0,0 +1,73 @@
+package requestmatching
+
+import "net"
+
+//cidrTree binary radix tree of ip nets.
+//IPv4 and IPv4-mapped nets are stored in IPv4 tree,consistent with net.IPNet.Contains.
+type cidrTree struct {
+	nodes []cidrNode
+	v4    int
+	v6    int
+}
+
+type cidrNode struct {
+	children [2]int
+	terms    []int
+}
+
+func newCIDRTree() *cidrTree {
+	return &cidrTree{nodes: []cidrNode{{}, {}}, v4: 0, v6: 1}
+}
+
+func (t *cidrTree) add(n *net.IPNet, term int) {
+	ip := n.IP
+	mask := n.Mask
+	root := t.v6
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		if len(mask) == net.IPv6len {
+			mask = mask[12:]
+		}
+		root = t.v4
+	}
+	ones, _ := mask.Size()
+	node := root
+	for i := 0; i < ones; i++ {
+		bit := (ip[i/8] >> uint(7-i%8)) & 1
+		next := t.nodes[node].children[bit]
+		if next == 0 {
+			next = len(t.nodes)
+			t.nodes[node].children[bit] = next
+			t.nodes = append(t.nodes, cidrNode{})
+		}
+		node = next
+	}
+	t.nodes[node].terms = append(t.nodes[node].terms, term)
+}
+
+//lookup emit terms of all nets containing ip.
+func (t *cidrTree) lookup(ip net.IP, emit func(term int)) {
+	if ip == nil {
+		return
+	}
+	node := t.v6
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		node = t.v4
+	} else if len(ip) != net.IPv6len {
+		return
+	}
+	for i := 0; ; i++ {
+		for _, term := range t.nodes[node].terms {
+			emit(term)
+		}
+		if i == len(ip)*8 {
+			return
+		}
+		bit := (ip[i/8] >> uint(7-i%8)) & 1
+		node = t.nodes[node].children[bit]
+		if node == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package requestmatching
+
+import (
+	"net/http"
+)
+
+//Cookies cookie pattern
+type Cookies []*ValueData
+
+//Add add cookie to pattern.
+//Cookie should be in "name","name=value","name^=prefix" or "name~=regexp" form.
+//Return error if cookie is not validated.
+func (c *Cookies) Add(cookie string) error {
+	d, err := ParseValueData(cookie, ErrCookieNotValidated)
+	if err != nil {
+		return err
+	}
+	*c = append(*c, d)
+	return nil
+}
+
+func cookieValues(r *http.Request) func(name string) []string {
+	return func(name string) []string {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return nil
+		}
+		return []string{cookie.Value}
+	}
+}
+
+//MatchRequest match request.
+//Return result and any error if raised.
+func (c *Cookies) MatchRequest(r *http.Request) (bool, error) {
+	if len(*c) == 0 {
+		return true, nil
+	}
+	return matchValues(*c, cookieValues(r), false), nil
+}
+
+//MatchRequestAll match request with all cookies.
+//Return result and any error if raised.
+func (c *Cookies) MatchRequestAll(r *http.Request) (bool, error) {
+	return matchValues(*c, cookieValues(r), true), nil
+}
+
+//NewCookies create new cookies pattern.
+func NewCookies() *Cookies {
+	return &Cookies{}
+}
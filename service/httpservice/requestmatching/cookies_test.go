@@ -0,0 +1,51 @@
+package requestmatching
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCookies(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://127.0.0.1/", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc123"})
+	r.AddCookie(&http.Cookie{Name: "empty", Value: ""})
+	c := NewCookies()
+	if !MustMatch(r, c) {
+		t.Fatal(c)
+	}
+	err := c.Add("=value")
+	if !errors.Is(err, ErrCookieNotValidated) {
+		t.Fatal(err)
+	}
+	for pattern, expected := range map[string]bool{
+		"session":         true,
+		"empty":           true,
+		"missing":         false,
+		"session=abc123":  true,
+		"session=abc":     false,
+		"session^=abc":    true,
+		"session~=[0-9]$": true,
+		"empty=":          true,
+	} {
+		c = NewCookies()
+		err := c.Add(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if MustMatch(r, c) != expected {
+			t.Fatal(pattern)
+		}
+	}
+	c = NewCookies()
+	c.Add("session")
+	c.Add("missing")
+	ok, err := c.MatchRequestAll(r)
+	if ok || err != nil || !MustMatch(r, c) {
+		t.Fatal(ok, err)
+	}
+	p := MustCreatePattern(&PatternConfig{CookieList: []string{"session^=abc"}})
+	if !MustMatch(r, p) {
+		t.Fatal(p)
+	}
+}
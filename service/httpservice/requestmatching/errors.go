@@ -4,3 +4,36 @@ import "errors"
 
 //ErrHeaderNotValidated error raised if given header is not validated.
 var ErrHeaderNotValidated = errors.New("requestmatching:header is not validated")
+
+//ErrUnknownMatchMode error raised if given match mode is unknown.
+var ErrUnknownMatchMode = errors.New("requestmatching:unknown match mode")
+
+//ErrUnknownField error raised if given pattern field is unknown.
+var ErrUnknownField = errors.New("requestmatching:unknown pattern field")
+
+//ErrMatchModeNotSupported error raised if pattern field does not support given match mode.
+var ErrMatchModeNotSupported = errors.New("requestmatching:match mode not supported by field")
+
+//ErrQueryNotValidated error raised if given query pattern is not validated.
+var ErrQueryNotValidated = errors.New("requestmatching:query is not validated")
+
+//ErrCookieNotValidated error raised if given cookie pattern is not validated.
+var ErrCookieNotValidated = errors.New("requestmatching:cookie is not validated")
+
+//ErrParamNotValidated error raised if given router param pattern is not validated.
+var ErrParamNotValidated = errors.New("requestmatching:router param is not validated")
+
+//ErrUnknownUserAgentFamily error raised if given user agent family is unknown.
+var ErrUnknownUserAgentFamily = errors.New("requestmatching:unknown user agent family")
+
+//ErrGlobNotValidated error raised if given glob pattern is not validated.
+var ErrGlobNotValidated = errors.New("requestmatching:glob is not validated")
+
+//ErrTemplateNotValidated error raised if given path template is not validated.
+var ErrTemplateNotValidated = errors.New("requestmatching:path template is not validated")
+
+//ErrGeoIPNotValidated error raised if given country,continent or asn is not validated.
+var ErrGeoIPNotValidated = errors.New("requestmatching:geoip is not validated")
+
+//ErrGeoDatabaseRequired error raised if geo pattern has no database.
+var ErrGeoDatabaseRequired = errors.New("requestmatching:geo database required")
@@ -0,0 +1,185 @@
+package requestmatching
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//FieldResult plain pattern field match result
+type FieldResult struct {
+	//Field field name
+	Field string
+	//Mode mode combining records within field
+	Mode MatchMode
+	//Result field match result
+	Result bool
+}
+
+//Explanation pattern match explanation
+type Explanation struct {
+	//Result final match result
+	Result bool
+	//Disabled whether pattern is disabled
+	Disabled bool
+	//Not whether result is reversed
+	Not bool
+	//Mode mode combining fields
+	Mode MatchMode
+	//Fields configured field results in evaluation order
+	Fields []*FieldResult
+	//Patterns sub pattern explanations
+	Patterns []*Explanation
+}
+
+//String return explanation in human readable format.
+func (e *Explanation) String() string {
+	var b strings.Builder
+	if e.Disabled {
+		b.WriteString("disabled ")
+	}
+	if e.Not {
+		b.WriteString("not ")
+	}
+	b.WriteString("(")
+	for k, v := range e.Fields {
+		if k > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(v.Field)
+		b.WriteString("=")
+		b.WriteString(strconv.FormatBool(v.Result))
+	}
+	b.WriteString(")")
+	if len(e.Patterns) > 0 {
+		b.WriteString(" [")
+		for k, v := range e.Patterns {
+			if k > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(v.String())
+		}
+		b.WriteString("]")
+	}
+	b.WriteString(" => ")
+	b.WriteString(strconv.FormatBool(e.Result))
+	return b.String()
+}
+
+//Explainer pattern which can explain match result.
+type Explainer interface {
+	//Explain match request and explain result.
+	//Return explanation and any error if raised.
+	Explain(r *http.Request) (*Explanation, error)
+}
+
+//Explain match request with given pattern and explain result.
+//Only result will be returned if pattern is not an explainer.
+//Return explanation and any error if raised.
+func Explain(r *http.Request, p Pattern) (*Explanation, error) {
+	if e, ok := p.(Explainer); ok {
+		return e.Explain(r)
+	}
+	result, err := p.MatchRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Explanation{Result: result}, nil
+}
+
+func explainAll(r *http.Request, p []Pattern) ([]*Explanation, error) {
+	result := make([]*Explanation, len(p))
+	for k := range p {
+		e, err := Explain(r, p[k])
+		if err != nil {
+			return nil, err
+		}
+		result[k] = e
+	}
+	return result, nil
+}
+
+//Explain match request and explain result.
+//All fields and sub patterns are evaluated.
+//Return explanation and any error if raised.
+func (p *PlainPattern) Explain(r *http.Request) (*Explanation, error) {
+	e := &Explanation{
+		Disabled: p.Disabled,
+		Not:      p.Not,
+		Mode:     p.Mode,
+	}
+	result, err := p.matchFields(r, func(name string, result bool) {
+		e.Fields = append(e.Fields, &FieldResult{
+			Field:  name,
+			Mode:   p.FieldModes[name],
+			Result: result,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p.Patterns != nil {
+		e.Patterns, err = explainAll(r, p.Patterns)
+		if err != nil {
+			return nil, err
+		}
+		if p.And {
+			result = allResult(e.Patterns)
+		} else if !result {
+			result = anyResult(e.Patterns)
+		}
+	}
+	e.Result = !p.Disabled && result != p.Not
+	return e, nil
+}
+
+func allResult(e []*Explanation) bool {
+	for k := range e {
+		if !e[k].Result {
+			return false
+		}
+	}
+	return true
+}
+
+func anyResult(e []*Explanation) bool {
+	if len(e) == 0 {
+		return true
+	}
+	for k := range e {
+		if e[k].Result {
+			return true
+		}
+	}
+	return false
+}
+
+//Explain match request and explain result.
+//Return explanation and any error if raised.
+func (f *Filters) Explain(r *http.Request) (*Explanation, error) {
+	patterns, err := explainAll(r, *f)
+	if err != nil {
+		return nil, err
+	}
+	return &Explanation{Mode: MatchModeAny, Patterns: patterns, Result: anyResult(patterns)}, nil
+}
+
+//Explain match request and explain result.
+//Return explanation and any error if raised.
+func (w *Whitelist) Explain(r *http.Request) (*Explanation, error) {
+	patterns, err := explainAll(r, *w)
+	if err != nil {
+		return nil, err
+	}
+	return &Explanation{Mode: MatchModeAny, Patterns: patterns, Result: len(patterns) > 0 && anyResult(patterns)}, nil
+}
+
+//Explain match request and explain result.
+//Return explanation and any error if raised.
+func (p *PatternAll) Explain(r *http.Request) (*Explanation, error) {
+	patterns, err := explainAll(r, *p)
+	if err != nil {
+		return nil, err
+	}
+	return &Explanation{Mode: MatchModeAll, Patterns: patterns, Result: allResult(patterns)}, nil
+}
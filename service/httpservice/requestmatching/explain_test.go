@@ -0,0 +1,60 @@
+package requestmatching
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://127.0.0.1/admin/?debug=1", nil)
+	r.Header.Set("X-A", "1")
+	p := MustCreatePattern(&PatternConfig{
+		MethodList: []string{"POST"},
+		HeaderList: []string{"X-A:1"},
+		RegExpList: []string{"^/public"},
+		Patterns:   []*PatternConfig{{KeywordList: []string{"debug"}}},
+	})
+	e, err := Explain(r, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Result != MustMatch(r, p) || !e.Result {
+		t.Fatal(e)
+	}
+	if len(e.Fields) != 3 ||
+		e.Fields[0].Field != FieldMethods || e.Fields[0].Result ||
+		e.Fields[1].Field != FieldRegExps || e.Fields[1].Result ||
+		e.Fields[2].Field != FieldHeaders || !e.Fields[2].Result {
+		t.Fatal(e)
+	}
+	if len(e.Patterns) != 1 || !e.Patterns[0].Result || e.Patterns[0].Fields[0].Field != FieldKeywords {
+		t.Fatal(e)
+	}
+	if e.String() != "(Methods=false RegExps=false Headers=true) [(Keywords=true) => true] => true" {
+		t.Fatal(e.String())
+	}
+	p.(*PlainPattern).Not = true
+	e, err = Explain(r, p)
+	if err != nil || e.Result || e.String()[:4] != "not " {
+		t.Fatal(e, err)
+	}
+	f := MustCreatePattern(&FiltersConfig{{URLList: []string{"/fail"}}, {PrefixList: []string{"/admin/"}}})
+	e, err = Explain(r, f)
+	if err != nil || !e.Result || len(e.Patterns) != 2 || e.Patterns[0].Result || !e.Patterns[1].Result {
+		t.Fatal(e, err)
+	}
+	w := MustCreatePattern(&WhitelistConfig{})
+	e, err = Explain(r, w)
+	if err != nil || e.Result {
+		t.Fatal(e, err)
+	}
+	a := MustCreatePattern(&PatternAllConfig{{PrefixList: []string{"/admin/"}}, {MethodList: []string{"POST"}}})
+	e, err = Explain(r, a)
+	if err != nil || e.Result || e.Result != MustMatch(r, a) {
+		t.Fatal(e, err)
+	}
+	e, err = Explain(r, NewMethods())
+	if err != nil || !e.Result || e.Fields != nil {
+		t.Fatal(e, err)
+	}
+}
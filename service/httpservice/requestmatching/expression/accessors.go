@@ -0,0 +1,85 @@
+package expression
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/herb-go/herb/middleware/router"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+//Accessor request accessor which provides value compared in expression.
+type Accessor struct {
+	//Name accessor name
+	Name string
+	//Argument whether accessor requires string argument,such as header("X-Token")
+	Argument bool
+	//String func return string value of request.
+	//Nil if accessor is ip accessor.
+	String func(r *http.Request, arg string) string
+	//IP func return ip of request.
+	//Nil if accessor is string accessor.
+	IP func(r *http.Request) net.IP
+}
+
+func newStringAccessor(name string, argument bool, fn func(r *http.Request, arg string) string) *Accessor {
+	return &Accessor{Name: name, Argument: argument, String: fn}
+}
+
+//Accessors fixed request accessors by name.
+var Accessors = map[string]*Accessor{
+	"method": newStringAccessor("method", false, func(r *http.Request, arg string) string {
+		return r.Method
+	}),
+	"host": newStringAccessor("host", false, func(r *http.Request, arg string) string {
+		return strings.ToLower(r.Host)
+	}),
+	"hostname": newStringAccessor("hostname", false, func(r *http.Request, arg string) string {
+		host := strings.ToLower(r.Host)
+		hostname, _, err := net.SplitHostPort(host)
+		if err != nil {
+			return host
+		}
+		return hostname
+	}),
+	"path": newStringAccessor("path", false, func(r *http.Request, arg string) string {
+		return r.URL.Path
+	}),
+	"url": newStringAccessor("url", false, func(r *http.Request, arg string) string {
+		return r.URL.RequestURI()
+	}),
+	"ext": newStringAccessor("ext", false, func(r *http.Request, arg string) string {
+		return strings.ToLower(filepath.Ext(r.URL.Path))
+	}),
+	"useragent": newStringAccessor("useragent", false, func(r *http.Request, arg string) string {
+		return r.UserAgent()
+	}),
+	"uafamily": newStringAccessor("uafamily", false, func(r *http.Request, arg string) string {
+		return requestmatching.GetUserAgentFamily(r.UserAgent())
+	}),
+	"contenttype": newStringAccessor("contenttype", false, func(r *http.Request, arg string) string {
+		return strings.TrimSpace(strings.SplitN(r.Header.Get("Content-Type"), ";", 2)[0])
+	}),
+	"header": newStringAccessor("header", true, func(r *http.Request, arg string) string {
+		return r.Header.Get(arg)
+	}),
+	"query": newStringAccessor("query", true, func(r *http.Request, arg string) string {
+		return r.URL.Query().Get(arg)
+	}),
+	"cookie": newStringAccessor("cookie", true, func(r *http.Request, arg string) string {
+		c, err := r.Cookie(arg)
+		if err != nil {
+			return ""
+		}
+		return c.Value
+	}),
+	"param": newStringAccessor("param", true, func(r *http.Request, arg string) string {
+		return router.GetParams(r).Get(arg)
+	}),
+	"ip": &Accessor{
+		Name: "ip",
+		IP:   requestmatching.GetRequestIP,
+	},
+}
@@ -0,0 +1,33 @@
+package expression
+
+import (
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+//Config expression config
+type Config struct {
+	//Expression expression source
+	Expression string
+}
+
+//CreateExpression create expression with config.
+//Return expression and any error if raised.
+func (c *Config) CreateExpression() (*Expression, error) {
+	return Compile(c.Expression)
+}
+
+//NewConditionFactory create new expression condition factory.
+func NewConditionFactory() middlewarefactory.ConditionFactory {
+	return func(loader func(v interface{}) error) (middlewarefactory.Condition, error) {
+		c := &Config{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		e, err := c.CreateExpression()
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+}
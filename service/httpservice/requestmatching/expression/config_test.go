@@ -0,0 +1,34 @@
+package expression
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+func newLoader(source string) func(v interface{}) error {
+	return func(v interface{}) error {
+		v.(*Config).Expression = source
+		return nil
+	}
+}
+
+func TestConditionFactory(t *testing.T) {
+	ctx := middlewarefactory.NewContext()
+	ctx.RegisterConditionFactory("expression", NewConditionFactory())
+	c, err := ctx.CreateCondition("expression", newLoader(`method == "POST"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := http.NewRequest("POST", "http://example.com/", nil)
+	ok, err := c.MatchRequest(r)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	c, err = ctx.CreateCondition("expression", newLoader(`method ==`))
+	if c != nil || !errors.Is(err, ErrSyntax) {
+		t.Fatal(c, err)
+	}
+}
@@ -0,0 +1,66 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//ErrSyntax error raised if expression syntax is invalid.
+var ErrSyntax = errors.New("expression:syntax error")
+
+//ErrUnknownAccessor error raised if request accessor is unknown.
+var ErrUnknownAccessor = errors.New("expression:unknown accessor")
+
+//ErrUnknownOperator error raised if operator is unknown.
+var ErrUnknownOperator = errors.New("expression:unknown operator")
+
+//ErrTypeMismatch error raised if operator or value does not fit accessor type.
+var ErrTypeMismatch = errors.New("expression:type mismatch")
+
+//ErrInvalidRegExp error raised if regexp literal can not be compiled.
+var ErrInvalidRegExp = errors.New("expression:invalid regexp")
+
+//ErrInvalidGlob error raised if glob literal can not be compiled.
+var ErrInvalidGlob = errors.New("expression:invalid glob")
+
+//ErrInvalidIPNet error raised if ip or cidr literal is invalid.
+var ErrInvalidIPNet = errors.New("expression:invalid ip or cidr")
+
+//Error expression compile error with position.
+//Err is one of expression errors and can be checked by errors.Is.
+type Error struct {
+	//Err underlying error
+	Err error
+	//Detail error detail
+	Detail string
+	//Offset byte offset in source
+	Offset int
+	//Line line number,starts from 1
+	Line int
+	//Column column number in bytes,starts from 1
+	Column int
+}
+
+//Error return error message.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s : %s at line %d column %d", e.Err.Error(), e.Detail, e.Line, e.Column)
+}
+
+//Unwrap return underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(source string, offset int, err error, detail string) *Error {
+	before := source[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - strings.LastIndex(before, "\n")
+	return &Error{
+		Err:    err,
+		Detail: detail,
+		Offset: offset,
+		Line:   line,
+		Column: column,
+	}
+}
@@ -0,0 +1,61 @@
+package expression
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	for _, v := range []struct {
+		source string
+		err    error
+		line   int
+		column int
+	}{
+		{``, ErrSyntax, 1, 1},
+		{`method`, ErrSyntax, 1, 7},
+		{`method == `, ErrSyntax, 1, 11},
+		{`method = "GET"`, ErrSyntax, 1, 8},
+		{`method == "GET`, ErrSyntax, 1, 11},
+		{`method == "GET" &`, ErrSyntax, 1, 17},
+		{`method == "GET" path == "/"`, ErrSyntax, 1, 17},
+		{`(method == "GET"`, ErrSyntax, 1, 17},
+		{`method in ["GET" "POST"]`, ErrSyntax, 1, 18},
+		{`method # "GET"`, ErrSyntax, 1, 8},
+		{`header == "a"`, ErrSyntax, 1, 8},
+		{`verb == "GET"`, ErrUnknownAccessor, 1, 1},
+		{`method like "GET"`, ErrUnknownOperator, 1, 8},
+		{"method == \"GET\" &&\n  path matches \"(\"", ErrInvalidRegExp, 2, 16},
+		{`path glob "/{a,b"`, ErrInvalidGlob, 1, 11},
+		{`ip in 10.0.0.0/33`, ErrInvalidIPNet, 1, 7},
+		{`ip in ["10.0.0.1", "a.b"]`, ErrInvalidIPNet, 1, 20},
+		{`ip == 10.0.0.0/8`, ErrTypeMismatch, 1, 7},
+		{`ip startsWith "10."`, ErrTypeMismatch, 1, 4},
+		{`method in "GET"`, ErrTypeMismatch, 1, 11},
+		{`method == 10.0.0.1`, ErrTypeMismatch, 1, 11},
+		{`method in ["GET", 10.0.0.1]`, ErrTypeMismatch, 1, 19},
+	} {
+		_, err := Compile(v.source)
+		if !errors.Is(err, v.err) {
+			t.Fatal(v.source, err)
+		}
+		e, ok := err.(*Error)
+		if !ok || e.Line != v.line || e.Column != v.column {
+			t.Fatal(v.source, err)
+		}
+	}
+	_, err := Compile(`method like "GET"`)
+	if err.Error() != `expression:unknown operator : "like" at line 1 column 8` {
+		t.Fatal(err)
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal(r)
+		}
+	}()
+	MustCompile(`method ==`)
+}
@@ -0,0 +1,54 @@
+//Package expression provide boolean expression language for request matching.
+package expression
+
+import (
+	"net/http"
+)
+
+//Expression compiled request matching expression.
+//Expression can be used as requestmatching.Pattern or middlewarefactory.Condition.
+type Expression struct {
+	source  string
+	matcher matcher
+}
+
+//MatchRequest match request.
+//Return result and any error if raised.
+func (e *Expression) MatchRequest(r *http.Request) (bool, error) {
+	return e.matcher(r)
+}
+
+//String return expression source.
+func (e *Expression) String() string {
+	return e.source
+}
+
+//Compile compile given expression source.
+//Return expression and any error if raised.
+//Compile error is *Error with position.
+func Compile(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{source: source, tokens: tokens}
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	t := p.next()
+	if t.kind != tokenEOF {
+		return nil, p.errorf(t, ErrSyntax, "unexpected %s", t.describe())
+	}
+	return &Expression{source: source, matcher: m}, nil
+}
+
+//MustCompile compile given expression source.
+//Panic if any error raised.
+func MustCompile(source string) *Expression {
+	e, err := Compile(source)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
@@ -0,0 +1,103 @@
+package expression
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/middleware/router"
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+var _ requestmatching.Pattern = &Expression{}
+var _ middlewarefactory.Condition = &Expression{}
+
+func newTestRequest(method string, url string, remoteAddr string) *http.Request {
+	r, _ := http.NewRequest(method, url, nil)
+	r.RemoteAddr = remoteAddr
+	return r
+}
+
+func TestExpression(t *testing.T) {
+	e := MustCompile(`method in ["POST","PUT"] && path startsWith "/api" && !ip in 10.0.0.0/8`)
+	if e.String() != `method in ["POST","PUT"] && path startsWith "/api" && !ip in 10.0.0.0/8` {
+		t.Fatal(e)
+	}
+	for _, v := range []struct {
+		r        *http.Request
+		expected bool
+	}{
+		{newTestRequest("POST", "http://example.com/api/users", "8.8.8.8:1"), true},
+		{newTestRequest("PUT", "http://example.com/api", "[2001:db8::1]:1"), true},
+		{newTestRequest("GET", "http://example.com/api/users", "8.8.8.8:1"), false},
+		{newTestRequest("POST", "http://example.com/web", "8.8.8.8:1"), false},
+		{newTestRequest("POST", "http://example.com/api/users", "10.1.2.3:1"), false},
+	} {
+		ok, err := e.MatchRequest(v.r)
+		if err != nil || ok != v.expected {
+			t.Fatal(v.r, ok, err)
+		}
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	r := newTestRequest("GET", "http://WWW.Example.com:8080/static/js/App.JS?debug=1&q=x", "192.168.1.5:1234")
+	r.Host = "WWW.Example.com:8080"
+	r.Header.Set("X-Token", "secret")
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("User-Agent", "curl/7.0")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc123"})
+	router.GetParams(r).Set("id", "12")
+	for source, expected := range map[string]bool{
+		`method == "GET"`:                            true,
+		`method != "GET"`:                            false,
+		`host == "www.example.com:8080"`:             true,
+		`hostname == "www.example.com"`:              true,
+		`path == "/static/js/App.JS"`:                true,
+		`path glob "/static/**/*.JS"`:                true,
+		`path glob "/static/*.JS"`:                   false,
+		`url endsWith "q=x"`:                         true,
+		`ext == ".js"`:                               true,
+		`useragent contains "curl"`:                  true,
+		`uafamily in ["curl","wget"]`:                true,
+		`contenttype == "application/json"`:          true,
+		`header("X-Token") == "secret"`:              true,
+		`header("x-token") == "secret"`:              true,
+		`header("X-Missing") == ""`:                  true,
+		`query("debug") == "1"`:                      true,
+		`query("q") matches "^[a-z]+$"`:              true,
+		`cookie("session") matches "^[a-z]+[0-9]+$"`: true,
+		`cookie("missing") != ""`:                    false,
+		`param("id") == "12"`:                        true,
+		`ip in 192.168.0.0/16`:                       true,
+		`ip in "192.168.1.5"`:                        true,
+		`ip == 192.168.1.5`:                          true,
+		`ip != 192.168.1.5`:                          false,
+		`ip in [10.0.0.0/8, "::1", 192.168.1.0/24]`:  true,
+		`ip in []`:       false,
+		`true`:           true,
+		`!true || false`: false,
+		`(method == "POST" || method == "GET") && !(ext == ".css")`: true,
+		`method == "POST" || method == "GET" && ext == ".css"`:      false,
+		"method == `GET`\n&& path startsWith \"/static\"":           true,
+	} {
+		e, err := Compile(source)
+		if err != nil {
+			t.Fatal(source, err)
+		}
+		ok, err := e.MatchRequest(r)
+		if err != nil || ok != expected {
+			t.Fatal(source, ok, err)
+		}
+	}
+}
+
+func TestInvalidIP(t *testing.T) {
+	r := newTestRequest("GET", "http://example.com/", "invalid")
+	if ok, err := MustCompile(`ip in 0.0.0.0/0`).MatchRequest(r); ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	if ok, err := MustCompile(`ip != 127.0.0.1`).MatchRequest(r); !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+}
@@ -0,0 +1,134 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenAddress
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenEqual
+	tokenNotEqual
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	value  string
+	offset int
+}
+
+//describe return token description used in error messages.
+func (t *token) describe() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+func isIdentByte(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+func isAddressByte(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') || c == '.' || c == ':' || c == '/'
+}
+
+//tokenize split source to tokens.
+//Return tokens and any error if raised.
+func tokenize(source string) ([]*token, error) {
+	tokens := []*token{}
+	i := 0
+	for {
+		for i < len(source) && (source[i] == ' ' || source[i] == '\t' || source[i] == '\n' || source[i] == '\r') {
+			i++
+		}
+		if i >= len(source) {
+			tokens = append(tokens, &token{kind: tokenEOF, offset: i})
+			return tokens, nil
+		}
+		start := i
+		c := source[i]
+		t := &token{offset: start}
+		switch {
+		case c == '(':
+			t.kind = tokenLParen
+			i++
+		case c == ')':
+			t.kind = tokenRParen
+			i++
+		case c == '[':
+			t.kind = tokenLBracket
+			i++
+		case c == ']':
+			t.kind = tokenRBracket
+			i++
+		case c == ',':
+			t.kind = tokenComma
+			i++
+		case c == '&' || c == '|' || c == '=':
+			if i+1 >= len(source) || source[i+1] != c {
+				return nil, newError(source, start, ErrSyntax, fmt.Sprintf("unexpected character %q", c))
+			}
+			t.kind = map[byte]tokenKind{'&': tokenAnd, '|': tokenOr, '=': tokenEqual}[c]
+			i += 2
+		case c == '!':
+			t.kind = tokenNot
+			i++
+			if i < len(source) && source[i] == '=' {
+				t.kind = tokenNotEqual
+				i++
+			}
+		case c == '"' || c == '`':
+			i++
+			for i < len(source) && source[i] != c {
+				if c == '"' && source[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(source) {
+				return nil, newError(source, start, ErrSyntax, "unterminated string")
+			}
+			i++
+			value, err := strconv.Unquote(source[start:i])
+			if err != nil {
+				return nil, newError(source, start, ErrSyntax, "invalid string "+source[start:i])
+			}
+			t.kind = tokenString
+			t.value = value
+		case (c >= '0' && c <= '9') || c == ':':
+			for i < len(source) && isAddressByte(source[i]) {
+				i++
+			}
+			t.kind = tokenAddress
+			t.value = source[start:i]
+		case isIdentByte(c, true):
+			for i < len(source) && isIdentByte(source[i], false) {
+				i++
+			}
+			t.kind = tokenIdent
+			t.value = source[start:i]
+		default:
+			return nil, newError(source, start, ErrSyntax, fmt.Sprintf("unexpected character %q", c))
+		}
+		t.text = source[start:i]
+		tokens = append(tokens, t)
+	}
+}
@@ -0,0 +1,346 @@
+package expression
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/herb-go/herb/service/httpservice/requestmatching"
+)
+
+type matcher func(r *http.Request) (bool, error)
+
+func (m matcher) MatchRequest(r *http.Request) (bool, error) {
+	return m(r)
+}
+
+type value struct {
+	token *token
+	list  []*token
+}
+
+type parser struct {
+	source string
+	tokens []*token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() *token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t *token, err error, format string, args ...interface{}) error {
+	return newError(p.source, t.offset, err, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) expect(kind tokenKind, expected string) (*token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return nil, p.errorf(t, ErrSyntax, "expected %s,got %s", expected, t.describe())
+	}
+	return t, nil
+}
+
+func (p *parser) parseOr() (matcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(r *http.Request) (bool, error) {
+			ok, err := l(r)
+			if err != nil || ok {
+				return ok, err
+			}
+			return right(r)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (matcher, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(r *http.Request) (bool, error) {
+			ok, err := l(r)
+			if err != nil || !ok {
+				return false, err
+			}
+			return right(r)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (matcher, error) {
+	if p.peek().kind != tokenNot {
+		return p.parsePrimary()
+	}
+	p.next()
+	m, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return func(r *http.Request) (bool, error) {
+		ok, err := m(r)
+		if err != nil {
+			return false, err
+		}
+		return !ok, nil
+	}, nil
+}
+
+func (p *parser) parsePrimary() (matcher, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.expect(tokenRParen, "\")\"")
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case tokenIdent:
+		switch t.value {
+		case "true", "false":
+			result := t.value == "true"
+			return func(r *http.Request) (bool, error) {
+				return result, nil
+			}, nil
+		}
+		return p.parseComparison(t)
+	}
+	return nil, p.errorf(t, ErrSyntax, "expected condition,got %s", t.describe())
+}
+
+func (p *parser) parseValue() (*value, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString, tokenAddress:
+		return &value{token: t}, nil
+	case tokenLBracket:
+		v := &value{token: t, list: []*token{}}
+		if p.peek().kind == tokenRBracket {
+			p.next()
+			return v, nil
+		}
+		for {
+			item := p.next()
+			if item.kind != tokenString && item.kind != tokenAddress {
+				return nil, p.errorf(item, ErrSyntax, "expected list item,got %s", item.describe())
+			}
+			v.list = append(v.list, item)
+			sep := p.next()
+			if sep.kind == tokenRBracket {
+				return v, nil
+			}
+			if sep.kind != tokenComma {
+				return nil, p.errorf(sep, ErrSyntax, "expected \",\" or \"]\",got %s", sep.describe())
+			}
+		}
+	}
+	return nil, p.errorf(t, ErrSyntax, "expected value,got %s", t.describe())
+}
+
+func (p *parser) parseComparison(name *token) (matcher, error) {
+	a := Accessors[name.value]
+	if a == nil {
+		return nil, p.errorf(name, ErrUnknownAccessor, "%s", name.describe())
+	}
+	var arg string
+	if a.Argument {
+		_, err := p.expect(tokenLParen, "\"(\"")
+		if err != nil {
+			return nil, err
+		}
+		t, err := p.expect(tokenString, "string argument")
+		if err != nil {
+			return nil, err
+		}
+		arg = t.value
+		_, err = p.expect(tokenRParen, "\")\"")
+		if err != nil {
+			return nil, err
+		}
+	}
+	op := p.next()
+	var opname string
+	switch op.kind {
+	case tokenEqual, tokenNotEqual:
+		opname = op.text
+	case tokenIdent:
+		opname = op.value
+	default:
+		return nil, p.errorf(op, ErrSyntax, "expected operator,got %s", op.describe())
+	}
+	v, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if a.IP != nil {
+		return p.compileIP(a, op, opname, v)
+	}
+	return p.compileString(a, arg, op, opname, v)
+}
+
+func (p *parser) requireString(a *Accessor, v *value) (string, error) {
+	if v.list != nil || v.token.kind != tokenString {
+		return "", p.errorf(v.token, ErrTypeMismatch, "accessor %q requires string value", a.Name)
+	}
+	return v.token.value, nil
+}
+
+func (p *parser) compileString(a *Accessor, arg string, op *token, opname string, v *value) (matcher, error) {
+	var test func(string) bool
+	switch opname {
+	case "==", "!=":
+		s, err := p.requireString(a, v)
+		if err != nil {
+			return nil, err
+		}
+		equal := opname == "=="
+		test = func(data string) bool { return (data == s) == equal }
+	case "in":
+		if v.list == nil {
+			return nil, p.errorf(v.token, ErrTypeMismatch, "operator \"in\" of accessor %q requires string list", a.Name)
+		}
+		set := map[string]bool{}
+		for _, item := range v.list {
+			if item.kind != tokenString {
+				return nil, p.errorf(item, ErrTypeMismatch, "accessor %q requires string value", a.Name)
+			}
+			set[item.value] = true
+		}
+		test = func(data string) bool { return set[data] }
+	case "startsWith", "endsWith", "contains":
+		s, err := p.requireString(a, v)
+		if err != nil {
+			return nil, err
+		}
+		fn := map[string]func(string, string) bool{
+			"startsWith": strings.HasPrefix,
+			"endsWith":   strings.HasSuffix,
+			"contains":   strings.Contains,
+		}[opname]
+		test = func(data string) bool { return fn(data, s) }
+	case "matches":
+		s, err := p.requireString(a, v)
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, p.errorf(v.token, ErrInvalidRegExp, "%s", err.Error())
+		}
+		test = re.MatchString
+	case "glob":
+		s, err := p.requireString(a, v)
+		if err != nil {
+			return nil, err
+		}
+		re, err := requestmatching.CompileGlob(s, true)
+		if err != nil {
+			return nil, p.errorf(v.token, ErrInvalidGlob, "%s", err.Error())
+		}
+		test = re.MatchString
+	default:
+		return nil, p.errorf(op, ErrUnknownOperator, "%s", op.describe())
+	}
+	get := a.String
+	return func(r *http.Request) (bool, error) {
+		return test(get(r, arg)), nil
+	}, nil
+}
+
+func (p *parser) parseIPNet(t *token) (*net.IPNet, error) {
+	if t.kind != tokenString && t.kind != tokenAddress {
+		return nil, p.errorf(t, ErrTypeMismatch, "accessor \"ip\" requires ip or cidr value")
+	}
+	if strings.Contains(t.value, "/") {
+		_, ipnet, err := net.ParseCIDR(t.value)
+		if err != nil {
+			return nil, p.errorf(t, ErrInvalidIPNet, "%s", t.describe())
+		}
+		return ipnet, nil
+	}
+	ip := net.ParseIP(t.value)
+	if ip == nil {
+		return nil, p.errorf(t, ErrInvalidIPNet, "%s", t.describe())
+	}
+	bits := 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		bits = 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+func (p *parser) compileIP(a *Accessor, op *token, opname string, v *value) (matcher, error) {
+	nets := requestmatching.IPNets{}
+	switch opname {
+	case "==", "!=":
+		if v.list != nil || strings.Contains(v.token.value, "/") {
+			return nil, p.errorf(v.token, ErrTypeMismatch, "operator %q of accessor \"ip\" requires ip value", opname)
+		}
+		fallthrough
+	case "in":
+		items := v.list
+		if items == nil {
+			items = []*token{v.token}
+		}
+		for _, item := range items {
+			ipnet, err := p.parseIPNet(item)
+			if err != nil {
+				return nil, err
+			}
+			nets = append(nets, ipnet)
+		}
+	case "startsWith", "endsWith", "contains", "matches", "glob":
+		return nil, p.errorf(op, ErrTypeMismatch, "operator %q not supported by accessor \"ip\"", opname)
+	default:
+		return nil, p.errorf(op, ErrUnknownOperator, "%s", op.describe())
+	}
+	negative := opname == "!="
+	get := a.IP
+	return func(r *http.Request) (bool, error) {
+		ip := get(r)
+		result := false
+		if ip != nil {
+			for _, ipnet := range nets {
+				if ipnet.Contains(ip) {
+					result = true
+					break
+				}
+			}
+		}
+		return result != negative, nil
+	}, nil
+}
@@ -0,0 +1,210 @@
+package requestmatching
+
+import (
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/herb-go/herb/service/httpservice/requestmatching/mmdb"
+)
+
+//DefaultGeoCacheSize default max cached lookups of geo database.
+var DefaultGeoCacheSize = 4096
+
+//DefaultGeoCheckInterval default interval in which files of databases opened by OpenGeoDatabase are checked for changes.
+var DefaultGeoCheckInterval = time.Minute
+
+//GeoRecord geo information of ip
+type GeoRecord struct {
+	//Country uppercase ISO 3166-1 alpha-2 country code.
+	//Registered country will be used if country is missing.
+	Country string
+	//Continent uppercase continent code
+	Continent string
+	//ASN autonomous system number
+	ASN uint64
+	//Organization autonomous system organization
+	Organization string
+}
+
+func geoString(m map[string]interface{}, keys ...string) string {
+	for k, key := range keys {
+		if k == len(keys)-1 {
+			s, _ := m[key].(string)
+			return s
+		}
+		m, _ = m[key].(map[string]interface{})
+	}
+	return ""
+}
+
+//NewGeoRecord create geo record from data of MaxMind-format database.
+func NewGeoRecord(data interface{}) *GeoRecord {
+	rec := &GeoRecord{}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return rec
+	}
+	rec.Country = geoString(m, "country", "iso_code")
+	if rec.Country == "" {
+		rec.Country = geoString(m, "registered_country", "iso_code")
+	}
+	rec.Continent = geoString(m, "continent", "code")
+	rec.ASN, _ = m["autonomous_system_number"].(uint64)
+	rec.Organization, _ = m["autonomous_system_organization"].(string)
+	return rec
+}
+
+type geoState struct {
+	reader *mmdb.Reader
+	locker sync.Mutex
+	cache  map[string]*GeoRecord
+}
+
+//GeoDatabase MaxMind-format database file with lookup cache.
+//Database file is read into memory and replaced atomically when reloaded.
+type GeoDatabase struct {
+	//Path database file path
+	Path string
+	//CacheSize max cached lookups.
+	//Cache will be cleared when full.
+	//Lookups will not be cached if not greater than 0.
+	CacheSize int
+	//CheckInterval interval in which database file is checked for changes while looking up.
+	//File will not be checked automatically if not greater than 0.
+	CheckInterval time.Duration
+	//OnError func called when reloading failed.
+	//Previous database will be kept.
+	OnError   func(err error)
+	state     atomic.Value
+	locker    sync.Mutex
+	modTime   time.Time
+	size      int64
+	lastCheck int64
+	reloading int32
+}
+
+//Reader return current database reader.
+func (d *GeoDatabase) Reader() *mmdb.Reader {
+	s, _ := d.state.Load().(*geoState)
+	if s == nil {
+		return nil
+	}
+	return s.reader
+}
+
+//Reload reload database from file if file changed since last load.
+//Return whether database reloaded and any error if raised.
+func (d *GeoDatabase) Reload() (bool, error) {
+	d.locker.Lock()
+	defer d.locker.Unlock()
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(d.modTime) && info.Size() == d.size && d.Reader() != nil {
+		return false, nil
+	}
+	reader, err := mmdb.Open(d.Path)
+	if err != nil {
+		return false, err
+	}
+	d.state.Store(&geoState{reader: reader, cache: map[string]*GeoRecord{}})
+	d.modTime = info.ModTime()
+	d.size = info.Size()
+	return true, nil
+}
+
+func (d *GeoDatabase) checkReload() {
+	if d.CheckInterval <= 0 {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&d.lastCheck)
+	if now-last < int64(d.CheckInterval) || !atomic.CompareAndSwapInt64(&d.lastCheck, last, now) {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&d.reloading, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&d.reloading, 0)
+		_, err := d.Reload()
+		if err != nil && d.OnError != nil {
+			d.OnError(err)
+		}
+	}()
+}
+
+//Lookup lookup geo record of given ip.
+//Empty record will be returned if ip not found.
+//Return record and any error if raised.
+func (d *GeoDatabase) Lookup(ip net.IP) (*GeoRecord, error) {
+	d.checkReload()
+	s, _ := d.state.Load().(*geoState)
+	if s == nil {
+		return nil, ErrGeoDatabaseRequired
+	}
+	key := string(ip.To16())
+	if d.CacheSize > 0 {
+		s.locker.Lock()
+		rec, ok := s.cache[key]
+		s.locker.Unlock()
+		if ok {
+			return rec, nil
+		}
+	}
+	data, _, err := s.reader.Lookup(ip)
+	if err != nil {
+		return nil, err
+	}
+	rec := NewGeoRecord(data)
+	if d.CacheSize > 0 {
+		s.locker.Lock()
+		if len(s.cache) >= d.CacheSize {
+			s.cache = map[string]*GeoRecord{}
+		}
+		s.cache[key] = rec
+		s.locker.Unlock()
+	}
+	return rec, nil
+}
+
+//NewGeoDatabase create new geo database with given file path.
+//Database will be loaded immediately.
+//Return database and any error if raised.
+func NewGeoDatabase(path string) (*GeoDatabase, error) {
+	d := &GeoDatabase{
+		Path:      path,
+		CacheSize: DefaultGeoCacheSize,
+	}
+	_, err := d.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+var geoDatabasesLocker sync.Mutex
+var geoDatabases = map[string]*GeoDatabase{}
+
+//OpenGeoDatabase return database of given file path shared by patterns.
+//Database file will be checked in DefaultGeoCheckInterval.
+//Return database and any error if raised.
+func OpenGeoDatabase(path string) (*GeoDatabase, error) {
+	geoDatabasesLocker.Lock()
+	defer geoDatabasesLocker.Unlock()
+	d := geoDatabases[path]
+	if d != nil {
+		return d, nil
+	}
+	d, err := NewGeoDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	d.CheckInterval = DefaultGeoCheckInterval
+	geoDatabases[path] = d
+	return d, nil
+}
@@ -0,0 +1,202 @@
+package requestmatching
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+)
+
+//Continents known continent codes.
+var Continents = map[string]bool{
+	"AF": true,
+	"AN": true,
+	"AS": true,
+	"EU": true,
+	"NA": true,
+	"OC": true,
+	"SA": true,
+}
+
+//GeoIPs geoip pattern which matches client ip by country,continent or autonomous system.
+type GeoIPs struct {
+	//Database country or city database
+	Database *GeoDatabase
+	//ASNDatabase asn database.
+	//Database will be used if nil.
+	ASNDatabase *GeoDatabase
+	//Countries uppercase country codes
+	Countries map[string]bool
+	//Continents uppercase continent codes
+	Continents map[string]bool
+	//ASNs autonomous system numbers
+	ASNs map[uint64]bool
+}
+
+//Len return records count.
+func (g *GeoIPs) Len() int {
+	return len(g.Countries) + len(g.Continents) + len(g.ASNs)
+}
+
+//AddCountry add ISO 3166-1 alpha-2 country code to pattern.
+//Return any error if raised.
+func (g *GeoIPs) AddCountry(code string) error {
+	c := strings.ToUpper(strings.TrimSpace(code))
+	if len(c) != 2 || c[0] < 'A' || c[0] > 'Z' || c[1] < 'A' || c[1] > 'Z' {
+		return fmt.Errorf("%w : \"%s\"", ErrGeoIPNotValidated, code)
+	}
+	g.Countries[c] = true
+	return nil
+}
+
+//AddContinent add continent code to pattern.
+//Return any error if raised.
+func (g *GeoIPs) AddContinent(code string) error {
+	c := strings.ToUpper(strings.TrimSpace(code))
+	if !Continents[c] {
+		return fmt.Errorf("%w : \"%s\"", ErrGeoIPNotValidated, code)
+	}
+	g.Continents[c] = true
+	return nil
+}
+
+//AddASN add autonomous system number in "AS13335" or "13335" form to pattern.
+//Return any error if raised.
+func (g *GeoIPs) AddASN(asn string) error {
+	s := strings.TrimSpace(asn)
+	if len(s) > 2 && strings.EqualFold(s[:2], "AS") {
+		s = s[2:]
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w : \"%s\"", ErrGeoIPNotValidated, asn)
+	}
+	g.ASNs[n] = true
+	return nil
+}
+
+//MatchRequest match request.
+//Return result and any error if raised.
+func (g *GeoIPs) MatchRequest(r *http.Request) (bool, error) {
+	if g.Len() == 0 {
+		return true, nil
+	}
+	ip := GetRequestIP(r)
+	if ip == nil {
+		return false, nil
+	}
+	if len(g.Countries) > 0 || len(g.Continents) > 0 {
+		if g.Database == nil {
+			return false, ErrGeoDatabaseRequired
+		}
+		rec, err := g.Database.Lookup(ip)
+		if err != nil {
+			return false, err
+		}
+		if g.Countries[rec.Country] || g.Continents[rec.Continent] {
+			return true, nil
+		}
+	}
+	if len(g.ASNs) > 0 {
+		db := g.ASNDatabase
+		if db == nil {
+			db = g.Database
+		}
+		if db == nil {
+			return false, ErrGeoDatabaseRequired
+		}
+		rec, err := db.Lookup(ip)
+		if err != nil {
+			return false, err
+		}
+		if g.ASNs[rec.ASN] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//NewGeoIPs create new geoip pattern with given database.
+func NewGeoIPs(database *GeoDatabase) *GeoIPs {
+	return &GeoIPs{
+		Database:   database,
+		Countries:  map[string]bool{},
+		Continents: map[string]bool{},
+		ASNs:       map[uint64]bool{},
+	}
+}
+
+//GeoIPConfig geoip pattern config
+type GeoIPConfig struct {
+	//CountryList ISO 3166-1 alpha-2 country codes
+	CountryList []string
+	//ContinentList continent codes,AF,AN,AS,EU,NA,OC or SA
+	ContinentList []string
+	//ASNList autonomous system numbers in "AS13335" or "13335" form
+	ASNList []string
+	//GeoIPDatabase path of MaxMind-format country or city database
+	GeoIPDatabase string
+	//ASNDatabase path of MaxMind-format asn database.
+	//GeoIPDatabase will be used if empty.
+	ASNDatabase string
+}
+
+//CreateGeoIPs create geoip pattern with config.
+//Databases are opened by OpenGeoDatabase and shared.
+//Return pattern and any error if raised.
+func (c *GeoIPConfig) CreateGeoIPs() (*GeoIPs, error) {
+	g := NewGeoIPs(nil)
+	for _, v := range c.CountryList {
+		if err := g.AddCountry(v); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range c.ContinentList {
+		if err := g.AddContinent(v); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range c.ASNList {
+		if err := g.AddASN(v); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if c.GeoIPDatabase != "" {
+		g.Database, err = OpenGeoDatabase(c.GeoIPDatabase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.ASNDatabase != "" {
+		g.ASNDatabase, err = OpenGeoDatabase(c.ASNDatabase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if (len(g.Countries) > 0 || len(g.Continents) > 0) && g.Database == nil {
+		return nil, ErrGeoDatabaseRequired
+	}
+	if len(g.ASNs) > 0 && g.Database == nil && g.ASNDatabase == nil {
+		return nil, ErrGeoDatabaseRequired
+	}
+	return g, nil
+}
+
+//NewGeoIPConditionFactory create new geoip condition factory.
+func NewGeoIPConditionFactory() middlewarefactory.ConditionFactory {
+	return func(loader func(v interface{}) error) (middlewarefactory.Condition, error) {
+		c := &GeoIPConfig{}
+		err := loader(c)
+		if err != nil {
+			return nil, err
+		}
+		g, err := c.CreateGeoIPs()
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+	}
+}
@@ -0,0 +1,243 @@
+package requestmatching
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/middleware/middlewarefactory"
+	"github.com/herb-go/herb/service/httpservice/requestmatching/internal/mmdbwriter"
+	"github.com/herb-go/herb/service/httpservice/requestmatching/mmdb"
+)
+
+func writeTestGeoDatabase(t *testing.T, path string, records map[string]interface{}) {
+	w := mmdbwriter.New("Test")
+	for network, v := range records {
+		err := w.Insert(network, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := w.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func geoCountry(country string, continent string) map[string]interface{} {
+	return map[string]interface{}{
+		"country":   map[string]interface{}{"iso_code": country},
+		"continent": map[string]interface{}{"code": continent},
+	}
+}
+
+func geoASN(asn uint32, org string) map[string]interface{} {
+	return map[string]interface{}{
+		"autonomous_system_number":       asn,
+		"autonomous_system_organization": org,
+	}
+}
+
+func newGeoRequest(remoteAddr string) *http.Request {
+	r, _ := http.NewRequest("GET", "http://example.com/", nil)
+	r.RemoteAddr = remoteAddr
+	return r
+}
+
+func TestGeoIPs(t *testing.T) {
+	dir := t.TempDir()
+	countryPath := filepath.Join(dir, "country.mmdb")
+	asnPath := filepath.Join(dir, "asn.mmdb")
+	writeTestGeoDatabase(t, countryPath, map[string]interface{}{
+		"1.0.0.0/8":     geoCountry("CN", "AS"),
+		"2.0.0.0/8":     geoCountry("DE", "EU"),
+		"2001:db8::/32": geoCountry("US", "NA"),
+		"3.0.0.0/8": map[string]interface{}{
+			"registered_country": map[string]interface{}{"iso_code": "FR"},
+			"continent":          map[string]interface{}{"code": "EU"},
+		},
+	})
+	writeTestGeoDatabase(t, asnPath, map[string]interface{}{
+		"1.1.0.0/16": geoASN(13335, "Example"),
+	})
+	db, err := NewGeoDatabase(countryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := db.Lookup(GetRequestIP(newGeoRequest("3.0.0.1:1")))
+	if err != nil || rec.Country != "FR" || rec.Continent != "EU" {
+		t.Fatal(rec, err)
+	}
+	asndb, err := NewGeoDatabase(asnPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGeoIPs(db)
+	if !MustMatch(newGeoRequest("9.9.9.9:1"), g) {
+		t.Fatal(g)
+	}
+	for _, v := range []string{"", "C", "CHN", "1a"} {
+		if !errors.Is(g.AddCountry(v), ErrGeoIPNotValidated) {
+			t.Fatal(v)
+		}
+	}
+	if !errors.Is(g.AddContinent("XX"), ErrGeoIPNotValidated) || !errors.Is(g.AddASN("ASX"), ErrGeoIPNotValidated) {
+		t.Fatal(g)
+	}
+	g.AddCountry("cn")
+	g.AddContinent("na")
+	g.AddASN("AS13335")
+	g.ASNDatabase = asndb
+	for addr, expected := range map[string]bool{
+		"1.2.3.4:1":         true,
+		"[2001:db8::1]:1":   true,
+		"2.2.2.2:1":         false,
+		"9.9.9.9:1":         false,
+		"invalid":           false,
+		"[::ffff:1.0.0.1]:": true,
+	} {
+		if MustMatch(newGeoRequest(addr), g) != expected {
+			t.Fatal(addr)
+		}
+	}
+	g = NewGeoIPs(db)
+	g.AddASN("13335")
+	if MustMatch(newGeoRequest("1.1.1.1:1"), g) {
+		t.Fatal(g)
+	}
+	g.ASNDatabase = asndb
+	if !MustMatch(newGeoRequest("1.1.1.1:1"), g) || MustMatch(newGeoRequest("1.2.1.1:1"), g) {
+		t.Fatal(g)
+	}
+	g = NewGeoIPs(nil)
+	g.AddCountry("CN")
+	_, err = g.MatchRequest(newGeoRequest("1.1.1.1:1"))
+	if err != ErrGeoDatabaseRequired {
+		t.Fatal(err)
+	}
+}
+
+func TestGeoDatabaseReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	writeTestGeoDatabase(t, path, map[string]interface{}{"1.0.0.0/8": geoCountry("CN", "AS")})
+	db, err := NewGeoDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ip := GetRequestIP(newGeoRequest("1.0.0.1:1"))
+	rec, err := db.Lookup(ip)
+	if err != nil || rec.Country != "CN" {
+		t.Fatal(rec, err)
+	}
+	cached, _ := db.Lookup(ip)
+	if cached != rec {
+		t.Fatal(cached)
+	}
+	ok, err := db.Reload()
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	writeTestGeoDatabase(t, path, map[string]interface{}{"1.0.0.0/8": geoCountry("JP", "AS")})
+	os.Chtimes(path, time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+	ok, err = db.Reload()
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	rec, err = db.Lookup(ip)
+	if err != nil || rec.Country != "JP" {
+		t.Fatal(rec, err)
+	}
+	os.WriteFile(path, []byte("broken"), 0644)
+	ok, err = db.Reload()
+	if ok || !errors.Is(err, mmdb.ErrInvalidDatabase) {
+		t.Fatal(ok, err)
+	}
+	rec, err = db.Lookup(ip)
+	if err != nil || rec.Country != "JP" {
+		t.Fatal(rec, err)
+	}
+	writeTestGeoDatabase(t, path, map[string]interface{}{"1.0.0.0/8": geoCountry("KR", "AS")})
+	os.Chtimes(path, time.Now().Add(2*time.Hour), time.Now().Add(2*time.Hour))
+	db.CheckInterval = time.Millisecond
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		time.Sleep(2 * time.Millisecond)
+		rec, err = db.Lookup(ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Country == "KR" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(rec)
+		}
+	}
+}
+
+func TestGeoIPConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	writeTestGeoDatabase(t, path, map[string]interface{}{
+		"1.0.0.0/8": map[string]interface{}{
+			"country":                  map[string]interface{}{"iso_code": "CN"},
+			"continent":                map[string]interface{}{"code": "AS"},
+			"autonomous_system_number": uint32(4134),
+		},
+	})
+	_, err := (&PatternConfig{CountryList: []string{"CN"}}).CreatePattern()
+	if err != ErrGeoDatabaseRequired {
+		t.Fatal(err)
+	}
+	_, err = (&PatternConfig{CountryList: []string{"CN"}, GeoIPDatabase: filepath.Join(path, "notexist")}).CreatePattern()
+	if err == nil {
+		t.Fatal(err)
+	}
+	p := MustCreatePattern(&PatternConfig{ContinentList: []string{"AS"}, ASNList: []string{"AS1"}, GeoIPDatabase: path})
+	if !MustMatch(newGeoRequest("1.0.0.1:1"), p) || MustMatch(newGeoRequest("2.0.0.1:1"), p) {
+		t.Fatal(p)
+	}
+	p = MustCreatePattern(&PatternConfig{ASNList: []string{"4134"}, GeoIPDatabase: path, MethodList: []string{"POST"}})
+	if MustMatch(newGeoRequest("1.0.0.1:1"), p) {
+		t.Fatal(p)
+	}
+	p2 := MustCreatePattern(&PatternConfig{ASNList: []string{"4134"}, ASNDatabase: path})
+	if p.(*PlainPattern).GeoIPs.Database != p2.(*PlainPattern).GeoIPs.ASNDatabase {
+		t.Fatal(p2)
+	}
+	ctx := middlewarefactory.NewContext()
+	ctx.RegisterConditionFactory("geoip", NewGeoIPConditionFactory())
+	c, err := ctx.CreateCondition("geoip", func(v interface{}) error {
+		v.(*GeoIPConfig).CountryList = []string{"CN"}
+		v.(*GeoIPConfig).GeoIPDatabase = path
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := c.MatchRequest(newGeoRequest("1.0.0.1:1"))
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	c, err = ctx.CreateCondition("geoip", func(v interface{}) error {
+		v.(*GeoIPConfig).CountryList = []string{"CN"}
+		return nil
+	})
+	if c != nil || err != ErrGeoDatabaseRequired {
+		t.Fatal(c, err)
+	}
+	m, err := (&FiltersConfig{{CountryList: []string{"CN"}, GeoIPDatabase: path}, {MethodList: []string{"GET"}}}).CreateMatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := m.MatchIDs(newGeoRequest("1.0.0.1:1"))
+	if err != nil || len(ids) != 2 {
+		t.Fatal(ids, err)
+	}
+}
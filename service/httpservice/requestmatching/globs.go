@@ -0,0 +1,135 @@
+package requestmatching
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+//CompileGlob compile glob pattern to regexp.
+//"*" matches any characters except "/",
+//"**" matches any characters including "/",
+//"/**/" matches zero or more path segments,
+//"?" matches one character except "/",
+//"{a,b}" matches any alternative.
+//Return regexp and any error if raised.
+func CompileGlob(glob string, caseSensitive bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	if !caseSensitive {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("^")
+	depth := 0
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			if (i == 1 || glob[i-2] == '/') && i+1 < len(glob) && glob[i+1] == '/' {
+				b.WriteString("(?:.*/)?")
+				i++
+			} else {
+				b.WriteString(".*")
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '{':
+			depth++
+			b.WriteString("(?:")
+		case c == '}' && depth > 0:
+			depth--
+			b.WriteString(")")
+		case c == ',' && depth > 0:
+			b.WriteString("|")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%w : \"%s\"", ErrGlobNotValidated, glob)
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+//GlobData glob data struct
+type GlobData struct {
+	//Host host pattern.
+	//Any host matches if empty.
+	Host string
+	//Glob path glob pattern
+	Glob string
+	//RegExp compiled glob
+	RegExp *regexp.Regexp
+}
+
+//Globs path globs pattern
+type Globs []*GlobData
+
+func (g *Globs) add(glob string, caseSensitive bool) error {
+	if glob == "" {
+		return fmt.Errorf("%w : \"%s\"", ErrGlobNotValidated, glob)
+	}
+	host, path := splitURL(glob, true)
+	re, err := CompileGlob(path, caseSensitive)
+	if err != nil {
+		return err
+	}
+	*g = append(*g, &GlobData{Host: host, Glob: path, RegExp: re})
+	return nil
+}
+
+//Add add glob to pattern.
+//Any string before first "/" will be used as host pattern.
+//Glob will be matched case-insensitively.
+//Return any error if raised.
+func (g *Globs) Add(glob string) error {
+	return g.add(glob, false)
+}
+
+//AddCaseSensitive add glob to pattern which will be matched case-sensitively.
+//Return any error if raised.
+func (g *Globs) AddCaseSensitive(glob string) error {
+	return g.add(glob, true)
+}
+
+//MatchRequest match request.
+//Return result and any error if raised.
+func (g *Globs) MatchRequest(r *http.Request) (bool, error) {
+	if len(*g) == 0 {
+		return true, nil
+	}
+	host, hostname := requestHosts(r)
+	for _, v := range *g {
+		if v.Host != "" && !matchHostPattern(v.Host, host, hostname) {
+			continue
+		}
+		if v.RegExp.MatchString(r.URL.Path) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//MatchRequestAll match request with all globs.
+//Return result and any error if raised.
+func (g *Globs) MatchRequestAll(r *http.Request) (bool, error) {
+	host, hostname := requestHosts(r)
+	for _, v := range *g {
+		if v.Host != "" && !matchHostPattern(v.Host, host, hostname) {
+			return false, nil
+		}
+		if !v.RegExp.MatchString(r.URL.Path) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+//NewGlobs create new globs pattern.
+func NewGlobs() *Globs {
+	return &Globs{}
+}
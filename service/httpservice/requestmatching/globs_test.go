@@ -0,0 +1,80 @@
+package requestmatching
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCompileGlob(t *testing.T) {
+	for glob, paths := range map[string]map[string]bool{
+		"/static/**/*.js": {"/static/a.js": true, "/static/a/b/c.js": true, "/static/a.css": false, "/static.js": false, "/STATIC/A.JS": true},
+		"/a/*":            {"/a/b": true, "/a/": true, "/a/b/c": false},
+		"/a/**":           {"/a/b": true, "/a/b/c": true, "/b": false},
+		"/?.txt":          {"/a.txt": true, "/ab.txt": false, "//.txt": false},
+		"/{a,b}/c":        {"/a/c": true, "/b/c": true, "/d/c": false},
+		"/a.b":            {"/a.b": true, "/axb": false},
+	} {
+		re, err := CompileGlob(glob, false)
+		if err != nil {
+			t.Fatal(glob, err)
+		}
+		for path, expected := range paths {
+			if re.MatchString(path) != expected {
+				t.Fatal(glob, path)
+			}
+		}
+	}
+	re, err := CompileGlob("/A/*", true)
+	if err != nil || re.MatchString("/a/b") || !re.MatchString("/A/b") {
+		t.Fatal(re, err)
+	}
+	_, err = CompileGlob("/{a,b", false)
+	if !errors.Is(err, ErrGlobNotValidated) {
+		t.Fatal(err)
+	}
+}
+
+func TestGlobs(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://www.example.com:8080/Static/js/app.js", nil)
+	g := NewGlobs()
+	if !MustMatch(r, g) {
+		t.Fatal(g)
+	}
+	if !errors.Is(g.Add(""), ErrGlobNotValidated) {
+		t.Fatal(g)
+	}
+	g.Add("/static/*.js")
+	if MustMatch(r, g) {
+		t.Fatal(g)
+	}
+	g.Add("other.com/static/**")
+	if MustMatch(r, g) {
+		t.Fatal(g)
+	}
+	g.Add("*.example.com/static/**/*.js")
+	if !MustMatch(r, g) {
+		t.Fatal(g)
+	}
+	ok, err := g.MatchRequestAll(r)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	g = NewGlobs()
+	g.AddCaseSensitive("/static/**")
+	if MustMatch(r, g) {
+		t.Fatal(g)
+	}
+	g.AddCaseSensitive("/Static/**")
+	if !MustMatch(r, g) {
+		t.Fatal(g)
+	}
+	p := MustCreatePattern(&PatternConfig{GlobList: []string{"/static/**"}, CaseSensitive: true})
+	if MustMatch(r, p) {
+		t.Fatal(p)
+	}
+	p = MustCreatePattern(&PatternConfig{GlobList: []string{"/static/**"}})
+	if !MustMatch(r, p) {
+		t.Fatal(p)
+	}
+}
@@ -43,6 +43,17 @@ func (h *Headers) MatchRequest(r *http.Request) (bool, error) {
 	return false, nil
 }
 
+//MatchRequestAll match request with all headers.
+//Return result and any error if raised.
+func (h *Headers) MatchRequestAll(r *http.Request) (bool, error) {
+	for k := range *h {
+		if r.Header.Get((*h)[k].Key) != (*h)[k].Value {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 //NewHeaders create new headers pattern.
 func NewHeaders() *Headers {
 	return &Headers{}
@@ -0,0 +1,31 @@
+package requestmatching
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/herb-go/herb/service"
+)
+
+//requestHosts return lowercased request host and hostname without port.
+func requestHosts(r *http.Request) (string, string) {
+	host := strings.ToLower(r.Host)
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return host, host
+	}
+	return host, hostname
+}
+
+//matchHostPattern check if request host or hostname matches given host pattern.
+//Pattern supports service.HostPattern wildcards.
+func matchHostPattern(pattern string, host string, hostname string) bool {
+	p := service.HostPattern(pattern)
+	return p.Match(host) || (hostname != host && p.Match(hostname))
+}
+
+//isHostWildcard check if host key is wildcard pattern such as ".example.com" or "*example.com".
+func isHostWildcard(h string) bool {
+	return h != "" && (h[0] == '.' || h[0] == '*')
+}
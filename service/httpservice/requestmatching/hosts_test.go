@@ -0,0 +1,97 @@
+package requestmatching
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHostPatterns(t *testing.T) {
+	for host, expected := range map[string]bool{
+		"www.example.com":      true,
+		"WWW.Example.com:8080": true,
+		"example.com":          false,
+		"www.other.com":        false,
+	} {
+		r, _ := http.NewRequest("GET", "http://127.0.0.1/A/b.PHP", nil)
+		r.Host = host
+		for _, p := range []Pattern{
+			MustCreatePattern(&PatternConfig{URLList: []string{"*.example.com/a/b.php"}}),
+			MustCreatePattern(&PatternConfig{PrefixList: []string{".example.com/a/"}}),
+			MustCreatePattern(&PatternConfig{SuffixList: []string{"*.example.com/.php"}}),
+		} {
+			if MustMatch(r, p) != expected {
+				t.Fatal(host, p)
+			}
+		}
+	}
+	r, _ := http.NewRequest("GET", "http://example.com:8080/a", nil)
+	if !MustMatch(r, MustCreatePattern(&PatternConfig{URLList: []string{"example.com/a"}})) {
+		t.Fatal(r)
+	}
+	if !MustMatch(r, MustCreatePattern(&PatternConfig{URLList: []string{"example.com:8080/a"}})) {
+		t.Fatal(r)
+	}
+}
+
+func TestHostKeys(t *testing.T) {
+	for _, v := range []struct {
+		host     string
+		path     string
+		expected bool
+	}{
+		{"www.example.com", "/exact", true},
+		{"www.example.com:8080", "/exact", true},
+		{"www.example.com", "/wildcard", true},
+		{"www.example.com", "/other", false},
+		{"other.com", "/exact", false},
+		{"other.com", "/any", true},
+	} {
+		r, _ := http.NewRequest("GET", "http://127.0.0.1"+v.path, nil)
+		r.Host = v.host
+		for _, p := range []Pattern{
+			MustCreatePattern(&PatternConfig{URLList: []string{"www.example.com/exact", "*.example.com/wildcard", "other.com/other", "/any"}}),
+			MustCreatePattern(&PatternConfig{PrefixList: []string{"www.example.com/exact", ".example.com/wildcard", "other.com/other", "/any"}}),
+			MustCreatePattern(&PatternConfig{SuffixList: []string{"www.example.com/exact", "*example.com/wildcard", "other.com/other", "/any"}}),
+		} {
+			if MustMatch(r, p) != v.expected {
+				t.Fatal(v.host, v.path, p)
+			}
+		}
+	}
+}
+
+func TestCaseSensitive(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://example.com/A/b.PHP", nil)
+	for _, c := range []*PatternConfig{
+		{URLList: []string{"/a/b.php"}},
+		{PrefixList: []string{"/a/"}},
+		{SuffixList: []string{"/.php"}},
+	} {
+		if !MustMatch(r, MustCreatePattern(c)) {
+			t.Fatal(c)
+		}
+		c.CaseSensitive = true
+		if MustMatch(r, MustCreatePattern(c)) {
+			t.Fatal(c)
+		}
+	}
+	for _, c := range []*PatternConfig{
+		{URLList: []string{"/A/b.PHP"}, CaseSensitive: true},
+		{PrefixList: []string{"EXAMPLE.com/A/"}, CaseSensitive: true},
+		{SuffixList: []string{"/.PHP"}, CaseSensitive: true},
+	} {
+		if !MustMatch(r, MustCreatePattern(c)) {
+			t.Fatal(c)
+		}
+	}
+	p := NewPaths()
+	p.AddCaseSensitive("/A/b.PHP")
+	ok, err := p.MatchRequestCaseSensitive(r)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	ok, err = p.MatchRequest(r)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+}
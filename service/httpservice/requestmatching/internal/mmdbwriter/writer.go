@@ -0,0 +1,377 @@
+//Package mmdbwriter provide MaxMind DB format writer used to build test databases.
+package mmdbwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+)
+
+//ErrInvalidNetwork error raised if network inserted is invalid.
+var ErrInvalidNetwork = errors.New("mmdbwriter:invalid network")
+
+//ErrUnsupportedType error raised if value type can not be encoded.
+var ErrUnsupportedType = errors.New("mmdbwriter:unsupported value type")
+
+//ErrDatabaseTooLarge error raised if records overflow record size.
+var ErrDatabaseTooLarge = errors.New("mmdbwriter:database too large for record size")
+
+//ErrInvalidRecordSize error raised if record size is not 24,28 or 32.
+var ErrInvalidRecordSize = errors.New("mmdbwriter:invalid record size")
+
+//Data types defined by MaxMind DB format.
+const (
+	typeString  = 2
+	typeDouble  = 3
+	typeBytes   = 4
+	typeUint16  = 5
+	typeUint32  = 6
+	typeMap     = 7
+	typeInt32   = 8
+	typeUint64  = 9
+	typeUint128 = 10
+	typeArray   = 11
+	typeBool    = 14
+	typeFloat   = 15
+)
+
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+const dataSeparatorSize = 16
+
+type writerNode struct {
+	children [2]*writerNode
+	value    interface{}
+	leaf     bool
+}
+
+//Writer in-memory IPv6 database writer used to build test databases.
+//IPv4 networks are stored in IPv4-compatible range "::/96".
+type Writer struct {
+	//DatabaseType database type
+	DatabaseType string
+	//RecordSize record size in bits,24,28 or 32.
+	//28 will be used if 0.
+	RecordSize int
+	//Languages locale codes
+	Languages []string
+	//Description descriptions by language
+	Description map[string]string
+	//BuildEpoch database build time in unix seconds
+	BuildEpoch int64
+	root       *writerNode
+}
+
+//Insert insert value of given ip or cidr network.
+//Value can be string,[]byte,bool,float64,float32,uint16,uint32,uint64,uint,int32,int,*big.Int,
+//map[string]interface{},map[string]string,[]interface{} or []string.
+//Networks inserted later override overlapped parts of earlier ones.
+//Return any error if raised.
+func (w *Writer) Insert(network string, value interface{}) error {
+	var ipnet *net.IPNet
+	if strings.Contains(network, "/") {
+		var err error
+		_, ipnet, err = net.ParseCIDR(network)
+		if err != nil {
+			return ErrInvalidNetwork
+		}
+	} else {
+		ip := net.ParseIP(network)
+		if ip == nil {
+			return ErrInvalidNetwork
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ipnet = &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+		} else {
+			ipnet = &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+		}
+	}
+	return w.InsertNet(ipnet, value)
+}
+
+//InsertNet insert value of given network.
+//Return any error if raised.
+func (w *Writer) InsertNet(ipnet *net.IPNet, value interface{}) error {
+	_, err := encodeValue(&bytes.Buffer{}, value)
+	if err != nil {
+		return err
+	}
+	ones, bits := ipnet.Mask.Size()
+	addr := make([]byte, 16)
+	switch {
+	case bits == 32 && ipnet.IP.To4() != nil:
+		copy(addr[12:], ipnet.IP.To4())
+		ones += 96
+	case bits == 128 && len(ipnet.IP) == 16:
+		copy(addr, ipnet.IP)
+	default:
+		return ErrInvalidNetwork
+	}
+	if ones == 0 {
+		return ErrInvalidNetwork
+	}
+	node := w.root
+	for i := 0; i < ones; i++ {
+		if node.leaf {
+			node.children[0] = &writerNode{value: node.value, leaf: true}
+			node.children[1] = &writerNode{value: node.value, leaf: true}
+			node.value = nil
+			node.leaf = false
+		}
+		bit := int(addr[i>>3]>>(7-uint(i&7))) & 1
+		if node.children[bit] == nil {
+			node.children[bit] = &writerNode{}
+		}
+		node = node.children[bit]
+	}
+	node.children = [2]*writerNode{}
+	node.value = value
+	node.leaf = true
+	return nil
+}
+
+//Bytes encode database.
+//Return database data and any error if raised.
+func (w *Writer) Bytes() ([]byte, error) {
+	recordSize := w.RecordSize
+	if recordSize == 0 {
+		recordSize = 28
+	}
+	if recordSize != 24 && recordSize != 28 && recordSize != 32 {
+		return nil, ErrInvalidRecordSize
+	}
+	nodes := []*writerNode{w.root}
+	index := map[*writerNode]int{w.root: 0}
+	for k := 0; k < len(nodes); k++ {
+		for _, c := range nodes[k].children {
+			if c != nil && !c.leaf {
+				index[c] = len(nodes)
+				nodes = append(nodes, c)
+			}
+		}
+	}
+	count := len(nodes)
+	data := &bytes.Buffer{}
+	offsets := map[string]int{}
+	records := make([]uint64, 0, count*2)
+	for _, n := range nodes {
+		for _, c := range n.children {
+			var record int
+			switch {
+			case c == nil:
+				record = count
+			case !c.leaf:
+				record = index[c]
+			default:
+				encoded, err := encodeValue(&bytes.Buffer{}, c.value)
+				if err != nil {
+					return nil, err
+				}
+				key := string(encoded)
+				offset, ok := offsets[key]
+				if !ok {
+					offset = data.Len()
+					offsets[key] = offset
+					data.Write(encoded)
+				}
+				record = count + dataSeparatorSize + offset
+			}
+			if uint64(record) >= uint64(1)<<uint(recordSize) {
+				return nil, ErrDatabaseTooLarge
+			}
+			records = append(records, uint64(record))
+		}
+	}
+	out := &bytes.Buffer{}
+	for k := 0; k < len(records); k += 2 {
+		left, right := records[k], records[k+1]
+		switch recordSize {
+		case 24:
+			out.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)})
+		case 28:
+			out.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(left>>24)<<4 | byte(right>>24), byte(right >> 16), byte(right >> 8), byte(right)})
+		default:
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint32(b, uint32(left))
+			binary.BigEndian.PutUint32(b[4:], uint32(right))
+			out.Write(b)
+		}
+	}
+	out.Write(make([]byte, dataSeparatorSize))
+	out.Write(data.Bytes())
+	out.Write(metadataMarker)
+	languages := make([]interface{}, len(w.Languages))
+	for k, v := range w.Languages {
+		languages[k] = v
+	}
+	description := map[string]interface{}{}
+	for k, v := range w.Description {
+		description[k] = v
+	}
+	_, err := encodeValue(out, map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(w.BuildEpoch),
+		"database_type":               w.DatabaseType,
+		"description":                 description,
+		"ip_version":                  uint16(6),
+		"languages":                   languages,
+		"node_count":                  uint32(count),
+		"record_size":                 uint16(recordSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+//WriteTo write encoded database to writer.
+//Return bytes written and any error if raised.
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+	data, err := w.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	n, err := out.Write(data)
+	return int64(n), err
+}
+
+//New create new writer with given database type.
+func New(databaseType string) *Writer {
+	return &Writer{
+		DatabaseType: databaseType,
+		root:         &writerNode{},
+	}
+}
+
+func writeControl(buf *bytes.Buffer, kind int, size int) {
+	var sizeBits byte
+	var extra []byte
+	switch {
+	case size < 29:
+		sizeBits = byte(size)
+	case size < 285:
+		sizeBits = 29
+		extra = []byte{byte(size - 29)}
+	case size < 65821:
+		sizeBits = 30
+		v := size - 285
+		extra = []byte{byte(v >> 8), byte(v)}
+	default:
+		sizeBits = 31
+		v := size - 65821
+		extra = []byte{byte(v >> 16), byte(v >> 8), byte(v)}
+	}
+	if kind > typeMap {
+		buf.WriteByte(sizeBits)
+		buf.WriteByte(byte(kind - 7))
+	} else {
+		buf.WriteByte(byte(kind<<5) | sizeBits)
+	}
+	buf.Write(extra)
+}
+
+func writeUint(buf *bytes.Buffer, kind int, v uint64) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	writeControl(buf, kind, len(b))
+	buf.Write(b)
+}
+
+//encodeValue encode value to buffer.
+//Return buffer bytes and any error if raised.
+func encodeValue(buf *bytes.Buffer, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		writeControl(buf, typeString, len(v))
+		buf.WriteString(v)
+	case []byte:
+		writeControl(buf, typeBytes, len(v))
+		buf.Write(v)
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		writeControl(buf, typeBool, size)
+	case float64:
+		writeControl(buf, typeDouble, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case float32:
+		writeControl(buf, typeFloat, 4)
+		binary.Write(buf, binary.BigEndian, math.Float32bits(v))
+	case uint16:
+		writeUint(buf, typeUint16, uint64(v))
+	case uint32:
+		writeUint(buf, typeUint32, uint64(v))
+	case uint64:
+		writeUint(buf, typeUint64, v)
+	case uint:
+		writeUint(buf, typeUint64, uint64(v))
+	case int32:
+		if v < 0 {
+			writeControl(buf, typeInt32, 4)
+			binary.Write(buf, binary.BigEndian, uint32(v))
+		} else {
+			writeUint(buf, typeInt32, uint64(v))
+		}
+	case int:
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return nil, ErrUnsupportedType
+		}
+		return encodeValue(buf, int32(v))
+	case *big.Int:
+		b := v.Bytes()
+		if v.Sign() < 0 || len(b) > 16 {
+			return nil, ErrUnsupportedType
+		}
+		writeControl(buf, typeUint128, len(b))
+		buf.Write(b)
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for key, s := range v {
+			m[key] = s
+		}
+		return encodeValue(buf, m)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		writeControl(buf, typeMap, len(keys))
+		for _, key := range keys {
+			encodeValue(buf, key)
+			_, err := encodeValue(buf, v[key])
+			if err != nil {
+				return nil, err
+			}
+		}
+	case []string:
+		a := make([]interface{}, len(v))
+		for k, s := range v {
+			a[k] = s
+		}
+		return encodeValue(buf, a)
+	case []interface{}:
+		writeControl(buf, typeArray, len(v))
+		for _, item := range v {
+			_, err := encodeValue(buf, item)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, ErrUnsupportedType
+	}
+	return buf.Bytes(), nil
+}
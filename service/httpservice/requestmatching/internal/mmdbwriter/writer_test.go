@@ -0,0 +1,42 @@
+package mmdbwriter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestInvalid(t *testing.T) {
+	w := New("Test")
+	for _, network := range []string{"", "::/0", "10.0.0.0/33", "a.b.c.d"} {
+		if !errors.Is(w.Insert(network, "a"), ErrInvalidNetwork) {
+			t.Fatal(network)
+		}
+	}
+	if !errors.Is(w.Insert("10.0.0.0/8", struct{}{}), ErrUnsupportedType) {
+		t.Fatal(w)
+	}
+	w.RecordSize = 16
+	if _, err := w.Bytes(); !errors.Is(err, ErrInvalidRecordSize) {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	w := New("Test")
+	if err := w.Insert("10.0.0.0/8", "a"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := w.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.NewBuffer(nil)
+	n, err := w.WriteTo(buf)
+	if err != nil || n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal(n, err)
+	}
+	if !bytes.Contains(data, metadataMarker) {
+		t.Fatal(data)
+	}
+}
@@ -29,6 +29,18 @@ func (k *Keywords) MatchRequest(r *http.Request) (bool, error) {
 	return false, nil
 }
 
+//MatchRequestAll match request with all keywords.
+//Return result and any error if raised.
+func (k *Keywords) MatchRequestAll(r *http.Request) (bool, error) {
+	uri := strings.ToLower(r.URL.RequestURI())
+	for i := range *k {
+		if strings.Index(uri, (*k)[i]) < 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 //NewKeywords create new keywords
 func NewKeywords() *Keywords {
 	return &Keywords{}
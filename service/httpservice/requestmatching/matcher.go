@@ -0,0 +1,531 @@
+package requestmatching
+
+import (
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//allModeRule rule combining all records within field
+type allModeRule struct {
+	rule  int
+	terms []int
+}
+
+//directRule rule field evaluated without index
+type directRule struct {
+	rule    int
+	pattern *PlainPattern
+	field   *field
+}
+
+//fieldIndex index of one plain pattern field.
+//Distinct records of all rules are stored as terms.
+//Field without lookup func is evaluated rule by rule.
+type fieldIndex struct {
+	name       string
+	termIDs    map[string]int
+	termRules  [][]int
+	allRules   []*allModeRule
+	direct     []*directRule
+	configured bitset
+	lookup     func(r *http.Request, emit func(term int))
+}
+
+//addDirectRule add rule evaluated without index to field.
+func (f *fieldIndex) addDirectRule(rule int, p *PlainPattern, pattern Pattern, size int) {
+	if size == 0 {
+		return
+	}
+	f.configured.set(rule)
+	f.direct = append(f.direct, &directRule{rule: rule, pattern: p, field: &field{name: f.name, pattern: pattern}})
+}
+
+func (f *fieldIndex) term(key string) (int, bool) {
+	id, ok := f.termIDs[key]
+	if !ok {
+		id = len(f.termRules)
+		f.termIDs[key] = id
+		f.termRules = append(f.termRules, nil)
+	}
+	return id, !ok
+}
+
+//addRule add rule with given record keys to field.
+//Func created will be called with key index and term id when new term created.
+func (f *fieldIndex) addRule(rule int, mode MatchMode, keys []string, created func(k int, term int)) {
+	if len(keys) == 0 {
+		return
+	}
+	f.configured.set(rule)
+	terms := make([]int, len(keys))
+	for k, key := range keys {
+		term, ok := f.term(key)
+		if ok && created != nil {
+			created(k, term)
+		}
+		terms[k] = term
+	}
+	if mode == MatchModeAll {
+		f.allRules = append(f.allRules, &allModeRule{rule: rule, terms: terms})
+		return
+	}
+	for _, term := range terms {
+		f.termRules[term] = append(f.termRules[term], rule)
+	}
+}
+
+//Matcher compiled indexed matcher of pattern configs.
+//Matching result of every rule equals plain pattern created by rule config.
+//Rules with sub patterns are evaluated one by one.
+//Queries,cookies and router params are evaluated rule by rule.
+type Matcher struct {
+	rules       []*PlainPattern
+	emptyResult bool
+	fields      []*fieldIndex
+	allMode     bitset
+	anyMode     bitset
+	noField     bitset
+	not         bitset
+	skip        bitset
+	fallback    []int
+}
+
+//Len return rules count.
+func (m *Matcher) Len() int {
+	return len(m.rules)
+}
+
+//MatchIDs match request and return ids of all matched rules in ascending order.
+//Rule id is index of rule config.
+//Template params of matched rules are captured after all rules evaluated.
+//Return ids and any error if raised.
+func (m *Matcher) MatchIDs(r *http.Request) ([]int, error) {
+	size := len(m.rules)
+	resultAll := newBitset(size)
+	for k := range resultAll {
+		resultAll[k] = ^uint64(0)
+	}
+	resultAny := newBitset(size)
+	copy(resultAny, m.noField)
+	matched := newBitset(size)
+	for _, f := range m.fields {
+		if f.configured.empty() {
+			continue
+		}
+		matched.clear()
+		var found bitset
+		if len(f.allRules) > 0 {
+			found = newBitset(len(f.termRules))
+		}
+		if f.lookup != nil {
+			f.lookup(r, func(term int) {
+				for _, rule := range f.termRules[term] {
+					matched.set(rule)
+				}
+				if found != nil {
+					found.set(term)
+				}
+			})
+		}
+		for _, dr := range f.direct {
+			ok, err := dr.pattern.matchField(r, dr.field)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched.set(dr.rule)
+			}
+		}
+		for _, ar := range f.allRules {
+			all := true
+			for _, term := range ar.terms {
+				if !found.has(term) {
+					all = false
+					break
+				}
+			}
+			if all {
+				matched.set(ar.rule)
+			}
+		}
+		for k := range matched {
+			resultAll[k] &= ^f.configured[k] | matched[k]
+			resultAny[k] |= f.configured[k] & matched[k]
+		}
+	}
+	result := newBitset(size)
+	for k := range result {
+		result[k] = ((m.allMode[k] & resultAll[k]) | (m.anyMode[k] & resultAny[k])) ^ m.not[k]
+		result[k] &^= m.skip[k]
+	}
+	for _, rule := range m.fallback {
+		ok, err := m.rules[rule].match(r)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result.set(rule)
+		}
+	}
+	ids := []int{}
+	for i := 0; i < size; i++ {
+		if result.has(i) {
+			ids = append(ids, i)
+			if !m.rules[i].Not {
+				err := m.rules[i].capture(r)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return ids, nil
+}
+
+//MatchRequest match request.
+//Match will success if any rule matched,or matcher has no rule and empty result is true.
+//Return result and any error if raised.
+func (m *Matcher) MatchRequest(r *http.Request) (bool, error) {
+	if len(m.rules) == 0 {
+		return m.emptyResult, nil
+	}
+	ids, err := m.MatchIDs(r)
+	if err != nil {
+		return false, err
+	}
+	return len(ids) > 0, nil
+}
+
+func (m *Matcher) newField(name string, lookup func(r *http.Request, emit func(term int))) *fieldIndex {
+	f := &fieldIndex{
+		name:       name,
+		termIDs:    map[string]int{},
+		configured: newBitset(len(m.rules)),
+		lookup:     lookup,
+	}
+	m.fields = append(m.fields, f)
+	return f
+}
+
+func hostKey(host string, value string) string {
+	return host + "\x00" + value
+}
+
+//pathHost indexed host pattern of path records with case sensitivity.
+type pathHost struct {
+	key           string
+	host          string
+	caseSensitive bool
+	trie          *byteTrie
+}
+
+//pathHosts distinct host patterns of path records.
+type pathHosts struct {
+	list  []*pathHost
+	byKey map[string]*pathHost
+}
+
+func (h *pathHosts) get(host string, caseSensitive bool) *pathHost {
+	key := "i" + host
+	if caseSensitive {
+		key = "s" + host
+	}
+	e := h.byKey[key]
+	if e == nil {
+		e = &pathHost{key: key, host: host, caseSensitive: caseSensitive}
+		h.byKey[key] = e
+		h.list = append(h.list, e)
+	}
+	return e
+}
+
+//each call fn with request path converted by case sensitivity for every host pattern matching request.
+func (h *pathHosts) each(r *http.Request, fn func(e *pathHost, urlpath string)) {
+	if len(h.list) == 0 {
+		return
+	}
+	host, hostname := requestHosts(r)
+	lower := strings.ToLower(r.URL.Path)
+	for _, e := range h.list {
+		if e.host != "" && !matchHostPattern(e.host, host, hostname) {
+			continue
+		}
+		if e.caseSensitive {
+			fn(e, r.URL.Path)
+		} else {
+			fn(e, lower)
+		}
+	}
+}
+
+func newPathHosts() *pathHosts {
+	return &pathHosts{byKey: map[string]*pathHost{}}
+}
+
+//NewMatcher compile given pattern configs to matcher.
+//MatchRequest of matcher without rule returns emptyResult.
+//Return matcher and any error if raised.
+func NewMatcher(configs []*PatternConfig, emptyResult bool) (*Matcher, error) {
+	m := &Matcher{
+		emptyResult: emptyResult,
+	}
+	for _, c := range configs {
+		p, err := c.CreatePattern()
+		if err != nil {
+			return nil, err
+		}
+		m.rules = append(m.rules, p.(*PlainPattern))
+	}
+	size := len(m.rules)
+	m.allMode = newBitset(size)
+	m.anyMode = newBitset(size)
+	m.noField = newBitset(size)
+	m.not = newBitset(size)
+	m.skip = newBitset(size)
+
+	cidr := newCIDRTree()
+	ipnets := m.newField(FieldIPNets, func(r *http.Request, emit func(term int)) {
+		cidr.lookup(GetRequestIP(r), emit)
+	})
+	methods := m.newField(FieldMethods, nil)
+	methods.lookup = func(r *http.Request, emit func(term int)) {
+		if term, ok := methods.termIDs[r.Method]; ok {
+			emit(term)
+		}
+	}
+	exts := m.newField(FieldExts, nil)
+	exts.lookup = func(r *http.Request, emit func(term int)) {
+		if term, ok := exts.termIDs[strings.ToLower(filepath.Ext(r.URL.Path))]; ok {
+			emit(term)
+		}
+	}
+	pathHostList := newPathHosts()
+	paths := m.newField(FieldPaths, nil)
+	paths.lookup = func(r *http.Request, emit func(term int)) {
+		pathHostList.each(r, func(e *pathHost, urlpath string) {
+			if term, ok := paths.termIDs[hostKey(e.key, urlpath)]; ok {
+				emit(term)
+			}
+		})
+	}
+	prefixHosts := newPathHosts()
+	prefixs := m.newField(FieldPrefixs, func(r *http.Request, emit func(term int)) {
+		prefixHosts.each(r, func(e *pathHost, urlpath string) {
+			e.trie.walk(urlpath, false, emit)
+		})
+	})
+	suffixHosts := newPathHosts()
+	suffixs := m.newField(FieldSuffixs, func(r *http.Request, emit func(term int)) {
+		suffixHosts.each(r, func(e *pathHost, urlpath string) {
+			e.trie.walk(urlpath, true, emit)
+		})
+	})
+	ac := newAhoCorasick()
+	keywords := m.newField(FieldKeywords, func(r *http.Request, emit func(term int)) {
+		ac.search(strings.ToLower(r.URL.RequestURI()), emit)
+	})
+	var set *regexSet
+	regexps := m.newField(FieldRegExps, func(r *http.Request, emit func(term int)) {
+		set.match(r.URL.Path, emit)
+	})
+	headerTerms := map[string]map[string]int{}
+	headers := m.newField(FieldHeaders, func(r *http.Request, emit func(term int)) {
+		for key, values := range headerTerms {
+			if term, ok := values[r.Header.Get(key)]; ok {
+				emit(term)
+			}
+		}
+	})
+	contenttypes := m.newField(FieldContentTypes, nil)
+	contenttypes.lookup = func(r *http.Request, emit func(term int)) {
+		contenttype := strings.TrimSpace(strings.SplitN(r.Header.Get("Content-Type"), ";", 2)[0])
+		if term, ok := contenttypes.termIDs[contenttype]; ok {
+			emit(term)
+		}
+	}
+
+	queries := m.newField(FieldQueries, nil)
+	cookies := m.newField(FieldCookies, nil)
+	routerparams := m.newField(FieldRouterParams, nil)
+	useragents := m.newField(FieldUserAgents, nil)
+	useragents.lookup = func(r *http.Request, emit func(term int)) {
+		if term, ok := useragents.termIDs[GetUserAgentFamily(r.UserAgent())]; ok {
+			emit(term)
+		}
+	}
+	globs := m.newField(FieldGlobs, nil)
+	templates := m.newField(FieldTemplates, nil)
+	geoips := m.newField(FieldGeoIPs, nil)
+
+	regexpList := []*regexp.Regexp{}
+	for i, p := range m.rules {
+		if p.Disabled {
+			m.skip.set(i)
+			continue
+		}
+		if p.Patterns != nil {
+			m.skip.set(i)
+			m.fallback = append(m.fallback, i)
+			continue
+		}
+		if p.Not {
+			m.not.set(i)
+		}
+		if p.Mode == MatchModeAny {
+			m.anyMode.set(i)
+		} else {
+			m.allMode.set(i)
+		}
+		if len(p.fields()) == 0 {
+			m.noField.set(i)
+		}
+		if p.IPNets != nil {
+			keys := make([]string, len(*p.IPNets))
+			for k, v := range *p.IPNets {
+				keys[k] = v.String()
+			}
+			ipnets.addRule(i, p.FieldModes[FieldIPNets], keys, func(k int, term int) {
+				cidr.add((*p.IPNets)[k], term)
+			})
+		}
+		if p.Methods != nil {
+			keys := []string{}
+			for v := range *p.Methods {
+				keys = append(keys, v)
+			}
+			methods.addRule(i, p.FieldModes[FieldMethods], keys, nil)
+		}
+		if p.Exts != nil {
+			keys := []string{}
+			for v := range *p.Exts {
+				keys = append(keys, v)
+			}
+			exts.addRule(i, p.FieldModes[FieldExts], keys, nil)
+		}
+		if p.Paths != nil {
+			keys := []string{}
+			for host, data := range *p.Paths {
+				e := pathHostList.get(host, p.CaseSensitive)
+				for v := range data {
+					keys = append(keys, hostKey(e.key, v))
+				}
+			}
+			paths.addRule(i, p.FieldModes[FieldPaths], keys, nil)
+		}
+		if p.Prefixs != nil {
+			data := map[string][]string{}
+			for host, v := range *p.Prefixs {
+				data[host] = v
+			}
+			addTrieRule(prefixs, prefixHosts, i, p.FieldModes[FieldPrefixs], data, p.CaseSensitive, false)
+		}
+		if p.Suffixs != nil {
+			data := map[string][]string{}
+			for host, v := range *p.Suffixs {
+				data[host] = v
+			}
+			addTrieRule(suffixs, suffixHosts, i, p.FieldModes[FieldSuffixs], data, p.CaseSensitive, true)
+		}
+		if p.Keywords != nil {
+			keywords.addRule(i, p.FieldModes[FieldKeywords], *p.Keywords, func(k int, term int) {
+				ac.add((*p.Keywords)[k], term)
+			})
+		}
+		if p.RegExps != nil {
+			keys := make([]string, len(*p.RegExps))
+			for k, v := range *p.RegExps {
+				keys[k] = v.String()
+			}
+			regexps.addRule(i, p.FieldModes[FieldRegExps], keys, func(k int, term int) {
+				regexpList = append(regexpList, (*p.RegExps)[k])
+			})
+		}
+		if p.Headers != nil {
+			keys := make([]string, len(*p.Headers))
+			for k, v := range *p.Headers {
+				keys[k] = hostKey(textproto.CanonicalMIMEHeaderKey(v.Key), v.Value)
+			}
+			headers.addRule(i, p.FieldModes[FieldHeaders], keys, func(k int, term int) {
+				key := textproto.CanonicalMIMEHeaderKey((*p.Headers)[k].Key)
+				if headerTerms[key] == nil {
+					headerTerms[key] = map[string]int{}
+				}
+				headerTerms[key][(*p.Headers)[k].Value] = term
+			})
+		}
+		if p.ContentTypes != nil {
+			keys := []string{}
+			for v := range *p.ContentTypes {
+				keys = append(keys, v)
+			}
+			contenttypes.addRule(i, p.FieldModes[FieldContentTypes], keys, nil)
+		}
+		if p.Queries != nil {
+			queries.addDirectRule(i, p, p.Queries, len(*p.Queries))
+		}
+		if p.Cookies != nil {
+			cookies.addDirectRule(i, p, p.Cookies, len(*p.Cookies))
+		}
+		if p.RouterParams != nil {
+			routerparams.addDirectRule(i, p, p.RouterParams, len(*p.RouterParams))
+		}
+		if p.Globs != nil {
+			globs.addDirectRule(i, p, p.Globs, len(*p.Globs))
+		}
+		if p.Templates != nil {
+			templates.addDirectRule(i, p, p.Templates, len(p.Templates.Data))
+		}
+		if p.GeoIPs != nil {
+			geoips.addDirectRule(i, p, p.GeoIPs, p.GeoIPs.Len())
+		}
+		if p.UserAgents != nil {
+			keys := []string{}
+			for v := range *p.UserAgents {
+				keys = append(keys, v)
+			}
+			useragents.addRule(i, p.FieldModes[FieldUserAgents], keys, nil)
+		}
+	}
+	ac.build()
+	set = newRegexSet(regexpList)
+	return m, nil
+}
+
+//addTrieRule add prefix or suffix records of rule to field and tries by host and case sensitivity.
+func addTrieRule(f *fieldIndex, hosts *pathHosts, rule int, mode MatchMode, data map[string][]string, caseSensitive bool, reverse bool) {
+	keys := []string{}
+	values := []string{}
+	entries := []*pathHost{}
+	for host, list := range data {
+		e := hosts.get(host, caseSensitive)
+		for _, v := range list {
+			keys = append(keys, hostKey(e.key, v))
+			values = append(values, v)
+			entries = append(entries, e)
+		}
+	}
+	f.addRule(rule, mode, keys, func(k int, term int) {
+		e := entries[k]
+		if e.trie == nil {
+			e.trie = newByteTrie()
+		}
+		e.trie.add(values[k], term, reverse)
+	})
+}
+
+//CreateMatcher compile filters config to indexed matcher.
+//Matcher will success if filters is empty.
+//Return matcher and any error if raised.
+func (c *FiltersConfig) CreateMatcher() (*Matcher, error) {
+	return NewMatcher(*c, true)
+}
+
+//CreateMatcher compile whitelist config to indexed matcher.
+//Matcher will fail if whitelist is empty.
+//Return matcher and any error if raised.
+func (c *WhitelistConfig) CreateMatcher() (*Matcher, error) {
+	return NewMatcher(*c, false)
+}
@@ -0,0 +1,266 @@
+package requestmatching
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/herb-go/herb/middleware/router"
+)
+
+var (
+	testIPList          = []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.3/32", "192.168.1.0/24", "0.0.0.0/0", "::1/128", "2001:db8::/32", "::ffff:0:0/96", "::/0"}
+	testURLList         = []string{"/", "/a", "/A", "/a/b", "127.0.0.1/a", "example.com/b", "example.com/", "*.example.com/a", ".example.com/b"}
+	testPrefixList      = []string{"/", "/a", "/a/b", "/b", "/A", "example.com/a", "127.0.0.1/b", "*.example.com/A"}
+	testSuffixList      = []string{"/", "/b", "/.php", "/a/b", "example.com/.php", ".php", "127.0.0.1/b"}
+	testExtList         = []string{"", ".php", ".PHP", ".html"}
+	testMethodList      = []string{"get", "POST", "put"}
+	testKeywordList     = []string{"", "a", "ab", "debug", "php", "q=1", "/a/"}
+	testRegExpList      = []string{"^/a", "b$", "(?i)PHP", "[0-9]+", "x|y", "^/$"}
+	testHeaderList      = []string{"X-A:1", "x-a:2", "X-B:", "Content-Type:text/plain", "x-b : 3"}
+	testContentTypeList = []string{"text/plain", "application/json"}
+	testQueryList       = []string{"q", "q=1", "debug^=y", "x~=^a", "missing"}
+	testCookieList      = []string{"session", "session=abc", "session^=a", "theme~=dark|light"}
+	testParamList       = []string{"id", "id=1", "id^=1", "name~=^[a-z]+$"}
+	testUserAgentList   = []string{"bot", "curl", "chrome", "empty", "other"}
+	testGlobList        = []string{"/**", "/a/*", "/**/*.php", "/?", "/{a,b}/**", "example.com/**/b", "*.example.com/*"}
+	testTemplateList    = []string{"/:id", "/a/:id", "/:dir/:file", "/debug/*rest", "example.com/:id", ".example.com/a/:id"}
+	testModes           = []string{"", "any", "all"}
+
+	testHosts       = []string{"", "127.0.0.1", "example.com", "EXAMPLE.com", "www.example.com", "example.com:8080", "WWW.EXAMPLE.COM"}
+	testPaths       = []string{"/", "/a", "/A", "/a/b", "/b", "/x.php", "/A/B.PHP", "/debug/1", "/y.html"}
+	testQueries     = []string{"", "q=1", "debug=y", "x=ab"}
+	testMethods     = []string{"GET", "POST", "PUT", "get"}
+	testUserAgents  = []string{"", "curl/7.0", "Mozilla/5.0 Chrome/90.0 Safari/537.36", "Googlebot/2.1", "custom"}
+	testRemoteAddrs = []string{"10.1.2.3:1", "10.2.0.1:1", "192.168.1.5:2", "[::1]:3", "[2001:db8::5]:4", "[::ffff:8.8.8.8]:5", "8.8.8.8:5", "invalid"}
+)
+
+func pick(rnd *rand.Rand, list []string, max int) []string {
+	n := rnd.Intn(max + 1)
+	if n == 0 {
+		return nil
+	}
+	result := make([]string, n)
+	for k := range result {
+		result[k] = list[rnd.Intn(len(list))]
+	}
+	return result
+}
+
+func randomPatternConfig(rnd *rand.Rand, depth int) *PatternConfig {
+	c := &PatternConfig{}
+	lists := []*[]string{}
+	switch rnd.Intn(5) {
+	case 0:
+		lists = append(lists, &c.IPList, &c.MethodList, &c.HeaderList)
+	case 1:
+		lists = append(lists, &c.URLList, &c.PrefixList, &c.SuffixList, &c.ExtList)
+	case 2:
+		lists = append(lists, &c.KeywordList, &c.RegExpList, &c.ContentTypeList, &c.PrefixList)
+	case 3:
+		lists = append(lists, &c.GlobList, &c.TemplateList, &c.URLList, &c.SuffixList)
+	default:
+		lists = append(lists, &c.QueryList, &c.CookieList, &c.ParamList, &c.UserAgentList)
+	}
+	sources := map[*[]string][]string{
+		&c.IPList: testIPList, &c.URLList: testURLList, &c.PrefixList: testPrefixList, &c.SuffixList: testSuffixList,
+		&c.ExtList: testExtList, &c.MethodList: testMethodList, &c.KeywordList: testKeywordList, &c.RegExpList: testRegExpList,
+		&c.HeaderList: testHeaderList, &c.ContentTypeList: testContentTypeList,
+		&c.QueryList: testQueryList, &c.CookieList: testCookieList, &c.ParamList: testParamList, &c.UserAgentList: testUserAgentList,
+		&c.GlobList: testGlobList, &c.TemplateList: testTemplateList,
+	}
+	for _, l := range lists {
+		if rnd.Intn(2) == 0 {
+			*l = pick(rnd, sources[l], 3)
+		}
+	}
+	c.CaseSensitive = rnd.Intn(2) == 0
+	c.Mode = testModes[rnd.Intn(len(testModes))]
+	c.FieldModes = map[string]string{}
+	for _, f := range []string{FieldKeywords, FieldRegExps, FieldHeaders, FieldQueries, FieldCookies, FieldRouterParams, FieldGlobs, FieldTemplates} {
+		c.FieldModes[f] = testModes[rnd.Intn(len(testModes))]
+	}
+	c.Not = rnd.Intn(4) == 0
+	c.Disabled = rnd.Intn(10) == 0
+	if depth == 0 && rnd.Intn(8) == 0 {
+		c.And = rnd.Intn(2) == 0
+		c.Patterns = []*PatternConfig{randomPatternConfig(rnd, 1), randomPatternConfig(rnd, 1)}
+	}
+	return c
+}
+
+func randomRequest(rnd *rand.Rand) *http.Request {
+	path := testPaths[rnd.Intn(len(testPaths))]
+	query := testQueries[rnd.Intn(len(testQueries))]
+	if query != "" {
+		path = path + "?" + query
+	}
+	r, err := http.NewRequest(testMethods[rnd.Intn(len(testMethods))], path, nil)
+	if err != nil {
+		panic(err)
+	}
+	r.Host = testHosts[rnd.Intn(len(testHosts))]
+	r.RemoteAddr = testRemoteAddrs[rnd.Intn(len(testRemoteAddrs))]
+	if rnd.Intn(2) == 0 {
+		r.Header.Set("X-A", fmt.Sprint(rnd.Intn(3)))
+	}
+	if rnd.Intn(2) == 0 {
+		r.Header.Set("X-B", fmt.Sprint(rnd.Intn(4)))
+	}
+	if rnd.Intn(2) == 0 {
+		r.Header.Set("Content-Type", testContentTypeList[rnd.Intn(len(testContentTypeList))]+"; charset=utf-8")
+	}
+	r.Header.Set("User-Agent", testUserAgents[rnd.Intn(len(testUserAgents))])
+	if rnd.Intn(2) == 0 {
+		r.AddCookie(&http.Cookie{Name: "session", Value: []string{"abc", "xyz", ""}[rnd.Intn(3)]})
+	}
+	if rnd.Intn(2) == 0 {
+		r.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	}
+	if rnd.Intn(2) == 0 {
+		params := router.GetParams(r)
+		params.Set("id", []string{"1", "12", "2", ""}[rnd.Intn(4)])
+		params.Set("name", []string{"abc", "ABC"}[rnd.Intn(2)])
+	}
+	return r
+}
+
+type matcherScenario struct {
+	Configs  []*PatternConfig
+	Requests []*http.Request
+}
+
+func (matcherScenario) Generate(rnd *rand.Rand, size int) reflect.Value {
+	s := matcherScenario{}
+	for i := rnd.Intn(size + 1); i >= 0; i-- {
+		s.Configs = append(s.Configs, randomPatternConfig(rnd, 0))
+	}
+	for i := 0; i < 20; i++ {
+		s.Requests = append(s.Requests, randomRequest(rnd))
+	}
+	return reflect.ValueOf(s)
+}
+
+func naiveMatchIDs(configs []*PatternConfig, r *http.Request) []int {
+	ids := []int{}
+	for k, c := range configs {
+		if MustMatch(r, MustCreatePattern(c)) {
+			ids = append(ids, k)
+		}
+	}
+	return ids
+}
+
+func TestMatcherProperty(t *testing.T) {
+	f := func(s matcherScenario) bool {
+		m, err := NewMatcher(s.Configs, true)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		for _, r := range s.Requests {
+			ids, err := m.MatchIDs(r)
+			if err != nil {
+				t.Log(err)
+				return false
+			}
+			expected := naiveMatchIDs(s.Configs, r)
+			if fmt.Sprint(ids) != fmt.Sprint(expected) {
+				t.Log(r.Method, r.Host, r.URL.String(), r.RemoteAddr, r.Header, ids, expected)
+				return false
+			}
+			filters := FiltersConfig(s.Configs)
+			if MustMatch(r, m) != MustMatch(r, MustCreatePattern(&filters)) {
+				return false
+			}
+		}
+		return true
+	}
+	err := quick.Check(f, &quick.Config{MaxCount: 500, Rand: rand.New(rand.NewSource(1))})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMatcherEmpty(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	f, err := (&FiltersConfig{}).CreateMatcher()
+	if err != nil || !MustMatch(r, f) || f.Len() != 0 {
+		t.Fatal(f, err)
+	}
+	w, err := (&WhitelistConfig{}).CreateMatcher()
+	if err != nil || MustMatch(r, w) {
+		t.Fatal(w, err)
+	}
+	w, err = (&WhitelistConfig{{URLList: []string{"/"}}, {URLList: []string{"/fail"}}, {}}).CreateMatcher()
+	if err != nil || !MustMatch(r, w) {
+		t.Fatal(w, err)
+	}
+	ids, err := w.MatchIDs(r)
+	if err != nil || fmt.Sprint(ids) != "[0 2]" {
+		t.Fatal(ids, err)
+	}
+	_, err = (&WhitelistConfig{{IPList: []string{"invalid"}}}).CreateMatcher()
+	if err == nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegexSet(t *testing.T) {
+	configs := []*PatternConfig{}
+	for i := 0; i < 100; i++ {
+		configs = append(configs, &PatternConfig{RegExpList: []string{fmt.Sprintf("^/item/%d$", i)}})
+	}
+	configs = append(configs, &PatternConfig{RegExpList: []string{"^/item/"}})
+	m, err := NewMatcher(configs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := http.NewRequest("GET", "/item/42", nil)
+	ids, err := m.MatchIDs(r)
+	if err != nil || fmt.Sprint(ids) != "[42 100]" {
+		t.Fatal(ids, err)
+	}
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	configs := FiltersConfig{}
+	for i := 0; i < 5000; i++ {
+		configs = append(configs, &PatternConfig{
+			PrefixList:  []string{fmt.Sprintf("/bot%d/", i)},
+			KeywordList: []string{fmt.Sprintf("token%d", rnd.Int())},
+		})
+	}
+	m, err := configs.CreateMatcher()
+	if err != nil {
+		b.Fatal(err)
+	}
+	r, _ := http.NewRequest("GET", "/bot42/index.html?a=1", nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.MatchIDs(r)
+	}
+}
+
+func BenchmarkNaive(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	configs := FiltersConfig{}
+	for i := 0; i < 5000; i++ {
+		configs = append(configs, &PatternConfig{
+			PrefixList:  []string{fmt.Sprintf("/bot%d/", i)},
+			KeywordList: []string{fmt.Sprintf("token%d", rnd.Int())},
+		})
+	}
+	p := MustCreatePattern(&configs).(*Filters)
+	r, _ := http.NewRequest("GET", "/bot42/index.html?a=1", nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, v := range *p {
+			v.MatchRequest(r)
+		}
+	}
+}
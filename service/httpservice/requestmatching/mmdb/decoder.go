@@ -0,0 +1,208 @@
+package mmdb
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+)
+
+//Data types defined by MaxMind DB format.
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEnd       = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+//maxDepth max nested depth of data structure.
+const maxDepth = 64
+
+type decoder struct {
+	buf []byte
+}
+
+func (d *decoder) read(offset int, n int) ([]byte, error) {
+	if offset < 0 || n < 0 || offset+n > len(d.buf) {
+		return nil, ErrInvalidDatabase
+	}
+	return d.buf[offset : offset+n], nil
+}
+
+func (d *decoder) control(offset int) (kind int, size int, next int, err error) {
+	b, err := d.read(offset, 1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	ctrl := b[0]
+	next = offset + 1
+	kind = int(ctrl >> 5)
+	if kind == typePointer {
+		return kind, int(ctrl), next, nil
+	}
+	if kind == typeExtended {
+		b, err = d.read(next, 1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		kind = int(b[0]) + 7
+		next++
+		if kind <= typeMap || kind > typeFloat {
+			return 0, 0, 0, ErrInvalidDatabase
+		}
+	}
+	size = int(ctrl & 0x1f)
+	if size < 29 {
+		return kind, size, next, nil
+	}
+	n := size - 28
+	b, err = d.read(next, n)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	next += n
+	v := int(uintValue(b))
+	switch n {
+	case 1:
+		size = 29 + v
+	case 2:
+		size = 285 + v
+	default:
+		size = 65821 + v
+	}
+	return kind, size, next, nil
+}
+
+//pointer decode pointer with control byte.
+//Return pointed offset and offset after pointer.
+func (d *decoder) pointer(ctrl int, offset int) (int, int, error) {
+	n := ((ctrl >> 3) & 0x3) + 1
+	b, err := d.read(offset, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	v := int(uintValue(b))
+	switch n {
+	case 1:
+		v = (ctrl&0x7)<<8 | v
+	case 2:
+		v = ((ctrl&0x7)<<16 | v) + 2048
+	case 3:
+		v = ((ctrl&0x7)<<24 | v) + 526336
+	}
+	return v, offset + n, nil
+}
+
+func uintValue(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+//decode decode value at offset.
+//Return value and offset after value.
+func (d *decoder) decode(offset int, depth int) (interface{}, int, error) {
+	if depth > maxDepth {
+		return nil, 0, ErrInvalidDatabase
+	}
+	kind, size, next, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if kind == typePointer {
+		target, after, err := d.pointer(size, next)
+		if err != nil {
+			return nil, 0, err
+		}
+		if k, _, _, err := d.control(target); err != nil || k == typePointer {
+			return nil, 0, ErrInvalidDatabase
+		}
+		v, _, err := d.decode(target, depth+1)
+		return v, after, err
+	}
+	switch kind {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			key, after, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			s, ok := key.(string)
+			if !ok {
+				return nil, 0, ErrInvalidDatabase
+			}
+			m[s], next, err = d.decode(after, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return m, next, nil
+	case typeArray:
+		a := make([]interface{}, size)
+		for i := range a {
+			a[i], next, err = d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return a, next, nil
+	case typeBool:
+		if size > 1 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return size == 1, next, nil
+	case typeContainer, typeEnd:
+		return nil, 0, ErrInvalidDatabase
+	}
+	b, err := d.read(next, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	next += size
+	switch kind {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes:
+		return append([]byte{}, b...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > map[int]int{typeUint16: 2, typeUint32: 4, typeUint64: 8}[kind] {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return uintValue(b), next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return int64(int32(uint32(uintValue(b)))), next, nil
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return new(big.Int).SetBytes(b), next, nil
+	}
+	return nil, 0, ErrInvalidDatabase
+}
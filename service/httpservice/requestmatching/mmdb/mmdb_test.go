@@ -0,0 +1,222 @@
+package mmdb
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/herb-go/herb/service/httpservice/requestmatching/internal/mmdbwriter"
+)
+
+func TestRoundTrip(t *testing.T) {
+	value := map[string]interface{}{
+		"string":  "value",
+		"long":    strings.Repeat("a", 300),
+		"longer":  strings.Repeat("b", 70000),
+		"bytes":   []byte{1, 2, 3},
+		"true":    true,
+		"false":   false,
+		"double":  1.5,
+		"float":   float32(2.5),
+		"uint16":  uint16(300),
+		"uint32":  uint32(70000),
+		"uint64":  uint64(1) << 40,
+		"zero":    uint32(0),
+		"int32":   int32(-5),
+		"int":     12,
+		"uint128": new(big.Int).Lsh(big.NewInt(1), 100),
+		"array":   []interface{}{"a", uint32(1)},
+		"strings": []string{"x", "y"},
+		"map":     map[string]string{"en": "English"},
+	}
+	expected := map[string]interface{}{
+		"string":  "value",
+		"long":    strings.Repeat("a", 300),
+		"longer":  strings.Repeat("b", 70000),
+		"bytes":   []byte{1, 2, 3},
+		"true":    true,
+		"false":   false,
+		"double":  1.5,
+		"float":   float32(2.5),
+		"uint16":  uint64(300),
+		"uint32":  uint64(70000),
+		"uint64":  uint64(1) << 40,
+		"zero":    uint64(0),
+		"int32":   int64(-5),
+		"int":     int64(12),
+		"uint128": new(big.Int).Lsh(big.NewInt(1), 100),
+		"array":   []interface{}{"a", uint64(1)},
+		"strings": []interface{}{"x", "y"},
+		"map":     map[string]interface{}{"en": "English"},
+	}
+	for _, size := range []int{24, 28, 32} {
+		w := mmdbwriter.New("Test")
+		w.RecordSize = size
+		w.Languages = []string{"en"}
+		w.Description = map[string]string{"en": "test database"}
+		w.BuildEpoch = 1600000000
+		if err := w.Insert("1.2.3.0/24", value); err != nil {
+			t.Fatal(err)
+		}
+		data, err := w.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := FromBytes(data)
+		if err != nil {
+			t.Fatal(size, err)
+		}
+		md := r.Metadata
+		if md.RecordSize != size || md.IPVersion != 6 || md.DatabaseType != "Test" || md.BuildEpoch != 1600000000 ||
+			!reflect.DeepEqual(md.Languages, []string{"en"}) || md.Description["en"] != "test database" {
+			t.Fatal(md)
+		}
+		v, prefix, err := r.Lookup(net.ParseIP("1.2.3.4"))
+		if err != nil || prefix != 24 || !reflect.DeepEqual(v, expected) {
+			t.Fatal(size, v, prefix, err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	w := mmdbwriter.New("Test")
+	for network, value := range map[string]interface{}{
+		"10.0.0.0/8":    "a",
+		"2001:db8::/32": "v6",
+		"::1":           "loopback",
+		"8.8.8.8":       "single",
+	} {
+		if err := w.Insert(network, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Insert("10.1.0.0/16", "b")
+	w.Insert("10.1.2.0/24", "a")
+	w.Insert("192.168.0.0/16", "c")
+	w.Insert("192.168.0.0/24", "d")
+	w.Insert("192.168.0.0/16", "e")
+	data, err := w.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := FromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ip, expected := range map[string]interface{}{
+		"10.0.0.1":        "a",
+		"10.1.0.1":        "b",
+		"10.1.2.1":        "a",
+		"10.2.0.1":        "a",
+		"11.0.0.1":        nil,
+		"8.8.8.8":         "single",
+		"8.8.8.9":         nil,
+		"::ffff:10.0.0.1": "a",
+		"2001:db8::5":     "v6",
+		"2001:db9::5":     nil,
+		"::1":             "loopback",
+		"::2":             nil,
+		"192.168.0.1":     "e",
+		"192.168.5.1":     "e",
+	} {
+		v, _, err := r.Lookup(net.ParseIP(ip))
+		if err != nil || v != expected {
+			t.Fatal(ip, v, err)
+		}
+	}
+	v, prefix, err := r.Lookup(net.ParseIP("10.1.3.1"))
+	if v != "b" || prefix != 24 || err != nil {
+		t.Fatal(v, prefix, err)
+	}
+	v, prefix, err = r.Lookup(net.ParseIP("2001:db8::1"))
+	if v != "v6" || prefix != 32 || err != nil {
+		t.Fatal(v, prefix, err)
+	}
+	v, _, err = r.Lookup(nil)
+	if v != nil || err != nil {
+		t.Fatal(v, err)
+	}
+}
+
+func TestPointer(t *testing.T) {
+	d := &decoder{buf: []byte{
+		//offset 0: string "abc"
+		0x43, 'a', 'b', 'c',
+		//offset 4: map with 2 pairs,keys and value are pointers to offset 0
+		0xe2, 0x20, 0x00, 0x43, 'x', 'y', 'z', 0x20, 0x07, 0x20, 0x00,
+	}}
+	v, next, err := d.decode(4, 0)
+	if err != nil || next != len(d.buf) || !reflect.DeepEqual(v, map[string]interface{}{"abc": "xyz", "xyz": "abc"}) {
+		t.Fatal(v, next, err)
+	}
+	d = &decoder{buf: []byte{0x20, 0x00}}
+	_, _, err = d.decode(0, 0)
+	if !errors.Is(err, ErrInvalidDatabase) {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	w := mmdbwriter.New("Test")
+	w.Insert("10.0.0.0/8", "a")
+	data, err := w.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, buf := range [][]byte{
+		nil,
+		[]byte("not a database"),
+		data[:len(data)-10],
+		data[len(data)/2:],
+	} {
+		_, err = FromBytes(buf)
+		if !errors.Is(err, ErrInvalidDatabase) {
+			t.Fatal(err)
+		}
+	}
+	corrupted := append([]byte{}, data...)
+	i := bytes.LastIndex(corrupted, metadataMarker)
+	for k := (i - 16) / 2; k < i-16; k++ {
+		corrupted[k] = 0xff
+	}
+	r, err := FromBytes(corrupted)
+	if err == nil {
+		_, _, err = r.Lookup(net.ParseIP("10.0.0.1"))
+		if !errors.Is(err, ErrInvalidDatabase) {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	w := mmdbwriter.New("Test")
+	w.Insert("10.0.0.0/8", "a")
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = w.WriteTo(file)
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, _, err := r.Lookup(net.ParseIP("10.0.0.1"))
+	if v != "a" || err != nil {
+		t.Fatal(v, err)
+	}
+	_, err = Open(filepath.Join(t.TempDir(), "notexist.mmdb"))
+	if !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+}
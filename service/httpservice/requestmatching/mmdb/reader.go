@@ -0,0 +1,205 @@
+//Package mmdb provide reader and writer of MaxMind DB format files.
+//Only standard library is used and database is loaded into memory.
+package mmdb
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+)
+
+//ErrInvalidDatabase error raised if database data is invalid.
+var ErrInvalidDatabase = errors.New("mmdb:invalid database")
+
+//metadataMarker marker before metadata section.
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+//dataSeparatorSize size of zero bytes between search tree and data section.
+const dataSeparatorSize = 16
+
+//Metadata database metadata
+type Metadata struct {
+	//NodeCount search tree node count
+	NodeCount int
+	//RecordSize search tree record size in bits,24,28 or 32
+	RecordSize int
+	//IPVersion 4 or 6
+	IPVersion int
+	//DatabaseType database type,such as "GeoLite2-Country"
+	DatabaseType string
+	//Languages locale codes
+	Languages []string
+	//BuildEpoch database build time in unix seconds
+	BuildEpoch int64
+	//Description descriptions by language
+	Description map[string]string
+}
+
+//Reader database reader.
+//Reader is immutable and safe for concurrent use.
+type Reader struct {
+	//Metadata database metadata
+	Metadata  *Metadata
+	tree      []byte
+	data      *decoder
+	nodeSize  int
+	ipv4Start int
+}
+
+func metadataUint(m map[string]interface{}, key string) (int, error) {
+	v, ok := m[key].(uint64)
+	if !ok {
+		return 0, ErrInvalidDatabase
+	}
+	return int(v), nil
+}
+
+func parseMetadata(buf []byte) (*Metadata, error) {
+	d := &decoder{buf: buf}
+	v, _, err := d.decode(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidDatabase
+	}
+	md := &Metadata{Description: map[string]string{}}
+	if md.NodeCount, err = metadataUint(m, "node_count"); err != nil {
+		return nil, err
+	}
+	if md.RecordSize, err = metadataUint(m, "record_size"); err != nil {
+		return nil, err
+	}
+	if md.IPVersion, err = metadataUint(m, "ip_version"); err != nil {
+		return nil, err
+	}
+	major, err := metadataUint(m, "binary_format_major_version")
+	if err != nil || major != 2 {
+		return nil, ErrInvalidDatabase
+	}
+	md.DatabaseType, _ = m["database_type"].(string)
+	epoch, _ := m["build_epoch"].(uint64)
+	md.BuildEpoch = int64(epoch)
+	languages, _ := m["languages"].([]interface{})
+	for _, l := range languages {
+		if s, ok := l.(string); ok {
+			md.Languages = append(md.Languages, s)
+		}
+	}
+	description, _ := m["description"].(map[string]interface{})
+	for k, v := range description {
+		if s, ok := v.(string); ok {
+			md.Description[k] = s
+		}
+	}
+	return md, nil
+}
+
+//FromBytes create reader from database data.
+//Return reader and any error if raised.
+func FromBytes(buf []byte) (*Reader, error) {
+	i := bytes.LastIndex(buf, metadataMarker)
+	if i < 0 {
+		return nil, ErrInvalidDatabase
+	}
+	md, err := parseMetadata(buf[i+len(metadataMarker):])
+	if err != nil {
+		return nil, err
+	}
+	if md.RecordSize != 24 && md.RecordSize != 28 && md.RecordSize != 32 {
+		return nil, ErrInvalidDatabase
+	}
+	if md.IPVersion != 4 && md.IPVersion != 6 {
+		return nil, ErrInvalidDatabase
+	}
+	nodeSize := md.RecordSize / 4
+	treeSize := md.NodeCount * nodeSize
+	if md.NodeCount <= 0 || treeSize+dataSeparatorSize > i {
+		return nil, ErrInvalidDatabase
+	}
+	r := &Reader{
+		Metadata: md,
+		tree:     buf[:treeSize],
+		data:     &decoder{buf: buf[treeSize+dataSeparatorSize : i]},
+		nodeSize: nodeSize,
+	}
+	if md.IPVersion == 6 {
+		node := 0
+		for i := 0; i < 96 && node < md.NodeCount; i++ {
+			node = r.record(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+//Open read database file into memory.
+//Return reader and any error if raised.
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buf)
+}
+
+//record return left(bit 0) or right(bit 1) record of node.
+func (r *Reader) record(node int, bit int) int {
+	b := r.tree[node*r.nodeSize : (node+1)*r.nodeSize]
+	switch r.Metadata.RecordSize {
+	case 24:
+		if bit == 0 {
+			return int(uintValue(b[0:3]))
+		}
+		return int(uintValue(b[3:6]))
+	case 28:
+		if bit == 0 {
+			return int(b[3]&0xf0)<<20 | int(uintValue(b[0:3]))
+		}
+		return int(b[3]&0x0f)<<24 | int(uintValue(b[4:7]))
+	}
+	if bit == 0 {
+		return int(uintValue(b[0:4]))
+	}
+	return int(uintValue(b[4:8]))
+}
+
+//Lookup lookup data of given ip.
+//Return data,network prefix length and any error if raised.
+//Prefix length of IPv4 address is counted in IPv4 bits.
+//Data will be nil if ip not found.
+func (r *Reader) Lookup(ip net.IP) (interface{}, int, error) {
+	var addr []byte
+	node := 0
+	if ip4 := ip.To4(); ip4 != nil {
+		addr = ip4
+		if r.Metadata.IPVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else {
+		addr = ip.To16()
+		if addr == nil || r.Metadata.IPVersion == 4 {
+			return nil, 0, nil
+		}
+	}
+	count := r.Metadata.NodeCount
+	bits := len(addr) * 8
+	depth := 0
+	for ; depth < bits && node < count; depth++ {
+		bit := int(addr[depth>>3]>>(7-uint(depth&7))) & 1
+		node = r.record(node, bit)
+	}
+	if node == count {
+		return nil, 0, nil
+	}
+	if node < count {
+		return nil, 0, ErrInvalidDatabase
+	}
+	v, _, err := r.data.decode(node-count-dataSeparatorSize, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v, depth, nil
+}
@@ -0,0 +1,79 @@
+package requestmatching
+
+import (
+	"fmt"
+	"net/http"
+)
+
+//MatchMode match mode type
+type MatchMode string
+
+const (
+	//MatchModeDefault default match mode.
+	//Records within a field use MatchModeAny,fields use MatchModeAll.
+	MatchModeDefault = MatchMode("")
+	//MatchModeAny match success if any record or field matched.
+	MatchModeAny = MatchMode("any")
+	//MatchModeAll match success if all records or fields matched.
+	MatchModeAll = MatchMode("all")
+)
+
+//ParseMatchMode parse match mode name.
+//Return match mode and any error if raised.
+func ParseMatchMode(name string) (MatchMode, error) {
+	m := MatchMode(name)
+	switch m {
+	case MatchModeDefault, MatchModeAny, MatchModeAll:
+		return m, nil
+	}
+	return MatchModeDefault, fmt.Errorf("%w : \"%s\"", ErrUnknownMatchMode, name)
+}
+
+//AllMatcher pattern which can require all records matched.
+type AllMatcher interface {
+	//MatchRequestAll match request with all records.
+	//Return result and any error if raised.
+	MatchRequestAll(r *http.Request) (bool, error)
+}
+
+//Plain pattern field names.
+const (
+	FieldIPNets       = "IPNets"
+	FieldMethods      = "Methods"
+	FieldExts         = "Exts"
+	FieldPaths        = "Paths"
+	FieldPrefixs      = "Prefixs"
+	FieldSuffixs      = "Suffixs"
+	FieldKeywords     = "Keywords"
+	FieldRegExps      = "RegExps"
+	FieldHeaders      = "Headers"
+	FieldContentTypes = "ContentTypes"
+	FieldQueries      = "Queries"
+	FieldCookies      = "Cookies"
+	FieldRouterParams = "RouterParams"
+	FieldUserAgents   = "UserAgents"
+	FieldGlobs        = "Globs"
+	FieldTemplates    = "Templates"
+	FieldGeoIPs       = "GeoIPs"
+)
+
+//Fields plain pattern field names in evaluation order.
+var Fields = []string{
+	FieldIPNets,
+	FieldMethods,
+	FieldExts,
+	FieldPaths,
+	FieldPrefixs,
+	FieldSuffixs,
+	FieldKeywords,
+	FieldRegExps,
+	FieldHeaders,
+	FieldContentTypes,
+	FieldQueries,
+	FieldCookies,
+	FieldRouterParams,
+	FieldUserAgents,
+	FieldGlobs,
+	FieldTemplates,
+	FieldGeoIPs,
+}
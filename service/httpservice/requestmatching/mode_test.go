@@ -0,0 +1,105 @@
+package requestmatching
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAllFieldsEvaluated(t *testing.T) {
+	r, _ := http.NewRequest("POST", "http://127.0.0.1/admin/index.php?debug=1", nil)
+	r.Header.Set("X-Token", "secret")
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	for _, c := range []*PatternConfig{
+		{SuffixList: []string{"/.asp"}},
+		{KeywordList: []string{"trace"}},
+		{RegExpList: []string{"^/public/"}},
+		{HeaderList: []string{"X-Token:wrong"}},
+		{ContentTypeList: []string{"text/plain"}},
+	} {
+		if MustMatch(r, MustCreatePattern(c)) {
+			t.Fatal(c)
+		}
+	}
+	for _, c := range []*PatternConfig{
+		{SuffixList: []string{"/.php"}},
+		{KeywordList: []string{"debug"}},
+		{RegExpList: []string{"^/admin/"}},
+		{HeaderList: []string{"X-Token:secret"}},
+		{ContentTypeList: []string{"application/json"}},
+	} {
+		if !MustMatch(r, MustCreatePattern(c)) {
+			t.Fatal(c)
+		}
+	}
+}
+
+func TestMatchMode(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://127.0.0.1/admin/?debug=1", nil)
+	r.Header.Set("X-A", "1")
+	r.Header.Set("X-B", "2")
+	c := &PatternConfig{
+		MethodList:  []string{"POST"},
+		KeywordList: []string{"debug"},
+	}
+	if MustMatch(r, MustCreatePattern(c)) {
+		t.Fatal(c)
+	}
+	c.Mode = "any"
+	if !MustMatch(r, MustCreatePattern(c)) {
+		t.Fatal(c)
+	}
+	c = &PatternConfig{Mode: "any"}
+	if !MustMatch(r, MustCreatePattern(c)) {
+		t.Fatal(c)
+	}
+	c = &PatternConfig{
+		HeaderList: []string{"X-A:1", "X-B:3"},
+	}
+	if !MustMatch(r, MustCreatePattern(c)) {
+		t.Fatal(c)
+	}
+	c.FieldModes = map[string]string{FieldHeaders: "all"}
+	if MustMatch(r, MustCreatePattern(c)) {
+		t.Fatal(c)
+	}
+	c.HeaderList = []string{"X-A:1", "X-B:2"}
+	if !MustMatch(r, MustCreatePattern(c)) {
+		t.Fatal(c)
+	}
+	c = &PatternConfig{
+		KeywordList: []string{"admin", "trace"},
+		RegExpList:  []string{"^/admin", "/$"},
+		FieldModes:  map[string]string{FieldKeywords: "all", FieldRegExps: "all"},
+	}
+	if MustMatch(r, MustCreatePattern(c)) {
+		t.Fatal(c)
+	}
+	c.KeywordList = []string{"admin", "debug"}
+	if !MustMatch(r, MustCreatePattern(c)) {
+		t.Fatal(c)
+	}
+	_, err := (&PatternConfig{Mode: "none"}).CreatePattern()
+	if !errors.Is(err, ErrUnknownMatchMode) {
+		t.Fatal(err)
+	}
+	_, err = (&PatternConfig{FieldModes: map[string]string{"Unknown": "all"}}).CreatePattern()
+	if !errors.Is(err, ErrUnknownField) {
+		t.Fatal(err)
+	}
+	_, err = (&PatternConfig{FieldModes: map[string]string{FieldMethods: "all"}}).CreatePattern()
+	if !errors.Is(err, ErrMatchModeNotSupported) {
+		t.Fatal(err)
+	}
+	p := NewPlainPattern()
+	p.Methods.Add("GET")
+	p.FieldModes = map[string]MatchMode{FieldMethods: MatchModeAll}
+	_, err = p.MatchRequest(r)
+	if !errors.Is(err, ErrMatchModeNotSupported) {
+		t.Fatal(err)
+	}
+	p = &PlainPattern{}
+	if !MustMatch(r, p) {
+		t.Fatal(p)
+	}
+}
@@ -1,7 +1,10 @@
 package requestmatching
 
 import (
+	"fmt"
 	"net/http"
+
+	"github.com/herb-go/herb/middleware/router"
 )
 
 //MustMatch match request with given pattern
@@ -95,10 +98,217 @@ type PlainPattern struct {
 	RegExps      *RegExps
 	Headers      *Headers
 	ContentTypes *ContentTypes
-	Disabled     bool
-	Not          bool
-	And          bool
-	Patterns     []Pattern
+	Queries      *Queries
+	Cookies      *Cookies
+	RouterParams *RouterParams
+	UserAgents   *UserAgents
+	Globs        *Globs
+	Templates    *Templates
+	GeoIPs       *GeoIPs
+	//CaseSensitive whether Paths,Prefixs and Suffixs are matched case-sensitively.
+	//Records should be added by AddCaseSensitive.
+	CaseSensitive bool
+	Disabled      bool
+	Not           bool
+	And           bool
+	Patterns      []Pattern
+	//Mode mode combining configured fields.
+	//Fields use MatchModeAll if empty.
+	Mode MatchMode
+	//FieldModes mode combining records within field by field name.
+	//Records use MatchModeAny if empty.
+	FieldModes map[string]MatchMode
+}
+
+type field struct {
+	name    string
+	pattern Pattern
+}
+
+//fields return configured fields in evaluation order.
+func (p *PlainPattern) fields() []*field {
+	result := make([]*field, 0, len(Fields))
+	add := func(name string, pattern Pattern, size int) {
+		if size > 0 {
+			result = append(result, &field{name: name, pattern: pattern})
+		}
+	}
+	if p.IPNets != nil {
+		add(FieldIPNets, p.IPNets, len(*p.IPNets))
+	}
+	if p.Methods != nil {
+		add(FieldMethods, p.Methods, len(*p.Methods))
+	}
+	if p.Exts != nil {
+		add(FieldExts, p.Exts, len(*p.Exts))
+	}
+	if p.Paths != nil {
+		add(FieldPaths, p.pathsPattern(), len(*p.Paths))
+	}
+	if p.Prefixs != nil {
+		add(FieldPrefixs, p.prefixsPattern(), len(*p.Prefixs))
+	}
+	if p.Suffixs != nil {
+		add(FieldSuffixs, p.suffixsPattern(), len(*p.Suffixs))
+	}
+	if p.Keywords != nil {
+		add(FieldKeywords, p.Keywords, len(*p.Keywords))
+	}
+	if p.RegExps != nil {
+		add(FieldRegExps, p.RegExps, len(*p.RegExps))
+	}
+	if p.Headers != nil {
+		add(FieldHeaders, p.Headers, len(*p.Headers))
+	}
+	if p.ContentTypes != nil {
+		add(FieldContentTypes, p.ContentTypes, len(*p.ContentTypes))
+	}
+	if p.Queries != nil {
+		add(FieldQueries, p.Queries, len(*p.Queries))
+	}
+	if p.Cookies != nil {
+		add(FieldCookies, p.Cookies, len(*p.Cookies))
+	}
+	if p.RouterParams != nil {
+		add(FieldRouterParams, p.RouterParams, len(*p.RouterParams))
+	}
+	if p.UserAgents != nil {
+		add(FieldUserAgents, p.UserAgents, len(*p.UserAgents))
+	}
+	if p.Globs != nil {
+		add(FieldGlobs, p.Globs, len(*p.Globs))
+	}
+	if p.Templates != nil {
+		add(FieldTemplates, p.Templates, len(p.Templates.Data))
+	}
+	if p.GeoIPs != nil {
+		add(FieldGeoIPs, p.GeoIPs, p.GeoIPs.Len())
+	}
+	return result
+}
+
+func (p *PlainPattern) pathsPattern() Pattern {
+	if p.CaseSensitive {
+		return patternFunc(p.Paths.MatchRequestCaseSensitive)
+	}
+	return p.Paths
+}
+
+func (p *PlainPattern) prefixsPattern() Pattern {
+	if p.CaseSensitive {
+		return patternFunc(p.Prefixs.MatchRequestCaseSensitive)
+	}
+	return p.Prefixs
+}
+
+func (p *PlainPattern) suffixsPattern() Pattern {
+	if p.CaseSensitive {
+		return patternFunc(p.Suffixs.MatchRequestCaseSensitive)
+	}
+	return p.Suffixs
+}
+
+type patternFunc func(r *http.Request) (bool, error)
+
+func (f patternFunc) MatchRequest(r *http.Request) (bool, error) {
+	return f(r)
+}
+
+func (p *PlainPattern) fieldPattern(name string) (Pattern, bool) {
+	switch name {
+	case FieldIPNets:
+		return p.IPNets, true
+	case FieldMethods:
+		return p.Methods, true
+	case FieldExts:
+		return p.Exts, true
+	case FieldPaths:
+		return p.pathsPattern(), true
+	case FieldPrefixs:
+		return p.prefixsPattern(), true
+	case FieldSuffixs:
+		return p.suffixsPattern(), true
+	case FieldKeywords:
+		return p.Keywords, true
+	case FieldRegExps:
+		return p.RegExps, true
+	case FieldHeaders:
+		return p.Headers, true
+	case FieldContentTypes:
+		return p.ContentTypes, true
+	case FieldQueries:
+		return p.Queries, true
+	case FieldCookies:
+		return p.Cookies, true
+	case FieldRouterParams:
+		return p.RouterParams, true
+	case FieldUserAgents:
+		return p.UserAgents, true
+	case FieldGlobs:
+		return p.Globs, true
+	case FieldTemplates:
+		return p.Templates, true
+	case FieldGeoIPs:
+		return p.GeoIPs, true
+	}
+	return nil, false
+}
+
+//SetFieldMode set mode combining records within given field.
+//Return any error if raised.
+func (p *PlainPattern) SetFieldMode(name string, mode MatchMode) error {
+	pattern, ok := p.fieldPattern(name)
+	if !ok {
+		return fmt.Errorf("%w : \"%s\"", ErrUnknownField, name)
+	}
+	if mode == MatchModeAll {
+		if _, ok := pattern.(AllMatcher); !ok {
+			return fmt.Errorf("%w : \"%s\"", ErrMatchModeNotSupported, name)
+		}
+	}
+	if p.FieldModes == nil {
+		p.FieldModes = map[string]MatchMode{}
+	}
+	p.FieldModes[name] = mode
+	return nil
+}
+
+func (p *PlainPattern) matchField(r *http.Request, f *field) (bool, error) {
+	if p.FieldModes[f.name] == MatchModeAll {
+		m, ok := f.pattern.(AllMatcher)
+		if !ok {
+			return false, fmt.Errorf("%w : \"%s\"", ErrMatchModeNotSupported, f.name)
+		}
+		return m.MatchRequestAll(r)
+	}
+	return f.pattern.MatchRequest(r)
+}
+
+//matchFields match request with configured fields.
+//All fields will be evaluated if record is not nil.
+func (p *PlainPattern) matchFields(r *http.Request, record func(name string, result bool)) (bool, error) {
+	fields := p.fields()
+	if len(fields) == 0 {
+		return true, nil
+	}
+	anyMode := p.Mode == MatchModeAny
+	matched := !anyMode
+	for _, f := range fields {
+		result, err := p.matchField(r, f)
+		if err != nil {
+			return false, err
+		}
+		if record != nil {
+			record(f.name, result)
+		}
+		if result == anyMode {
+			matched = anyMode
+			if record == nil {
+				break
+			}
+		}
+	}
+	return matched, nil
 }
 
 //NewPlainPattern create new pattern struct.
@@ -114,6 +324,13 @@ func NewPlainPattern() *PlainPattern {
 		Headers:      NewHeaders(),
 		RegExps:      NewRegExps(),
 		ContentTypes: NewContentTypes(),
+		Queries:      NewQueries(),
+		Cookies:      NewCookies(),
+		RouterParams: NewRouterParams(),
+		UserAgents:   NewUserAgents(),
+		Globs:        NewGlobs(),
+		Templates:    NewTemplates(),
+		GeoIPs:       NewGeoIPs(nil),
 	}
 }
 
@@ -127,17 +344,37 @@ func (p *PlainPattern) MatchRequest(r *http.Request) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if result && !p.Not {
+		err = p.capture(r)
+		if err != nil {
+			return false, err
+		}
+	}
 	return result != p.Not, nil
 }
 
+//match match request without capturing params.
+func (p *PlainPattern) match(r *http.Request) (bool, error) {
+	if p.Disabled {
+		return false, nil
+	}
+	result, err := p.matchRequest(r)
+	if err != nil {
+		return false, err
+	}
+	return result != p.Not, nil
+}
+
+//matchSubPattern match sub pattern,plain pattern is matched without capturing params.
+func matchSubPattern(r *http.Request, pattern Pattern) (bool, error) {
+	if sub, ok := pattern.(*PlainPattern); ok {
+		return sub.match(r)
+	}
+	return pattern.MatchRequest(r)
+}
+
 func (p *PlainPattern) matchRequest(r *http.Request) (bool, error) {
-	result, err := MatchAll(r,
-		p.IPNets,
-		p.Methods,
-		p.Exts,
-		p.Paths,
-		p.Prefixs,
-	)
+	result, err := p.matchFields(r, nil)
 	if err != nil {
 		return false, err
 	}
@@ -145,12 +382,79 @@ func (p *PlainPattern) matchRequest(r *http.Request) (bool, error) {
 		return result, nil
 	}
 	if p.And {
-		return MatchAll(r, p.Patterns...)
+		for _, v := range p.Patterns {
+			ok, err := matchSubPattern(r, v)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
 	}
-	if result == true {
+	if result == true || len(p.Patterns) == 0 {
 		return true, nil
 	}
-	return MatchAny(r, p.Patterns...)
+	for _, v := range p.Patterns {
+		ok, err := matchSubPattern(r, v)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+//capturing return whether params of pattern or sub patterns should be captured.
+func (p *PlainPattern) capturing() bool {
+	if p.Templates != nil && p.Templates.Capture && len(p.Templates.Data) > 0 {
+		return true
+	}
+	for _, v := range p.Patterns {
+		if sub, ok := v.(*PlainPattern); ok && sub.capturing() {
+			return true
+		}
+	}
+	return false
+}
+
+//capture set params of matched templates to router params of request.
+//Should be called only after whole pattern matched.
+//Params of matched sub patterns are captured too.
+//Return any error if raised.
+func (p *PlainPattern) capture(r *http.Request) error {
+	if !p.capturing() {
+		return nil
+	}
+	if p.Templates != nil && p.Templates.Capture && len(p.Templates.Data) > 0 {
+		var params router.Params
+		var ok bool
+		if p.FieldModes[FieldTemplates] == MatchModeAll {
+			params, ok = p.Templates.ParamsAll(r)
+		} else {
+			params, ok = p.Templates.Params(r)
+		}
+		if ok {
+			rp := router.GetParams(r)
+			for _, param := range params {
+				rp.Set(param.Name, param.Value)
+			}
+		}
+	}
+	for _, v := range p.Patterns {
+		sub, ok := v.(*PlainPattern)
+		if !ok || sub.Not || !sub.capturing() {
+			continue
+		}
+		matched, err := sub.match(r)
+		if err != nil {
+			return err
+		}
+		if matched {
+			err = sub.capture(r)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 //PatternConfig plainpattern config struct
@@ -165,10 +469,43 @@ type PatternConfig struct {
 	RegExpList      []string
 	HeaderList      []string
 	ContentTypeList []string
-	Disabled        bool
-	Not             bool
-	And             bool
-	Patterns        []*PatternConfig
+	//QueryList query patterns in "key","key=value","key^=prefix" or "key~=regexp" form
+	QueryList []string
+	//CookieList cookie patterns in "name","name=value","name^=prefix" or "name~=regexp" form
+	CookieList []string
+	//ParamList router param patterns in "name","name=value","name^=prefix" or "name~=regexp" form
+	ParamList []string
+	//UserAgentList user agent family names
+	UserAgentList []string
+	//GlobList path glob patterns,hostname can be set before first "/"
+	GlobList []string
+	//TemplateList router style path templates,hostname can be set before first "/"
+	TemplateList []string
+	//CaptureParams whether params of matched template are set to router params
+	CaptureParams bool
+	//CaseSensitive whether urls,prefixs,suffixs,globs and templates are matched case-sensitively
+	CaseSensitive bool
+	//CountryList ISO 3166-1 alpha-2 country codes,GeoIPDatabase required
+	CountryList []string
+	//ContinentList continent codes,GeoIPDatabase required
+	ContinentList []string
+	//ASNList autonomous system numbers,ASNDatabase or GeoIPDatabase required
+	ASNList []string
+	//GeoIPDatabase path of MaxMind-format country or city database
+	GeoIPDatabase string
+	//ASNDatabase path of MaxMind-format asn database
+	ASNDatabase string
+	Disabled    bool
+	Not         bool
+	And         bool
+	Patterns    []*PatternConfig
+	//Mode mode combining configured fields,"all" or "any".
+	//"all" will be used if empty.
+	Mode string
+	//FieldModes mode combining records within field by field name,"any" or "all".
+	//"any" will be used if empty.
+	//"all" mode is supported by Keywords,RegExps,Headers,Queries,Cookies and RouterParams.
+	FieldModes map[string]string
 }
 
 //CreatePattern create plain pattern.
@@ -181,14 +518,64 @@ func (c *PatternConfig) CreatePattern() (Pattern, error) {
 			return nil, err
 		}
 	}
+	p.CaseSensitive = c.CaseSensitive
 	for k := range c.URLList {
-		p.Paths.Add(c.URLList[k])
+		if c.CaseSensitive {
+			p.Paths.AddCaseSensitive(c.URLList[k])
+		} else {
+			p.Paths.Add(c.URLList[k])
+		}
 	}
 	for k := range c.PrefixList {
-		p.Prefixs.Add(c.PrefixList[k])
+		if c.CaseSensitive {
+			p.Prefixs.AddCaseSensitive(c.PrefixList[k])
+		} else {
+			p.Prefixs.Add(c.PrefixList[k])
+		}
 	}
 	for k := range c.SuffixList {
-		p.Suffixs.Add(c.SuffixList[k])
+		if c.CaseSensitive {
+			p.Suffixs.AddCaseSensitive(c.SuffixList[k])
+		} else {
+			p.Suffixs.Add(c.SuffixList[k])
+		}
+	}
+	for k := range c.GlobList {
+		var err error
+		if c.CaseSensitive {
+			err = p.Globs.AddCaseSensitive(c.GlobList[k])
+		} else {
+			err = p.Globs.Add(c.GlobList[k])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for k := range c.TemplateList {
+		var err error
+		if c.CaseSensitive {
+			err = p.Templates.AddCaseSensitive(c.TemplateList[k])
+		} else {
+			err = p.Templates.Add(c.TemplateList[k])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.Templates.Capture = c.CaptureParams
+	if len(c.CountryList) > 0 || len(c.ContinentList) > 0 || len(c.ASNList) > 0 {
+		gc := &GeoIPConfig{
+			CountryList:   c.CountryList,
+			ContinentList: c.ContinentList,
+			ASNList:       c.ASNList,
+			GeoIPDatabase: c.GeoIPDatabase,
+			ASNDatabase:   c.ASNDatabase,
+		}
+		g, err := gc.CreateGeoIPs()
+		if err != nil {
+			return nil, err
+		}
+		p.GeoIPs = g
 	}
 	for k := range c.KeywordList {
 		p.Keywords.Add(c.KeywordList[k])
@@ -214,9 +601,48 @@ func (c *PatternConfig) CreatePattern() (Pattern, error) {
 	for k := range c.ContentTypeList {
 		p.ContentTypes.Add(c.ContentTypeList[k])
 	}
+	for k := range c.QueryList {
+		err := p.Queries.Add(c.QueryList[k])
+		if err != nil {
+			return nil, err
+		}
+	}
+	for k := range c.CookieList {
+		err := p.Cookies.Add(c.CookieList[k])
+		if err != nil {
+			return nil, err
+		}
+	}
+	for k := range c.ParamList {
+		err := p.RouterParams.Add(c.ParamList[k])
+		if err != nil {
+			return nil, err
+		}
+	}
+	for k := range c.UserAgentList {
+		err := p.UserAgents.Add(c.UserAgentList[k])
+		if err != nil {
+			return nil, err
+		}
+	}
 	p.Disabled = c.Disabled
 	p.Not = c.Not
 	p.And = c.And
+	mode, err := ParseMatchMode(c.Mode)
+	if err != nil {
+		return nil, err
+	}
+	p.Mode = mode
+	for name, v := range c.FieldModes {
+		mode, err := ParseMatchMode(v)
+		if err != nil {
+			return nil, err
+		}
+		err = p.SetFieldMode(name, mode)
+		if err != nil {
+			return nil, err
+		}
+	}
 	for k := range c.Patterns {
 		pattern, err := c.Patterns[k].CreatePattern()
 		if err != nil {
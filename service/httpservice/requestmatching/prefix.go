@@ -18,42 +18,58 @@ func (p PrefixData) Has(requesturi string) bool {
 	return false
 }
 
-//Prefixs prefixs pattern struct
+//Prefixs prefixs pattern struct.
+//Hostname keys support service.HostPattern wildcards.
+//Exact hostname keys are looked up directly,only wildcard keys are iterated.
 type Prefixs map[string]PrefixData
 
-//MatchRequest match request.
-//Return result and any error if raised.
-func (p Prefixs) MatchRequest(r *http.Request) (bool, error) {
-	var data PrefixData
+func (p Prefixs) match(r *http.Request, caseSensitive bool) bool {
 	if len(p) == 0 {
-		return true, nil
+		return true
 	}
-	urlpath := strings.ToLower(r.URL.Path)
-	h := r.Host
-	if h != "" {
-		data = p[h]
-		if data != nil && data.Has(urlpath) {
-			return true, nil
-
-		}
+	urlpath := r.URL.Path
+	if !caseSensitive {
+		urlpath = strings.ToLower(urlpath)
 	}
-	data = p[""]
-	if data == nil {
-		return false, nil
+	host, hostname := requestHosts(r)
+	if p[host].Has(urlpath) || (hostname != host && p[hostname].Has(urlpath)) {
+		return true
 	}
+	for h, data := range p {
+		if isHostWildcard(h) && matchHostPattern(h, host, hostname) && data.Has(urlpath) {
+			return true
+		}
+	}
+	return p[""].Has(urlpath)
+}
+
+//MatchRequest match request case-insensitively.
+//Return result and any error if raised.
+func (p Prefixs) MatchRequest(r *http.Request) (bool, error) {
+	return p.match(r, false), nil
+}
 
-	return data.Has(r.URL.Path), nil
+//MatchRequestCaseSensitive match request case-sensitively.
+//Return result and any error if raised.
+func (p Prefixs) MatchRequestCaseSensitive(r *http.Request) (bool, error) {
+	return p.match(r, true), nil
 }
 
-//Add add url to prefixs
-//Any string before first "/" will be used as hostname
+//Add add url to prefixs.
+//Any string before first "/" will be used as hostname.
+//Url will be converted to lower and matched case-insensitively.
 func (p Prefixs) Add(url string) {
-	host, prefix := splitURL(url)
-	data := p[host]
-	if data == nil {
-		data = PrefixData{}
-		p[host] = data
-	}
+	p.add(url, false)
+}
+
+//AddCaseSensitive add url to prefixs with path case kept.
+//Pattern should be matched by MatchRequestCaseSensitive.
+func (p Prefixs) AddCaseSensitive(url string) {
+	p.add(url, true)
+}
+
+func (p Prefixs) add(url string, caseSensitive bool) {
+	host, prefix := splitURL(url, caseSensitive)
 	p[host] = append(p[host], prefix)
 }
 
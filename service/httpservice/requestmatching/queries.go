@@ -0,0 +1,42 @@
+package requestmatching
+
+import (
+	"net/http"
+)
+
+//Queries query pattern
+type Queries []*ValueData
+
+//Add add query to pattern.
+//Query should be in "key","key=value","key^=prefix" or "key~=regexp" form.
+//Return error if query is not validated.
+func (q *Queries) Add(query string) error {
+	d, err := ParseValueData(query, ErrQueryNotValidated)
+	if err != nil {
+		return err
+	}
+	*q = append(*q, d)
+	return nil
+}
+
+//MatchRequest match request.
+//Return result and any error if raised.
+func (q *Queries) MatchRequest(r *http.Request) (bool, error) {
+	if len(*q) == 0 {
+		return true, nil
+	}
+	query := r.URL.Query()
+	return matchValues(*q, func(key string) []string { return query[key] }, false), nil
+}
+
+//MatchRequestAll match request with all queries.
+//Return result and any error if raised.
+func (q *Queries) MatchRequestAll(r *http.Request) (bool, error) {
+	query := r.URL.Query()
+	return matchValues(*q, func(key string) []string { return query[key] }, true), nil
+}
+
+//NewQueries create new queries pattern.
+func NewQueries() *Queries {
+	return &Queries{}
+}
@@ -0,0 +1,66 @@
+package requestmatching
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestQueries(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://127.0.0.1/?a=1&b=prefix-value&c=&c=123", nil)
+	q := NewQueries()
+	if !MustMatch(r, q) {
+		t.Fatal(q)
+	}
+	for _, pattern := range []string{"", "=1", " ^=1", "a~=("} {
+		err := q.Add(pattern)
+		if !errors.Is(err, ErrQueryNotValidated) {
+			t.Fatal(pattern, err)
+		}
+	}
+	for pattern, expected := range map[string]bool{
+		"a":           true,
+		"d":           false,
+		"c":           true,
+		"a=1":         true,
+		"a = 2":       false,
+		"b^=prefix":   true,
+		"b^=value":    false,
+		"c~=^[0-9]+$": true,
+		"a~=^[a-z]+$": false,
+	} {
+		q = NewQueries()
+		err := q.Add(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if MustMatch(r, q) != expected {
+			t.Fatal(pattern)
+		}
+	}
+	q = NewQueries()
+	q.Add("a=1")
+	q.Add("d")
+	if !MustMatch(r, q) {
+		t.Fatal(q)
+	}
+	ok, err := q.MatchRequestAll(r)
+	if ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	q = NewQueries()
+	q.Add("a=1")
+	q.Add("b^=prefix")
+	ok, err = q.MatchRequestAll(r)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	p := MustCreatePattern(&PatternConfig{QueryList: []string{"a=2", "b^=prefix"}, FieldModes: map[string]string{FieldQueries: "all"}})
+	if MustMatch(r, p) {
+		t.Fatal(p)
+	}
+	_, err = (&PatternConfig{QueryList: []string{"=1"}}).CreatePattern()
+	if !errors.Is(err, ErrQueryNotValidated) {
+		t.Fatal(err)
+	}
+}
@@ -22,6 +22,17 @@ func (r *RegExps) MatchRequest(req *http.Request) (bool, error) {
 	return false, nil
 }
 
+//MatchRequestAll match request with all regexps.
+//Return result and any error if raised.
+func (r *RegExps) MatchRequestAll(req *http.Request) (bool, error) {
+	for i := range *r {
+		if !(*r)[i].Match([]byte(req.URL.Path)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 //Add add pattern to regexps.
 //Return any error if raised.
 func (r *RegExps) Add(pattern string) error {
@@ -0,0 +1,116 @@
+package requestmatching
+
+import (
+	"regexp"
+	"strings"
+)
+
+//regexSetFanout max children count of regex set node
+const regexSetFanout = 8
+
+//regexSet regexp set finding all regexps matching text.
+//Regexps are grouped into a tree of combined regexps,
+//so groups without any match are skipped with one combined match.
+type regexSet struct {
+	regexps []*regexp.Regexp
+	root    *regexNode
+}
+
+type regexNode struct {
+	//combined alternation of all regexps in node.
+	//Nil if combined regexp can not be compiled.
+	combined *regexp.Regexp
+	children []*regexNode
+	//terms regexp terms of leaf node
+	terms []int
+}
+
+func combineRegexps(list []*regexp.Regexp, terms []int) *regexp.Regexp {
+	sources := make([]string, len(terms))
+	for k, v := range terms {
+		sources[k] = list[v].String()
+	}
+	re, err := regexp.Compile("(?:" + strings.Join(sources, ")|(?:") + ")")
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+func newRegexSet(list []*regexp.Regexp) *regexSet {
+	s := &regexSet{regexps: list}
+	if len(list) == 0 {
+		return s
+	}
+	nodes := []*regexNode{}
+	for start := 0; start < len(list); start += regexSetFanout {
+		end := start + regexSetFanout
+		if end > len(list) {
+			end = len(list)
+		}
+		n := &regexNode{}
+		for i := start; i < end; i++ {
+			n.terms = append(n.terms, i)
+		}
+		nodes = append(nodes, n)
+	}
+	for {
+		for _, n := range nodes {
+			if n.combined == nil && len(n.terms) > 1 {
+				n.combined = combineRegexps(list, n.terms)
+			}
+		}
+		if len(nodes) == 1 {
+			break
+		}
+		parents := []*regexNode{}
+		for start := 0; start < len(nodes); start += regexSetFanout {
+			end := start + regexSetFanout
+			if end > len(nodes) {
+				end = len(nodes)
+			}
+			p := &regexNode{children: nodes[start:end]}
+			terms := []int{}
+			for _, c := range p.children {
+				terms = append(terms, c.allTerms()...)
+			}
+			p.combined = combineRegexps(list, terms)
+			parents = append(parents, p)
+		}
+		nodes = parents
+	}
+	s.root = nodes[0]
+	return s
+}
+
+func (n *regexNode) allTerms() []int {
+	if n.children == nil {
+		return n.terms
+	}
+	result := []int{}
+	for _, c := range n.children {
+		result = append(result, c.allTerms()...)
+	}
+	return result
+}
+
+func (s *regexSet) matchNode(n *regexNode, text string, emit func(term int)) {
+	if n.combined != nil && !n.combined.MatchString(text) {
+		return
+	}
+	for _, term := range n.terms {
+		if s.regexps[term].MatchString(text) {
+			emit(term)
+		}
+	}
+	for _, c := range n.children {
+		s.matchNode(c, text, emit)
+	}
+}
+
+//match emit terms of all regexps matching text.
+func (s *regexSet) match(text string, emit func(term int)) {
+	if s.root != nil {
+		s.matchNode(s.root, text, emit)
+	}
+}
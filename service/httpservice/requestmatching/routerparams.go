@@ -0,0 +1,55 @@
+package requestmatching
+
+import (
+	"net/http"
+
+	"github.com/herb-go/herb/middleware/router"
+)
+
+//RouterParams router params pattern.
+//Params should be set by router before matching.
+type RouterParams []*ValueData
+
+//Add add router param to pattern.
+//Param should be in "name","name=value","name^=prefix" or "name~=regexp" form.
+//Param without value matches any non-empty value.
+//Return error if param is not validated.
+func (p *RouterParams) Add(param string) error {
+	d, err := ParseValueData(param, ErrParamNotValidated)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, d)
+	return nil
+}
+
+func routerParamValues(r *http.Request) func(name string) []string {
+	params, _ := r.Context().Value(router.ContextNameRouterParams).(*router.Params)
+	return func(name string) []string {
+		v := params.Get(name)
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	}
+}
+
+//MatchRequest match request.
+//Return result and any error if raised.
+func (p *RouterParams) MatchRequest(r *http.Request) (bool, error) {
+	if len(*p) == 0 {
+		return true, nil
+	}
+	return matchValues(*p, routerParamValues(r), false), nil
+}
+
+//MatchRequestAll match request with all router params.
+//Return result and any error if raised.
+func (p *RouterParams) MatchRequestAll(r *http.Request) (bool, error) {
+	return matchValues(*p, routerParamValues(r), true), nil
+}
+
+//NewRouterParams create new router params pattern.
+func NewRouterParams() *RouterParams {
+	return &RouterParams{}
+}
@@ -0,0 +1,54 @@
+package requestmatching
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/herb-go/herb/middleware/router"
+)
+
+func TestRouterParams(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://127.0.0.1/", nil)
+	p := NewRouterParams()
+	p.Add("id")
+	if MustMatch(r, p) {
+		t.Fatal(p)
+	}
+	params := router.GetParams(r)
+	params.Set("id", "123")
+	params.Set("name", "")
+	err := p.Add("~=1")
+	if !errors.Is(err, ErrParamNotValidated) {
+		t.Fatal(err)
+	}
+	for pattern, expected := range map[string]bool{
+		"id":           true,
+		"name":         false,
+		"missing":      false,
+		"id=123":       true,
+		"id=12":        false,
+		"id^=12":       true,
+		"id~=^[0-9]+$": true,
+	} {
+		p = NewRouterParams()
+		err := p.Add(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if MustMatch(r, p) != expected {
+			t.Fatal(pattern)
+		}
+	}
+	p = NewRouterParams()
+	p.Add("id^=1")
+	p.Add("id~=3$")
+	ok, err := p.MatchRequestAll(r)
+	if !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	pattern := MustCreatePattern(&PatternConfig{ParamList: []string{"id=1"}})
+	if MustMatch(r, pattern) {
+		t.Fatal(pattern)
+	}
+}
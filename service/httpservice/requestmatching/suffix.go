@@ -5,10 +5,10 @@ import (
 	"strings"
 )
 
-//SuffixData prefix data struct.
+//SuffixData suffix data struct.
 type SuffixData []string
 
-//Has check is requesturi is start with any record in prefix data.
+//Has check is requesturi is end with any record in suffix data.
 func (p SuffixData) Has(requesturi string) bool {
 	for k := range p {
 		if strings.HasSuffix(requesturi, p[k]) {
@@ -18,48 +18,64 @@ func (p SuffixData) Has(requesturi string) bool {
 	return false
 }
 
-//Suffixs prefixs pattern struct
+//Suffixs suffixs pattern struct.
+//Hostname keys support service.HostPattern wildcards.
+//Exact hostname keys are looked up directly,only wildcard keys are iterated.
 type Suffixs map[string]SuffixData
 
-//MatchRequest match request.
-//Return result and any error if raised.
-func (p Suffixs) MatchRequest(r *http.Request) (bool, error) {
-	var data SuffixData
+func (p Suffixs) match(r *http.Request, caseSensitive bool) bool {
 	if len(p) == 0 {
-		return true, nil
+		return true
 	}
-	urlpath := strings.ToLower(r.URL.Path)
-	h := r.Host
-	if h != "" {
-		data = p[h]
-		if data != nil && data.Has(urlpath) {
-			return true, nil
-
-		}
+	urlpath := r.URL.Path
+	if !caseSensitive {
+		urlpath = strings.ToLower(urlpath)
 	}
-	data = p[""]
-	if data == nil {
-		return false, nil
+	host, hostname := requestHosts(r)
+	if p[host].Has(urlpath) || (hostname != host && p[hostname].Has(urlpath)) {
+		return true
 	}
+	for h, data := range p {
+		if isHostWildcard(h) && matchHostPattern(h, host, hostname) && data.Has(urlpath) {
+			return true
+		}
+	}
+	return p[""].Has(urlpath)
+}
+
+//MatchRequest match request case-insensitively.
+//Return result and any error if raised.
+func (p Suffixs) MatchRequest(r *http.Request) (bool, error) {
+	return p.match(r, false), nil
+}
 
-	return data.Has(r.URL.Path), nil
+//MatchRequestCaseSensitive match request case-sensitively.
+//Return result and any error if raised.
+func (p Suffixs) MatchRequestCaseSensitive(r *http.Request) (bool, error) {
+	return p.match(r, true), nil
 }
 
-//Add add url to prefixs
-//Any string before first "/" will be used as hostname
+//Add add url to suffixs.
+//Any string before first "/" will be used as hostname.
+//Url will be converted to lower and matched case-insensitively.
 func (p Suffixs) Add(url string) {
-	host, suffix := splitURL(url)
+	p.add(url, false)
+}
+
+//AddCaseSensitive add url to suffixs with path case kept.
+//Pattern should be matched by MatchRequestCaseSensitive.
+func (p Suffixs) AddCaseSensitive(url string) {
+	p.add(url, true)
+}
+
+func (p Suffixs) add(url string, caseSensitive bool) {
+	host, suffix := splitURL(url, caseSensitive)
 	//remove first "/"
 	suffix = suffix[1:]
-	data := p[host]
-	if data == nil {
-		data = SuffixData{}
-		p[host] = data
-	}
 	p[host] = append(p[host], suffix)
 }
 
-//NewSuffixs create new prefixs pattern.
+//NewSuffixs create new suffixs pattern.
 func NewSuffixs() *Suffixs {
 	return &Suffixs{}
 }
@@ -0,0 +1,168 @@
+package requestmatching
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/herb-go/herb/middleware/router"
+)
+
+//TemplateData path template data struct.
+//Segment starts with ":" matches one non-empty path segment,
+//last segment starts with "*" matches rest of path.
+type TemplateData struct {
+	//Host host pattern.
+	//Any host matches if empty.
+	Host string
+	//Template path template
+	Template string
+	//CaseSensitive whether static segments are matched case-sensitively
+	CaseSensitive bool
+	segments      []string
+}
+
+//Match match path with template.
+//Return captured params and whether path matched.
+func (t *TemplateData) Match(path string) (router.Params, bool) {
+	var params router.Params
+	parts := strings.Split(path, "/")
+	for k, s := range t.segments {
+		if s != "" && s[0] == '*' {
+			if k >= len(parts) {
+				return nil, false
+			}
+			params = append(params, router.Param{Name: s[1:], Value: "/" + strings.Join(parts[k:], "/")})
+			return params, true
+		}
+		if k >= len(parts) {
+			return nil, false
+		}
+		if s != "" && s[0] == ':' {
+			if parts[k] == "" {
+				return nil, false
+			}
+			params = append(params, router.Param{Name: s[1:], Value: parts[k]})
+			continue
+		}
+		if s != parts[k] && (t.CaseSensitive || !strings.EqualFold(s, parts[k])) {
+			return nil, false
+		}
+	}
+	if len(parts) != len(t.segments) {
+		return nil, false
+	}
+	return params, true
+}
+
+//ParseTemplate parse path template.
+//Return template data and any error if raised.
+func ParseTemplate(template string, caseSensitive bool) (*TemplateData, error) {
+	if template == "" {
+		return nil, fmt.Errorf("%w : \"%s\"", ErrTemplateNotValidated, template)
+	}
+	host, path := splitURL(template, true)
+	t := &TemplateData{
+		Host:          host,
+		Template:      path,
+		CaseSensitive: caseSensitive,
+		segments:      strings.Split(path, "/"),
+	}
+	for k, s := range t.segments {
+		if s == ":" || s == "*" || (strings.HasPrefix(s, "*") && k != len(t.segments)-1) {
+			return nil, fmt.Errorf("%w : \"%s\"", ErrTemplateNotValidated, template)
+		}
+	}
+	return t, nil
+}
+
+//Templates path templates pattern
+type Templates struct {
+	//Capture whether params of matched templates are set to router params of request
+	//by plain pattern after whole pattern matched.
+	Capture bool
+	//Data templates data
+	Data []*TemplateData
+}
+
+//Add add template to pattern.
+//Any string before first "/" will be used as host pattern.
+//Static segments will be matched case-insensitively.
+//Return any error if raised.
+func (t *Templates) Add(template string) error {
+	return t.add(template, false)
+}
+
+//AddCaseSensitive add template to pattern which will be matched case-sensitively.
+//Return any error if raised.
+func (t *Templates) AddCaseSensitive(template string) error {
+	return t.add(template, true)
+}
+
+func (t *Templates) add(template string, caseSensitive bool) error {
+	data, err := ParseTemplate(template, caseSensitive)
+	if err != nil {
+		return err
+	}
+	t.Data = append(t.Data, data)
+	return nil
+}
+
+//Params match request and return params of first matched template.
+//Request will not be modified.
+//Return params and whether any template matched.
+func (t *Templates) Params(r *http.Request) (router.Params, bool) {
+	host, hostname := requestHosts(r)
+	for _, v := range t.Data {
+		if v.Host != "" && !matchHostPattern(v.Host, host, hostname) {
+			continue
+		}
+		params, ok := v.Match(r.URL.Path)
+		if ok {
+			return params, true
+		}
+	}
+	return nil, false
+}
+
+//ParamsAll match request with all templates and return params of all templates.
+//Request will not be modified.
+//Return params and whether all templates matched.
+func (t *Templates) ParamsAll(r *http.Request) (router.Params, bool) {
+	host, hostname := requestHosts(r)
+	var result router.Params
+	for _, v := range t.Data {
+		if v.Host != "" && !matchHostPattern(v.Host, host, hostname) {
+			return nil, false
+		}
+		params, ok := v.Match(r.URL.Path)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, params...)
+	}
+	return result, true
+}
+
+//MatchRequest match request.
+//Params are not captured,they are set by plain pattern after whole pattern matched.
+//Return result and any error if raised.
+func (t *Templates) MatchRequest(r *http.Request) (bool, error) {
+	if len(t.Data) == 0 {
+		return true, nil
+	}
+	_, ok := t.Params(r)
+	return ok, nil
+}
+
+//MatchRequestAll match request with all templates.
+//Return result and any error if raised.
+func (t *Templates) MatchRequestAll(r *http.Request) (bool, error) {
+	_, ok := t.ParamsAll(r)
+	return ok, nil
+}
+
+//NewTemplates create new templates pattern.
+func NewTemplates() *Templates {
+	return &Templates{}
+}
@@ -0,0 +1,164 @@
+package requestmatching
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/herb-go/herb/middleware/router"
+)
+
+func TestParseTemplate(t *testing.T) {
+	for _, template := range []string{"", "/a/:", "/*", "/*rest/a"} {
+		_, err := ParseTemplate(template, false)
+		if !errors.Is(err, ErrTemplateNotValidated) {
+			t.Fatal(template, err)
+		}
+	}
+	for template, paths := range map[string]map[string]bool{
+		"/users/:id/posts": {"/users/1/posts": true, "/Users/1/Posts": true, "/users//posts": false, "/users/1/posts/": false, "/users/1": false},
+		"/files/*path":     {"/files/a/b": true, "/files/": true, "/files": false},
+		"/":                {"/": true, "/a": false},
+	} {
+		data, err := ParseTemplate(template, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for path, expected := range paths {
+			_, ok := data.Match(path)
+			if ok != expected {
+				t.Fatal(template, path)
+			}
+		}
+	}
+	data, err := ParseTemplate("/Users/:id", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := data.Match("/users/1"); ok {
+		t.Fatal(data)
+	}
+	params, ok := data.Match("/Users/1")
+	if !ok || params.Get("id") != "1" {
+		t.Fatal(params)
+	}
+	data, _ = ParseTemplate("/files/*path", false)
+	params, _ = data.Match("/files/a/b")
+	if params.Get("path") != "/a/b" {
+		t.Fatal(params)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://api.example.com/users/12/posts", nil)
+	tp := NewTemplates()
+	if !MustMatch(r, tp) {
+		t.Fatal(tp)
+	}
+	tp.Add("/users/:id")
+	tp.Add("other.com/users/:id/posts")
+	if MustMatch(r, tp) {
+		t.Fatal(tp)
+	}
+	tp.Add(".example.com/users/:id/:type")
+	if !MustMatch(r, tp) {
+		t.Fatal(tp)
+	}
+	if router.GetParams(r).Get("id") != "" {
+		t.Fatal(router.GetParams(r))
+	}
+	tp.Capture = true
+	if !MustMatch(r, tp) {
+		t.Fatal(tp)
+	}
+	if router.GetParams(r).Get("id") != "" {
+		t.Fatal(router.GetParams(r))
+	}
+	params, ok := tp.Params(r)
+	if !ok || params.Get("id") != "12" || params.Get("type") != "posts" {
+		t.Fatal(params)
+	}
+	r, _ = http.NewRequest("GET", "http://api.example.com/users/12/posts", nil)
+	p := MustCreatePattern(&PatternConfig{
+		TemplateList:  []string{"/users/:id/posts", "/:type/:name/posts"},
+		CaptureParams: true,
+		FieldModes:    map[string]string{FieldTemplates: "all"},
+	})
+	if !MustMatch(r, p) {
+		t.Fatal(p)
+	}
+	rp := router.GetParams(r)
+	if rp.Get("id") != "12" || rp.Get("type") != "users" || rp.Get("name") != "12" {
+		t.Fatal(rp)
+	}
+	p = MustCreatePattern(&PatternConfig{
+		TemplateList: []string{"/users/:id/posts", "/:id"},
+		FieldModes:   map[string]string{FieldTemplates: "all"},
+	})
+	if MustMatch(r, p) {
+		t.Fatal(p)
+	}
+}
+
+func TestTemplatesCaptureAfterMatched(t *testing.T) {
+	newRequest := func() *http.Request {
+		r, _ := http.NewRequest("POST", "http://www.example.com/users/12", nil)
+		router.GetParams(r).Set("id", "origin")
+		return r
+	}
+	var tests = []*PatternConfig{
+		{TemplateList: []string{"/users/:id"}, MethodList: []string{"GET"}, CaptureParams: true},
+		{TemplateList: []string{"/users/:id"}, CaptureParams: true, Not: true},
+		{TemplateList: []string{"/users/:id"}, CaptureParams: true, Disabled: true},
+		{Patterns: []*PatternConfig{{TemplateList: []string{"/users/:id"}, CaptureParams: true}, {MethodList: []string{"GET"}}}, And: true},
+		{MethodList: []string{"POST"}, Patterns: []*PatternConfig{{TemplateList: []string{"/users/:id"}, CaptureParams: true}}, And: true, Not: true},
+	}
+	for _, v := range tests {
+		p := MustCreatePattern(v)
+		r := newRequest()
+		MustMatch(r, p)
+		if router.GetParams(r).Get("id") != "origin" {
+			t.Fatal(v, router.GetParams(r))
+		}
+		r = newRequest()
+		_, err := Explain(r, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if router.GetParams(r).Get("id") != "origin" {
+			t.Fatal(v, router.GetParams(r))
+		}
+		m, err := NewMatcher([]*PatternConfig{v}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = newRequest()
+		_, err = m.MatchIDs(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if router.GetParams(r).Get("id") != "origin" {
+			t.Fatal(v, router.GetParams(r))
+		}
+	}
+	var matched = []*PatternConfig{
+		{TemplateList: []string{"/users/:id"}, MethodList: []string{"POST"}, CaptureParams: true},
+		{Patterns: []*PatternConfig{{TemplateList: []string{"/users/:id"}, CaptureParams: true}, {MethodList: []string{"POST"}}}, And: true},
+	}
+	for _, v := range matched {
+		p := MustCreatePattern(v)
+		r := newRequest()
+		if !MustMatch(r, p) || router.GetParams(r).Get("id") != "12" {
+			t.Fatal(v, router.GetParams(r))
+		}
+		m, err := NewMatcher([]*PatternConfig{v}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = newRequest()
+		ids, err := m.MatchIDs(r)
+		if err != nil || len(ids) != 1 || router.GetParams(r).Get("id") != "12" {
+			t.Fatal(v, ids, err, router.GetParams(r))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package requestmatching
+
+//byteTrie byte trie storing term ids at end of keys.
+//Keys can be stored reversed for suffix matching.
+type byteTrie struct {
+	nodes []trieNode
+}
+
+type trieNode struct {
+	children map[byte]int
+	terms    []int
+}
+
+func newByteTrie() *byteTrie {
+	return &byteTrie{nodes: []trieNode{{}}}
+}
+
+func (t *byteTrie) add(key string, term int, reverse bool) {
+	n := 0
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if reverse {
+			c = key[len(key)-1-i]
+		}
+		next, ok := t.nodes[n].children[c]
+		if !ok {
+			if t.nodes[n].children == nil {
+				t.nodes[n].children = map[byte]int{}
+			}
+			next = len(t.nodes)
+			t.nodes[n].children[c] = next
+			t.nodes = append(t.nodes, trieNode{})
+		}
+		n = next
+	}
+	t.nodes[n].terms = append(t.nodes[n].terms, term)
+}
+
+//walk emit terms of all keys which are prefixs of s,or suffixs if reverse.
+func (t *byteTrie) walk(s string, reverse bool, emit func(term int)) {
+	n := 0
+	for i := 0; ; i++ {
+		for _, term := range t.nodes[n].terms {
+			emit(term)
+		}
+		if i == len(s) {
+			return
+		}
+		c := s[i]
+		if reverse {
+			c = s[len(s)-1-i]
+		}
+		next, ok := t.nodes[n].children[c]
+		if !ok {
+			return
+		}
+		n = next
+	}
+}
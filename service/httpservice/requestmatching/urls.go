@@ -8,13 +8,26 @@ import (
 //PathData path data type
 type PathData map[string]bool
 
-//Paths paths pattern struct
+//Paths paths pattern struct.
+//Hostname keys support service.HostPattern wildcards.
+//Exact hostname keys are looked up directly,only wildcard keys are iterated.
 type Paths map[string]PathData
 
-//Add add url to paths pattern
-//Any string before first "/" will be used as hostname
+//Add add url to paths pattern.
+//Any string before first "/" will be used as hostname.
+//Url will be converted to lower and matched case-insensitively.
 func (p Paths) Add(url string) {
-	host, path := splitURL(url)
+	p.add(url, false)
+}
+
+//AddCaseSensitive add url to paths pattern with path case kept.
+//Pattern should be matched by MatchRequestCaseSensitive.
+func (p Paths) AddCaseSensitive(url string) {
+	p.add(url, true)
+}
+
+func (p Paths) add(url string, caseSensitive bool) {
+	host, path := splitURL(url, caseSensitive)
 	data := p[host]
 	if data == nil {
 		data = PathData{}
@@ -23,42 +36,54 @@ func (p Paths) Add(url string) {
 	p[host][path] = true
 }
 
-//MatchRequest match request.
-//Return result and any error if raised.
-func (p Paths) MatchRequest(r *http.Request) (bool, error) {
-	var data PathData
+func (p Paths) match(r *http.Request, caseSensitive bool) bool {
 	if len(p) == 0 {
-		return true, nil
+		return true
 	}
-	urlpath := strings.ToLower(r.URL.Path)
-	h := r.Host
-	if h != "" {
-		data = p[h]
-		if data != nil {
-			if data[urlpath] == true {
-				return true, nil
-			}
-		}
+	urlpath := r.URL.Path
+	if !caseSensitive {
+		urlpath = strings.ToLower(urlpath)
 	}
-	data = p[""]
-	if data == nil {
-		return false, nil
+	host, hostname := requestHosts(r)
+	if p[host][urlpath] || (hostname != host && p[hostname][urlpath]) {
+		return true
+	}
+	for h, data := range p {
+		if isHostWildcard(h) && matchHostPattern(h, host, hostname) && data[urlpath] {
+			return true
+		}
 	}
+	return p[""][urlpath]
+}
 
-	return data[r.URL.Path], nil
+//MatchRequest match request case-insensitively.
+//Return result and any error if raised.
+func (p Paths) MatchRequest(r *http.Request) (bool, error) {
+	return p.match(r, false), nil
+}
+
+//MatchRequestCaseSensitive match request case-sensitively.
+//Return result and any error if raised.
+func (p Paths) MatchRequestCaseSensitive(r *http.Request) (bool, error) {
+	return p.match(r, true), nil
 }
 
 //NewPaths create new paths pattern.
 func NewPaths() *Paths {
 	return &Paths{}
 }
-func splitURL(url string) (host string, path string) {
-	url = strings.ToLower(url)
+
+//splitURL split url to lowercased hostname and path.
+//Path will be converted to lower unless caseSensitive is true.
+func splitURL(url string, caseSensitive bool) (host string, path string) {
+	if !caseSensitive {
+		url = strings.ToLower(url)
+	}
 	if url[0] == '/' {
 		path = url
 	} else {
 		splited := strings.SplitN(url, "/", 2)
-		host = splited[0]
+		host = strings.ToLower(splited[0])
 		if len(splited) == 2 {
 			path = "/" + splited[1]
 		} else {
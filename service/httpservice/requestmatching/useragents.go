@@ -0,0 +1,93 @@
+package requestmatching
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+//UserAgentFamily user agent family detecting rule
+type UserAgentFamily struct {
+	//Name family name
+	Name string
+	//Keywords lowercase keywords contained in user agent
+	Keywords []string
+}
+
+//UserAgentFamilyEmpty family name of requests without user agent.
+const UserAgentFamilyEmpty = "empty"
+
+//UserAgentFamilyOther family name of user agents matching no family.
+const UserAgentFamilyOther = "other"
+
+//UserAgentFamilies ordered user agent families.
+//First family with any keyword contained in user agent will be used.
+var UserAgentFamilies = []*UserAgentFamily{
+	{Name: "bot", Keywords: []string{"bot", "spider", "crawl", "slurp", "facebookexternalhit", "mediapartners"}},
+	{Name: "curl", Keywords: []string{"curl/"}},
+	{Name: "wget", Keywords: []string{"wget/"}},
+	{Name: "python", Keywords: []string{"python-requests", "python-urllib", "aiohttp", "httpx"}},
+	{Name: "go", Keywords: []string{"go-http-client"}},
+	{Name: "java", Keywords: []string{"java/", "okhttp", "apache-httpclient"}},
+	{Name: "edge", Keywords: []string{"edg/", "edge/", "edga/", "edgios/"}},
+	{Name: "opera", Keywords: []string{"opr/", "opera"}},
+	{Name: "samsung", Keywords: []string{"samsungbrowser"}},
+	{Name: "firefox", Keywords: []string{"firefox/", "fxios/"}},
+	{Name: "chrome", Keywords: []string{"chrome/", "crios/", "chromium/"}},
+	{Name: "safari", Keywords: []string{"safari/"}},
+	{Name: "ie", Keywords: []string{"msie ", "trident/"}},
+}
+
+//GetUserAgentFamily return family name of given user agent.
+func GetUserAgentFamily(ua string) string {
+	if ua == "" {
+		return UserAgentFamilyEmpty
+	}
+	ua = strings.ToLower(ua)
+	for _, f := range UserAgentFamilies {
+		for _, keyword := range f.Keywords {
+			if strings.Contains(ua, keyword) {
+				return f.Name
+			}
+		}
+	}
+	return UserAgentFamilyOther
+}
+
+//UserAgents user agent families pattern
+type UserAgents map[string]bool
+
+//Add add user agent family to pattern.
+//Family name will be converted to lower.
+//Return error if family is unknown.
+func (u *UserAgents) Add(family string) error {
+	name := strings.ToLower(strings.TrimSpace(family))
+	if name != UserAgentFamilyEmpty && name != UserAgentFamilyOther {
+		found := false
+		for _, f := range UserAgentFamilies {
+			if f.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w : \"%s\"", ErrUnknownUserAgentFamily, family)
+		}
+	}
+	(*u)[name] = true
+	return nil
+}
+
+//MatchRequest match request.
+//Return result and any error if raised.
+func (u *UserAgents) MatchRequest(r *http.Request) (bool, error) {
+	if len(*u) == 0 {
+		return true, nil
+	}
+	return (*u)[GetUserAgentFamily(r.UserAgent())], nil
+}
+
+//NewUserAgents create new user agents pattern.
+func NewUserAgents() *UserAgents {
+	return &UserAgents{}
+}
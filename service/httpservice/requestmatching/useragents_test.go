@@ -0,0 +1,62 @@
+package requestmatching
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestUserAgentFamily(t *testing.T) {
+	for ua, expected := range map[string]string{
+		"": UserAgentFamilyEmpty,
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)": "bot",
+		"curl/7.68.0":             "curl",
+		"Wget/1.20.3 (linux-gnu)": "wget",
+		"python-requests/2.25.1":  "python",
+		"Go-http-client/1.1":      "go",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0 Safari/537.36":              "chrome",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0 Safari/537.36 Edg/91.0.864": "edge",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:89.0) Gecko/20100101 Firefox/89.0":                                          "firefox",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1 Safari/605.1.15":   "safari",
+		"Mozilla/5.0 (Windows NT 10.0; Trident/7.0; rv:11.0) like Gecko":                                                          "ie",
+		"custom-client": UserAgentFamilyOther,
+	} {
+		if GetUserAgentFamily(ua) != expected {
+			t.Fatal(ua, GetUserAgentFamily(ua))
+		}
+	}
+}
+
+func TestUserAgents(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://127.0.0.1/", nil)
+	r.Header.Set("User-Agent", "curl/7.68.0")
+	u := NewUserAgents()
+	if !MustMatch(r, u) {
+		t.Fatal(u)
+	}
+	err := u.Add("unknown")
+	if !errors.Is(err, ErrUnknownUserAgentFamily) {
+		t.Fatal(err)
+	}
+	u.Add("bot")
+	if MustMatch(r, u) {
+		t.Fatal(u)
+	}
+	u.Add(" Curl ")
+	if !MustMatch(r, u) {
+		t.Fatal(u)
+	}
+	p := MustCreatePattern(&PatternConfig{UserAgentList: []string{"empty", "other"}})
+	r.Header.Del("User-Agent")
+	if !MustMatch(r, p) {
+		t.Fatal(p)
+	}
+	_, err = (&PatternConfig{UserAgentList: []string{"unknown"}}).CreatePattern()
+	if !errors.Is(err, ErrUnknownUserAgentFamily) {
+		t.Fatal(err)
+	}
+	_, err = (&PatternConfig{UserAgentList: []string{"bot"}, FieldModes: map[string]string{FieldUserAgents: "all"}}).CreatePattern()
+	if !errors.Is(err, ErrMatchModeNotSupported) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,103 @@
+package requestmatching
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//ValueMatchMode value match mode type
+type ValueMatchMode int
+
+const (
+	//ValueMatchPresent match if key is present.
+	ValueMatchPresent = ValueMatchMode(iota)
+	//ValueMatchExact match if any value equals pattern value.
+	ValueMatchExact
+	//ValueMatchPrefix match if any value starts with pattern value.
+	ValueMatchPrefix
+	//ValueMatchRegExp match if any value matches pattern regexp.
+	ValueMatchRegExp
+)
+
+//ValueData key value pattern data struct
+type ValueData struct {
+	Key    string
+	Mode   ValueMatchMode
+	Value  string
+	RegExp *regexp.Regexp
+}
+
+//Match check if any of given values matches data.
+func (d *ValueData) Match(values []string) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for _, v := range values {
+		switch d.Mode {
+		case ValueMatchPresent:
+			return true
+		case ValueMatchExact:
+			if v == d.Value {
+				return true
+			}
+		case ValueMatchPrefix:
+			if strings.HasPrefix(v, d.Value) {
+				return true
+			}
+		case ValueMatchRegExp:
+			if d.RegExp.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//ParseValueData parse key value pattern.
+//Pattern should be in "key","key=value","key^=prefix" or "key~=regexp" form.
+//Key and value will be trimmed.
+//Given err will be wrapped if pattern is not validated.
+//Return value data and any error if raised.
+func ParseValueData(pattern string, err error) (*ValueData, error) {
+	d := &ValueData{}
+	i := strings.Index(pattern, "=")
+	if i < 0 {
+		d.Key = strings.TrimSpace(pattern)
+	} else {
+		key := pattern[:i]
+		d.Mode = ValueMatchExact
+		switch {
+		case strings.HasSuffix(key, "^"):
+			d.Mode = ValueMatchPrefix
+			key = key[:len(key)-1]
+		case strings.HasSuffix(key, "~"):
+			d.Mode = ValueMatchRegExp
+			key = key[:len(key)-1]
+		}
+		d.Key = strings.TrimSpace(key)
+		d.Value = strings.TrimSpace(pattern[i+1:])
+	}
+	if d.Key == "" {
+		return nil, fmt.Errorf("%w : \"%s\"", err, pattern)
+	}
+	if d.Mode == ValueMatchRegExp {
+		re, reerr := regexp.Compile(d.Value)
+		if reerr != nil {
+			return nil, fmt.Errorf("%w : \"%s\" : %s", err, pattern, reerr)
+		}
+		d.RegExp = re
+	}
+	return d, nil
+}
+
+//matchValues match value datas with values getter.
+//Return true if any data matched,or all data matched if all is true.
+func matchValues(list []*ValueData, get func(key string) []string, all bool) bool {
+	for _, d := range list {
+		if d.Match(get(d.Key)) != all {
+			return !all
+		}
+	}
+	return all
+}
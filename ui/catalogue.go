@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//ErrInvalidCatalogue error raised if catalogue data is invalid.
+var ErrInvalidCatalogue = errors.New("ui:invalid catalogue")
+
+//ErrUnsupportedCatalogue error raised if catalogue file extension is not supported.
+var ErrUnsupportedCatalogue = errors.New("ui:unsupported catalogue format")
+
+//CatalogueParsers catalogue parsers by file extension.
+var CatalogueParsers = map[string]func(data []byte) (*Messages, error){
+	".json": ParseMessagesJSON,
+	".toml": ParseMessagesTOML,
+	".po":   ParseMessagesPO,
+}
+
+func flattenMessages(m *Messages, prefix string, v interface{}) error {
+	switch data := v.(type) {
+	case string:
+		(*m)[prefix] = data
+	case map[string]interface{}:
+		for key, value := range data {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			err := flattenMessages(m, key, value)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w : value of \"%s\" is not string", ErrInvalidCatalogue, prefix)
+	}
+	return nil
+}
+
+//ParseMessagesJSON parse messages from json object.
+//Keys of nested objects are joined with ".".
+//Return messages and any error if raised.
+func ParseMessagesJSON(data []byte) (*Messages, error) {
+	v := map[string]interface{}{}
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidCatalogue, err.Error())
+	}
+	m := NewMessages()
+	err = flattenMessages(m, "", v)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//LoadMessagesFile load messages from json,toml or po file.
+//Return messages and any error if raised.
+func LoadMessagesFile(path string) (*Messages, error) {
+	parser := CatalogueParsers[strings.ToLower(filepath.Ext(path))]
+	if parser == nil {
+		return nil, fmt.Errorf("%w : \"%s\"", ErrUnsupportedCatalogue, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := parser(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+	return m, nil
+}
+
+//LoadFile load messages of given lang and module from file.
+//Return any error if raised.
+func (c *Translations) LoadFile(lang string, module string, path string) error {
+	m, err := LoadMessagesFile(path)
+	if err != nil {
+		return err
+	}
+	c.SetMessages(lang, module, m)
+	return nil
+}
+
+//LoadDir load all catalogues from directory in "<lang>/<module>.<ext>" layout,
+//and replace all messages at once.
+//Files with unsupported extension are ignored.
+//Messages are kept if any error raised.
+//Return any error if raised.
+func (c *Translations) LoadDir(dir string) error {
+	langs, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	t := NewTranslations()
+	for _, lang := range langs {
+		if !lang.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(dir, lang.Name()))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			ext := filepath.Ext(file.Name())
+			if file.IsDir() || CatalogueParsers[strings.ToLower(ext)] == nil {
+				continue
+			}
+			module := strings.TrimSuffix(file.Name(), ext)
+			err = t.LoadFile(lang.Name(), module, filepath.Join(dir, lang.Name(), file.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	c.ReplaceAll(t)
+	return nil
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMessagesJSON(t *testing.T) {
+	m, err := ParseMessagesJSON([]byte(`{"a":"1","b":{"c":"2","d":{"e":"3"}}}`))
+	if err != nil || len(*m) != 3 || m.Get("a") != "1" || m.Get("b.c") != "2" || m.Get("b.d.e") != "3" {
+		t.Fatal(m, err)
+	}
+	for _, data := range []string{`[]`, `{"a":1}`, `{"a":`} {
+		_, err = ParseMessagesJSON([]byte(data))
+		if !errors.Is(err, ErrInvalidCatalogue) {
+			t.Fatal(data, err)
+		}
+	}
+}
+
+func TestParseMessagesTOML(t *testing.T) {
+	m, err := ParseMessagesTOML([]byte(`# comment
+title = "标题" # trailing comment
+"quoted key" = 'C:\path'
+escaped = "tab\there \"quoted\" \u00e9 \U0001F600"
+dotted.key = "dotted"
+
+[table]
+key = """
+line1
+line2\
+    continued"""
+literal = '''
+raw \n ''text'''''
+[ "sub" . table ]
+key="sub"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]string{
+		"title":         "标题",
+		"quoted key":    `C:\path`,
+		"escaped":       "tab\there \"quoted\" é 😀",
+		"dotted.key":    "dotted",
+		"table.key":     "line1\nline2continued",
+		"table.literal": "raw \\n ''text''",
+		"sub.table.key": "sub",
+	} {
+		if v, ok := m.Load(key); !ok || v != expected {
+			t.Fatal(key, v)
+		}
+	}
+	if len(*m) != 7 {
+		t.Fatal(m)
+	}
+	for _, data := range []string{
+		"a = 1",
+		"a = \"1\" b",
+		"a \"1\"",
+		"a = \"1",
+		"a = \"1\nb\"",
+		"a = \"\\x\"",
+		"[[a]]\nb = \"1\"",
+		"[a\nb = \"1\"",
+		"a = \"1\"\na = \"2\"",
+		"= \"1\"",
+	} {
+		_, err = ParseMessagesTOML([]byte(data))
+		if !errors.Is(err, ErrInvalidCatalogue) {
+			t.Fatal(data, err)
+		}
+	}
+	_, err = ParseMessagesTOML([]byte("a = \"1\"\n\nb = 2"))
+	if err == nil || err.Error() != `ui:invalid catalogue : value of "b" is not string at line 3` {
+		t.Fatal(err)
+	}
+}
+
+func TestParseMessagesPO(t *testing.T) {
+	m, err := ParseMessagesPO([]byte(`# translator comment
+msgid ""
+msgstr ""
+"Language: zh_CN\n"
+"Plural-Forms: nplurals=1; plural=0;\n"
+
+#: app.go:1
+msgid "hello"
+msgstr "你好"
+
+msgid "multi"
+"line"
+msgstr ""
+"多"
+"行\n"
+
+#, fuzzy
+msgid "fuzzy"
+msgstr "模糊"
+
+msgid "untranslated"
+msgstr ""
+
+msgctxt "menu"
+msgid "open"
+msgstr "打开"
+
+msgid "file"
+msgid_plural "files"
+msgstr[0] "文件"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]string{
+		"hello":                              "你好",
+		"multiline":                          "多行\n",
+		"menu" + POContextSeparator + "open": "打开",
+		"file":                               "文件",
+	} {
+		if v, ok := m.Load(key); !ok || v != expected {
+			t.Fatal(key, v)
+		}
+	}
+	if len(*m) != 4 {
+		t.Fatal(m)
+	}
+	for _, data := range []string{
+		`"orphan"`,
+		`msgid hello`,
+		`msgid`,
+		`msgstr[x] "a"`,
+		`unknown "a"`,
+	} {
+		_, err = ParseMessagesPO([]byte(data))
+		if !errors.Is(err, ErrInvalidCatalogue) {
+			t.Fatal(data, err)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	c := NewTranslations()
+	c.SetMessages("fr", "app", NewMessages().Set("hello", "Bonjour"))
+	c.SetFallback("zh-hk", "zh-tw")
+	err := c.LoadDir("testdata/locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range [][3]string{
+		{"en", "errors.required", "{{label}} is required"},
+		{"zh", "errors.required", "{{label}}必须填写"},
+		{"zh", "bye", "再见"},
+		{"zh-TW", "hello", "您好"},
+		{"zh-HK", "hello", "您好"},
+		{"zh-TW", "bye", "再见"},
+		{"fr", "hello", "hello"},
+	} {
+		if result := c.Get(v[0], "app", v[1]); result != v[2] {
+			t.Fatal(v, result)
+		}
+	}
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "en"), 0755)
+	os.WriteFile(filepath.Join(dir, "en", "app.toml"), []byte("hello = 1"), 0644)
+	err = c.LoadDir(dir)
+	if !errors.Is(err, ErrInvalidCatalogue) {
+		t.Fatal(err)
+	}
+	if result := c.Get("zh", "app", "hello"); result != "你好" {
+		t.Fatal(result)
+	}
+	err = c.LoadFile("en", "app", filepath.Join(dir, "en", "app.yaml"))
+	if !errors.Is(err, ErrUnsupportedCatalogue) {
+		t.Fatal(err)
+	}
+	err = c.LoadFile("en", "app", filepath.Join(dir, "en", "notexist.json"))
+	if !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	err = c.LoadFile("en", "other", "testdata/locales/en/app.json")
+	if err != nil || c.Get("en", "other", "bye") != "Bye" {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,143 @@
+package format
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+func pad(i int, width int) string {
+	s := strconv.Itoa(i)
+	for len(s) < width {
+		s = "0" + s
+	}
+	return s
+}
+
+func (l *Locale) field(t time.Time, letter rune, count int) string {
+	switch letter {
+	case 'y':
+		if count == 2 {
+			return pad(t.Year()%100, 2)
+		}
+		return pad(t.Year(), count)
+	case 'M', 'L':
+		switch {
+		case count >= 4:
+			return l.Months[t.Month()-1]
+		case count == 3:
+			return l.ShortMonths[t.Month()-1]
+		}
+		return pad(int(t.Month()), count)
+	case 'd':
+		return pad(t.Day(), count)
+	case 'E':
+		if count >= 4 {
+			return l.Days[t.Weekday()]
+		}
+		return l.ShortDays[t.Weekday()]
+	case 'a':
+		if t.Hour() < 12 {
+			return l.AM
+		}
+		return l.PM
+	case 'h':
+		h := t.Hour() % 12
+		if h == 0 {
+			h = 12
+		}
+		return pad(h, count)
+	case 'H':
+		return pad(t.Hour(), count)
+	case 'm':
+		return pad(t.Minute(), count)
+	case 's':
+		return pad(t.Second(), count)
+	case 'z':
+		return t.Format("MST")
+	}
+	return strings.Repeat(string(letter), count)
+}
+
+//FormatPattern format time with CLDR date pattern,such as "EEEE, MMMM d, y".
+//Text in single quotes is output literally and "''" stands for single quote.
+func (l *Locale) FormatPattern(t time.Time, pattern string) string {
+	var b strings.Builder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			if i+1 < len(runes) && runes[i+1] == '\'' {
+				b.WriteRune('\'')
+				i += 2
+				continue
+			}
+			i++
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						b.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+		case (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			count := 1
+			for i+count < len(runes) && runes[i+count] == r {
+				count++
+			}
+			b.WriteString(l.field(t, r, count))
+			i += count
+		default:
+			b.WriteRune(r)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func styleFormat(formats map[string]string, style string) string {
+	if f, ok := formats[style]; ok {
+		return f
+	}
+	return formats[StyleMedium]
+}
+
+//Date format date of time in given language and style.
+//Style should be one of "short","medium","long" and "full",medium is used if style unknown.
+func Date(lang string, style string, t time.Time) string {
+	l := GetLocale(lang)
+	return l.FormatPattern(t, styleFormat(l.DateFormats, style))
+}
+
+//Time format time of day in given language and style.
+//Style should be one of "short","medium","long" and "full",medium is used if style unknown.
+func Time(lang string, style string, t time.Time) string {
+	l := GetLocale(lang)
+	return l.FormatPattern(t, styleFormat(l.TimeFormats, style))
+}
+
+//DateTime format date and time in given language and style.
+//Style should be one of "short","medium","long" and "full",medium is used if style unknown.
+func DateTime(lang string, style string, t time.Time) string {
+	l := GetLocale(lang)
+	date := l.FormatPattern(t, styleFormat(l.DateFormats, style))
+	clock := l.FormatPattern(t, styleFormat(l.TimeFormats, style))
+	return strings.Replace(strings.Replace(l.DateTimePattern, "{1}", date, 1), "{0}", clock, 1)
+}
+
+//In convert time to location with given IANA time zone name,such as "Asia/Shanghai".
+//Return converted time and any error if raised.
+func In(t time.Time, zone string) (time.Time, error) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return t, err
+	}
+	return t.In(loc), nil
+}
@@ -0,0 +1,64 @@
+package format
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDate(t *testing.T) {
+	date := time.Date(2020, 3, 4, 15, 6, 7, 0, time.UTC)
+	var tests = []struct {
+		format func(lang string, style string, t time.Time) string
+		lang   string
+		style  string
+		result string
+	}{
+		{Date, "en", StyleShort, "3/4/20"},
+		{Date, "en", StyleMedium, "Mar 4, 2020"},
+		{Date, "en", StyleLong, "March 4, 2020"},
+		{Date, "en", StyleFull, "Wednesday, March 4, 2020"},
+		{Date, "en", "unknown", "Mar 4, 2020"},
+		{Time, "en", StyleShort, "3:06 PM"},
+		{Time, "en", StyleMedium, "3:06:07 PM"},
+		{Time, "en", StyleLong, "3:06:07 PM UTC"},
+		{DateTime, "en", StyleMedium, "Mar 4, 2020, 3:06:07 PM"},
+		{Date, "zh-CN", StyleFull, "2020年3月4日星期三"},
+		{Time, "zh", StyleShort, "15:06"},
+		{DateTime, "zh", StyleMedium, "2020年3月4日 15:06:07"},
+		{Date, "ja", StyleMedium, "2020/03/04"},
+		{Time, "ja", StyleFull, "15時06分07秒 UTC"},
+		{Date, "fr", StyleFull, "mercredi 4 mars 2020"},
+		{Date, "de", StyleLong, "4. März 2020"},
+		{DateTime, "de", StyleShort, "04.03.20, 15:06"},
+		{Date, "es", StyleLong, "4 de marzo de 2020"},
+		{Date, "ru", StyleMedium, "4 мар. 2020 г."},
+	}
+	for _, v := range tests {
+		result := v.format(v.lang, v.style, date)
+		if result != v.result {
+			t.Fatal(v.lang, v.style, result)
+		}
+	}
+	result := English.FormatPattern(time.Date(2020, 3, 4, 0, 30, 0, 0, time.UTC), "h 'o''clock' a, ''yy")
+	if result != "12 o'clock AM, '20" {
+		t.Fatal(result)
+	}
+}
+
+func TestIn(t *testing.T) {
+	date := time.Date(2020, 3, 4, 15, 6, 7, 0, time.UTC)
+	local, err := In(date, "Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := Time("en", StyleShort, local); result != "11:06 PM" {
+		t.Fatal(result)
+	}
+	if result := DateTime("zh", StyleLong, local); result != "2020年3月4日 CST 23:06:07" {
+		t.Fatal(result)
+	}
+	_, err = In(date, "Invalid/Zone")
+	if err == nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,93 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/herb-go/herb/ui"
+	"github.com/herb-go/herb/ui/render"
+)
+
+//Funcs template funcs by name.
+//All funcs take language as first argument,such as {{formatCurrency .Lang .Price "USD"}}.
+var Funcs = map[string]interface{}{
+	"formatNumber":   Number,
+	"formatDecimal":  Decimal,
+	"formatPercent":  Percent,
+	"formatCurrency": Currency,
+	"formatDate":     Date,
+	"formatTime":     Time,
+	"formatDateTime": DateTime,
+	"formatRelative": Relative,
+	"inZone":         In,
+}
+
+//RegisterFuncs register Funcs to render engine,such as gotemplate or jet engine.
+//Return any error if raised.
+func RegisterFuncs(e render.Engine) error {
+	for name, fn := range Funcs {
+		err := e.RegisterFunc(name, fn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//FormatNumberArgument locale aware number argument formatter for ui message format.
+//Supported styles are "" ,"integer","percent" and "currency/<code>" such as "currency/USD".
+func FormatNumberArgument(lang string, style string, value interface{}) (string, error) {
+	_, err := toFloat64(value)
+	if err != nil {
+		return "", fmt.Errorf("%w : %v", ui.ErrInvalidArgument, value)
+	}
+	style = strings.TrimPrefix(strings.TrimSpace(style), "::")
+	switch {
+	case style == "integer":
+		return Decimal(lang, value, 0)
+	case style == "percent":
+		return Percent(lang, value)
+	case strings.HasPrefix(style, "currency/"):
+		return Currency(lang, value, strings.TrimPrefix(style, "currency/"))
+	}
+	return Number(lang, value)
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch data := v.(type) {
+	case time.Time:
+		return data, nil
+	case *time.Time:
+		if data != nil {
+			return *data, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w : %v", ui.ErrInvalidArgument, v)
+}
+
+//FormatDateArgument locale aware date argument formatter for ui message format.
+func FormatDateArgument(lang string, style string, value interface{}) (string, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return "", err
+	}
+	return Date(lang, style, t), nil
+}
+
+//FormatTimeArgument locale aware time argument formatter for ui message format.
+func FormatTimeArgument(lang string, style string, value interface{}) (string, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return "", err
+	}
+	return Time(lang, style, t), nil
+}
+
+//RegisterArgumentFormatters replace ui.ArgumentFormatters with locale aware formatters.
+//Should be called at startup.
+func RegisterArgumentFormatters() {
+	ui.ArgumentFormatters["number"] = FormatNumberArgument
+	ui.ArgumentFormatters["date"] = FormatDateArgument
+	ui.ArgumentFormatters["time"] = FormatTimeArgument
+}
@@ -0,0 +1,94 @@
+package format
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/herb-go/herb/ui"
+	"github.com/herb-go/herb/ui/render"
+	"github.com/herb-go/herb/ui/render/engines/gotemplate"
+)
+
+type errorEngine struct {
+	render.Engine
+}
+
+var errTest = errors.New("test error")
+
+func (e errorEngine) RegisterFunc(name string, fn interface{}) error {
+	return errTest
+}
+
+func TestRegisterFuncs(t *testing.T) {
+	engine := gotemplate.New()
+	err := RegisterFuncs(engine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetViewRoot("./testdata")
+	view, err := engine.Compile(render.NewViewConfig("format.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := view.Execute(map[string]interface{}{
+		"Lang":  "de",
+		"Price": 1234.5,
+		"Time":  time.Now().Add(-3 * time.Minute),
+		"Ratio": 0.256,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := Date("de", StyleLong, time.Now().Add(-3*time.Minute).In(time.FixedZone("JST", 9*3600)))
+	if string(output) != "1.234,50\u00a0€|"+date+"|26\u00a0%|vor 3 Minuten" {
+		t.Fatal(string(output))
+	}
+	_, err = view.Execute(map[string]interface{}{
+		"Lang":  "de",
+		"Price": "abc",
+		"Time":  time.Now(),
+	})
+	if !errors.Is(err, ErrInvalidNumber) {
+		t.Fatal(err)
+	}
+	err = RegisterFuncs(errorEngine{})
+	if err != errTest {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterArgumentFormatters(t *testing.T) {
+	formatters := map[string]ui.ArgumentFormatter{}
+	for k, v := range ui.ArgumentFormatters {
+		formatters[k] = v
+	}
+	defer func() {
+		ui.ArgumentFormatters = formatters
+	}()
+	RegisterArgumentFormatters()
+	date := time.Date(2020, 3, 4, 15, 6, 7, 0, time.UTC)
+	result, err := ui.FormatMessage(
+		"de",
+		"{price, number, ::currency/EUR} {n, number} {n, number, integer} {r, number, percent} {d, date, long} {d, time, short} {count, plural, one {# Datei} other {# Dateien}}",
+		map[string]interface{}{"price": 12.5, "n": 1234.5, "r": 0.5, "d": date, "count": 1234},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "12,50\u00a0€ 1.234,5 1.234 50\u00a0% 4. März 2020 15:06 1.234 Dateien" {
+		t.Fatal(result)
+	}
+	_, err = ui.FormatMessage("de", "{n, number}", map[string]interface{}{"n": "abc"})
+	if !errors.Is(err, ui.ErrInvalidArgument) {
+		t.Fatal(err)
+	}
+	_, err = ui.FormatMessage("de", "{d, date}", map[string]interface{}{"d": 1})
+	if !errors.Is(err, ui.ErrInvalidArgument) {
+		t.Fatal(err)
+	}
+	_, err = ui.FormatMessage("de", "{d, time}", map[string]interface{}{"d": 1})
+	if !errors.Is(err, ui.ErrInvalidArgument) {
+		t.Fatal(err)
+	}
+}
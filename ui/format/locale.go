@@ -0,0 +1,105 @@
+//Package format provide locale aware formatting helpers for numbers,currencies,dates and relative times.
+package format
+
+import (
+	"strings"
+
+	"github.com/herb-go/herb/ui"
+)
+
+//Date and time styles.
+const (
+	StyleShort  = "short"
+	StyleMedium = "medium"
+	StyleLong   = "long"
+	StyleFull   = "full"
+)
+
+//RelativeFormat relative time patterns of unit by plural category.
+//"{0}" in pattern will be replaced by formatted number.
+type RelativeFormat struct {
+	Past   map[ui.PluralCategory]string
+	Future map[ui.PluralCategory]string
+}
+
+//Locale locale formatting data
+type Locale struct {
+	//Decimal decimal separator
+	Decimal string
+	//Group grouping separator
+	Group string
+	//MinimumGroupingDigits minimum integer digits before first group separator
+	MinimumGroupingDigits int
+	//PercentPattern percent pattern,"#" for number
+	PercentPattern string
+	//CurrencyPattern currency pattern,"¤" for symbol and "#" for number
+	CurrencyPattern string
+	//CurrencySymbols locale specific currency symbols by code
+	CurrencySymbols map[string]string
+	//DateFormats CLDR date patterns by style
+	DateFormats map[string]string
+	//TimeFormats CLDR time patterns by style
+	TimeFormats map[string]string
+	//DateTimePattern pattern joins time "{0}" and date "{1}"
+	DateTimePattern string
+	//Months month names from January
+	Months []string
+	//ShortMonths abbreviated month names from January
+	ShortMonths []string
+	//Days day names from Sunday
+	Days []string
+	//ShortDays abbreviated day names from Sunday
+	ShortDays []string
+	//AM ante meridiem text
+	AM string
+	//PM post meridiem text
+	PM string
+	//Now text for current moment
+	Now string
+	//Relative relative time formats by unit
+	Relative map[string]*RelativeFormat
+}
+
+//Locales registered locales by normalized language code.
+var Locales = map[string]*Locale{
+	"en": English,
+	"zh": Chinese,
+	"ja": Japanese,
+	"fr": French,
+	"de": German,
+	"es": Spanish,
+	"ru": Russian,
+}
+
+//DefaultLocale locale used if no locale found.
+var DefaultLocale = English
+
+func findLocale(lang string) *Locale {
+	lang = ui.NormalizeLang(lang)
+	for lang != "" {
+		if l := Locales[lang]; l != nil {
+			return l
+		}
+		i := strings.LastIndex(lang, "-")
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	return nil
+}
+
+//GetLocale return locale of given language.
+//Parent languages and ui.Lang are tried in order,DefaultLocale will be returned if not found.
+func GetLocale(lang string) *Locale {
+	if lang == "" {
+		lang = ui.Lang
+	}
+	if l := findLocale(lang); l != nil {
+		return l
+	}
+	if l := findLocale(ui.Lang); l != nil {
+		return l
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,305 @@
+package format
+
+import "github.com/herb-go/herb/ui"
+
+func relativeOther(past string, future string) *RelativeFormat {
+	return &RelativeFormat{
+		Past:   map[ui.PluralCategory]string{ui.PluralOther: past},
+		Future: map[ui.PluralCategory]string{ui.PluralOther: future},
+	}
+}
+
+func relativeOneOther(pastOne string, pastOther string, futureOne string, futureOther string) *RelativeFormat {
+	return &RelativeFormat{
+		Past:   map[ui.PluralCategory]string{ui.PluralOne: pastOne, ui.PluralOther: pastOther},
+		Future: map[ui.PluralCategory]string{ui.PluralOne: futureOne, ui.PluralOther: futureOther},
+	}
+}
+
+//relativeSlavic create relative format with one,few and many forms,few form is used for other.
+func relativeSlavic(one string, few string, many string, past string, future string) *RelativeFormat {
+	return &RelativeFormat{
+		Past: map[ui.PluralCategory]string{
+			ui.PluralOne:   "{0} " + one + past,
+			ui.PluralFew:   "{0} " + few + past,
+			ui.PluralMany:  "{0} " + many + past,
+			ui.PluralOther: "{0} " + few + past,
+		},
+		Future: map[ui.PluralCategory]string{
+			ui.PluralOne:   future + "{0} " + one,
+			ui.PluralFew:   future + "{0} " + few,
+			ui.PluralMany:  future + "{0} " + many,
+			ui.PluralOther: future + "{0} " + few,
+		},
+	}
+}
+
+//English english locale
+var English = &Locale{
+	Decimal:               ".",
+	Group:                 ",",
+	MinimumGroupingDigits: 1,
+	PercentPattern:        "#%",
+	CurrencyPattern:       "¤#",
+	CurrencySymbols:       map[string]string{"CNY": "CN¥"},
+	DateFormats: map[string]string{
+		StyleShort:  "M/d/yy",
+		StyleMedium: "MMM d, y",
+		StyleLong:   "MMMM d, y",
+		StyleFull:   "EEEE, MMMM d, y",
+	},
+	TimeFormats: map[string]string{
+		StyleShort:  "h:mm a",
+		StyleMedium: "h:mm:ss a",
+		StyleLong:   "h:mm:ss a z",
+		StyleFull:   "h:mm:ss a z",
+	},
+	DateTimePattern: "{1}, {0}",
+	Months:          []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	ShortMonths:     []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	Days:            []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	ShortDays:       []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	AM:              "AM",
+	PM:              "PM",
+	Now:             "now",
+	Relative: map[string]*RelativeFormat{
+		UnitSecond: relativeOneOther("{0} second ago", "{0} seconds ago", "in {0} second", "in {0} seconds"),
+		UnitMinute: relativeOneOther("{0} minute ago", "{0} minutes ago", "in {0} minute", "in {0} minutes"),
+		UnitHour:   relativeOneOther("{0} hour ago", "{0} hours ago", "in {0} hour", "in {0} hours"),
+		UnitDay:    relativeOneOther("{0} day ago", "{0} days ago", "in {0} day", "in {0} days"),
+		UnitWeek:   relativeOneOther("{0} week ago", "{0} weeks ago", "in {0} week", "in {0} weeks"),
+		UnitMonth:  relativeOneOther("{0} month ago", "{0} months ago", "in {0} month", "in {0} months"),
+		UnitYear:   relativeOneOther("{0} year ago", "{0} years ago", "in {0} year", "in {0} years"),
+	},
+}
+
+//Chinese chinese locale
+var Chinese = &Locale{
+	Decimal:               ".",
+	Group:                 ",",
+	MinimumGroupingDigits: 1,
+	PercentPattern:        "#%",
+	CurrencyPattern:       "¤#",
+	CurrencySymbols:       map[string]string{"USD": "US$"},
+	DateFormats: map[string]string{
+		StyleShort:  "y/M/d",
+		StyleMedium: "y年M月d日",
+		StyleLong:   "y年M月d日",
+		StyleFull:   "y年M月d日EEEE",
+	},
+	TimeFormats: map[string]string{
+		StyleShort:  "HH:mm",
+		StyleMedium: "HH:mm:ss",
+		StyleLong:   "z HH:mm:ss",
+		StyleFull:   "z HH:mm:ss",
+	},
+	DateTimePattern: "{1} {0}",
+	Months:          []string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"},
+	ShortMonths:     []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+	Days:            []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+	ShortDays:       []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+	AM:              "上午",
+	PM:              "下午",
+	Now:             "现在",
+	Relative: map[string]*RelativeFormat{
+		UnitSecond: relativeOther("{0}秒钟前", "{0}秒钟后"),
+		UnitMinute: relativeOther("{0}分钟前", "{0}分钟后"),
+		UnitHour:   relativeOther("{0}小时前", "{0}小时后"),
+		UnitDay:    relativeOther("{0}天前", "{0}天后"),
+		UnitWeek:   relativeOther("{0}周前", "{0}周后"),
+		UnitMonth:  relativeOther("{0}个月前", "{0}个月后"),
+		UnitYear:   relativeOther("{0}年前", "{0}年后"),
+	},
+}
+
+//Japanese japanese locale
+var Japanese = &Locale{
+	Decimal:               ".",
+	Group:                 ",",
+	MinimumGroupingDigits: 1,
+	PercentPattern:        "#%",
+	CurrencyPattern:       "¤#",
+	CurrencySymbols:       map[string]string{"JPY": "￥", "CNY": "元"},
+	DateFormats: map[string]string{
+		StyleShort:  "y/MM/dd",
+		StyleMedium: "y/MM/dd",
+		StyleLong:   "y年M月d日",
+		StyleFull:   "y年M月d日EEEE",
+	},
+	TimeFormats: map[string]string{
+		StyleShort:  "H:mm",
+		StyleMedium: "H:mm:ss",
+		StyleLong:   "H:mm:ss z",
+		StyleFull:   "H時mm分ss秒 z",
+	},
+	DateTimePattern: "{1} {0}",
+	Months:          []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+	ShortMonths:     []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+	Days:            []string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+	ShortDays:       []string{"日", "月", "火", "水", "木", "金", "土"},
+	AM:              "午前",
+	PM:              "午後",
+	Now:             "今",
+	Relative: map[string]*RelativeFormat{
+		UnitSecond: relativeOther("{0} 秒前", "{0} 秒後"),
+		UnitMinute: relativeOther("{0} 分前", "{0} 分後"),
+		UnitHour:   relativeOther("{0} 時間前", "{0} 時間後"),
+		UnitDay:    relativeOther("{0} 日前", "{0} 日後"),
+		UnitWeek:   relativeOther("{0} 週間前", "{0} 週間後"),
+		UnitMonth:  relativeOther("{0} か月前", "{0} か月後"),
+		UnitYear:   relativeOther("{0} 年前", "{0} 年後"),
+	},
+}
+
+//French french locale
+var French = &Locale{
+	Decimal:               ",",
+	Group:                 "\u202f",
+	MinimumGroupingDigits: 1,
+	PercentPattern:        "#\u202f%",
+	CurrencyPattern:       "#\u00a0¤",
+	DateFormats: map[string]string{
+		StyleShort:  "dd/MM/y",
+		StyleMedium: "d MMM y",
+		StyleLong:   "d MMMM y",
+		StyleFull:   "EEEE d MMMM y",
+	},
+	TimeFormats: map[string]string{
+		StyleShort:  "HH:mm",
+		StyleMedium: "HH:mm:ss",
+		StyleLong:   "HH:mm:ss z",
+		StyleFull:   "HH:mm:ss z",
+	},
+	DateTimePattern: "{1} {0}",
+	Months:          []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	ShortMonths:     []string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+	Days:            []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+	ShortDays:       []string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	AM:              "AM",
+	PM:              "PM",
+	Now:             "maintenant",
+	Relative: map[string]*RelativeFormat{
+		UnitSecond: relativeOneOther("il y a {0} seconde", "il y a {0} secondes", "dans {0} seconde", "dans {0} secondes"),
+		UnitMinute: relativeOneOther("il y a {0} minute", "il y a {0} minutes", "dans {0} minute", "dans {0} minutes"),
+		UnitHour:   relativeOneOther("il y a {0} heure", "il y a {0} heures", "dans {0} heure", "dans {0} heures"),
+		UnitDay:    relativeOneOther("il y a {0} jour", "il y a {0} jours", "dans {0} jour", "dans {0} jours"),
+		UnitWeek:   relativeOneOther("il y a {0} semaine", "il y a {0} semaines", "dans {0} semaine", "dans {0} semaines"),
+		UnitMonth:  relativeOneOther("il y a {0} mois", "il y a {0} mois", "dans {0} mois", "dans {0} mois"),
+		UnitYear:   relativeOneOther("il y a {0} an", "il y a {0} ans", "dans {0} an", "dans {0} ans"),
+	},
+}
+
+//German german locale
+var German = &Locale{
+	Decimal:               ",",
+	Group:                 ".",
+	MinimumGroupingDigits: 1,
+	PercentPattern:        "#\u00a0%",
+	CurrencyPattern:       "#\u00a0¤",
+	DateFormats: map[string]string{
+		StyleShort:  "dd.MM.yy",
+		StyleMedium: "dd.MM.y",
+		StyleLong:   "d. MMMM y",
+		StyleFull:   "EEEE, d. MMMM y",
+	},
+	TimeFormats: map[string]string{
+		StyleShort:  "HH:mm",
+		StyleMedium: "HH:mm:ss",
+		StyleLong:   "HH:mm:ss z",
+		StyleFull:   "HH:mm:ss z",
+	},
+	DateTimePattern: "{1}, {0}",
+	Months:          []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	ShortMonths:     []string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+	Days:            []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+	ShortDays:       []string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+	AM:              "AM",
+	PM:              "PM",
+	Now:             "jetzt",
+	Relative: map[string]*RelativeFormat{
+		UnitSecond: relativeOneOther("vor {0} Sekunde", "vor {0} Sekunden", "in {0} Sekunde", "in {0} Sekunden"),
+		UnitMinute: relativeOneOther("vor {0} Minute", "vor {0} Minuten", "in {0} Minute", "in {0} Minuten"),
+		UnitHour:   relativeOneOther("vor {0} Stunde", "vor {0} Stunden", "in {0} Stunde", "in {0} Stunden"),
+		UnitDay:    relativeOneOther("vor {0} Tag", "vor {0} Tagen", "in {0} Tag", "in {0} Tagen"),
+		UnitWeek:   relativeOneOther("vor {0} Woche", "vor {0} Wochen", "in {0} Woche", "in {0} Wochen"),
+		UnitMonth:  relativeOneOther("vor {0} Monat", "vor {0} Monaten", "in {0} Monat", "in {0} Monaten"),
+		UnitYear:   relativeOneOther("vor {0} Jahr", "vor {0} Jahren", "in {0} Jahr", "in {0} Jahren"),
+	},
+}
+
+//Spanish spanish locale
+var Spanish = &Locale{
+	Decimal:               ",",
+	Group:                 ".",
+	MinimumGroupingDigits: 2,
+	PercentPattern:        "#\u00a0%",
+	CurrencyPattern:       "#\u00a0¤",
+	DateFormats: map[string]string{
+		StyleShort:  "d/M/yy",
+		StyleMedium: "d MMM y",
+		StyleLong:   "d 'de' MMMM 'de' y",
+		StyleFull:   "EEEE, d 'de' MMMM 'de' y",
+	},
+	TimeFormats: map[string]string{
+		StyleShort:  "H:mm",
+		StyleMedium: "H:mm:ss",
+		StyleLong:   "H:mm:ss z",
+		StyleFull:   "H:mm:ss z",
+	},
+	DateTimePattern: "{1}, {0}",
+	Months:          []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	ShortMonths:     []string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+	Days:            []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+	ShortDays:       []string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	AM:              "a.\u00a0m.",
+	PM:              "p.\u00a0m.",
+	Now:             "ahora",
+	Relative: map[string]*RelativeFormat{
+		UnitSecond: relativeOneOther("hace {0} segundo", "hace {0} segundos", "dentro de {0} segundo", "dentro de {0} segundos"),
+		UnitMinute: relativeOneOther("hace {0} minuto", "hace {0} minutos", "dentro de {0} minuto", "dentro de {0} minutos"),
+		UnitHour:   relativeOneOther("hace {0} hora", "hace {0} horas", "dentro de {0} hora", "dentro de {0} horas"),
+		UnitDay:    relativeOneOther("hace {0} día", "hace {0} días", "dentro de {0} día", "dentro de {0} días"),
+		UnitWeek:   relativeOneOther("hace {0} semana", "hace {0} semanas", "dentro de {0} semana", "dentro de {0} semanas"),
+		UnitMonth:  relativeOneOther("hace {0} mes", "hace {0} meses", "dentro de {0} mes", "dentro de {0} meses"),
+		UnitYear:   relativeOneOther("hace {0} año", "hace {0} años", "dentro de {0} año", "dentro de {0} años"),
+	},
+}
+
+//Russian russian locale
+var Russian = &Locale{
+	Decimal:               ",",
+	Group:                 "\u00a0",
+	MinimumGroupingDigits: 1,
+	PercentPattern:        "#\u00a0%",
+	CurrencyPattern:       "#\u00a0¤",
+	CurrencySymbols:       map[string]string{"RUB": "₽"},
+	DateFormats: map[string]string{
+		StyleShort:  "dd.MM.y",
+		StyleMedium: "d MMM y 'г'.",
+		StyleLong:   "d MMMM y 'г'.",
+		StyleFull:   "EEEE, d MMMM y 'г'.",
+	},
+	TimeFormats: map[string]string{
+		StyleShort:  "HH:mm",
+		StyleMedium: "HH:mm:ss",
+		StyleLong:   "HH:mm:ss z",
+		StyleFull:   "HH:mm:ss z",
+	},
+	DateTimePattern: "{1}, {0}",
+	Months:          []string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+	ShortMonths:     []string{"янв.", "февр.", "мар.", "апр.", "мая", "июн.", "июл.", "авг.", "сент.", "окт.", "нояб.", "дек."},
+	Days:            []string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+	ShortDays:       []string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"},
+	AM:              "AM",
+	PM:              "PM",
+	Now:             "сейчас",
+	Relative: map[string]*RelativeFormat{
+		UnitSecond: relativeSlavic("секунду", "секунды", "секунд", " назад", "через "),
+		UnitMinute: relativeSlavic("минуту", "минуты", "минут", " назад", "через "),
+		UnitHour:   relativeSlavic("час", "часа", "часов", " назад", "через "),
+		UnitDay:    relativeSlavic("день", "дня", "дней", " назад", "через "),
+		UnitWeek:   relativeSlavic("неделю", "недели", "недель", " назад", "через "),
+		UnitMonth:  relativeSlavic("месяц", "месяца", "месяцев", " назад", "через "),
+		UnitYear:   relativeSlavic("год", "года", "лет", " назад", "через "),
+	},
+}
@@ -0,0 +1,218 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//ErrInvalidNumber error raised if value is not a number.
+var ErrInvalidNumber = errors.New("format:invalid number")
+
+//DefaultMaxFractionDigits max fraction digits used by Number.
+var DefaultMaxFractionDigits = 3
+
+//CurrencySymbols currency symbols by ISO 4217 code.
+//Locale currency symbols take precedence.
+var CurrencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "¥",
+	"KRW": "₩",
+	"INR": "₹",
+	"RUB": "RUB",
+}
+
+//CurrencyDigits fraction digits of currencies by ISO 4217 code.
+//Currencies not listed use 2 fraction digits.
+var CurrencyDigits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"CLP": 0,
+	"ISK": 0,
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	var f float64
+	switch data := v.(type) {
+	case int:
+		f = float64(data)
+	case int8:
+		f = float64(data)
+	case int16:
+		f = float64(data)
+	case int32:
+		f = float64(data)
+	case int64:
+		f = float64(data)
+	case uint:
+		f = float64(data)
+	case uint8:
+		f = float64(data)
+	case uint16:
+		f = float64(data)
+	case uint32:
+		f = float64(data)
+	case uint64:
+		f = float64(data)
+	case float32:
+		f = float64(data)
+	case float64:
+		f = data
+	case string:
+		var err error
+		f, err = strconv.ParseFloat(strings.TrimSpace(data), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w : %v", ErrInvalidNumber, v)
+		}
+	default:
+		return 0, fmt.Errorf("%w : %v", ErrInvalidNumber, v)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%w : %v", ErrInvalidNumber, v)
+	}
+	return f, nil
+}
+
+func (l *Locale) group(digits string) string {
+	if len(digits) < 3+l.MinimumGroupingDigits {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(l.Group)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+//FormatFloat format absolute value of f with grouping and given fraction digits range.
+//Return formatted number and whether f is negative after rounding.
+func (l *Locale) FormatFloat(f float64, minFraction int, maxFraction int) (string, bool) {
+	s := strconv.FormatFloat(math.Abs(f), 'f', maxFraction, 64)
+	intpart, fraction := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		intpart, fraction = s[:i], s[i+1:]
+	}
+	negative := f < 0 && strings.Trim(s, "0.") != ""
+	for len(fraction) > minFraction && strings.HasSuffix(fraction, "0") {
+		fraction = fraction[:len(fraction)-1]
+	}
+	result := l.group(intpart)
+	if fraction != "" {
+		result = result + l.Decimal + fraction
+	}
+	return result, negative
+}
+
+func (l *Locale) formatNumber(f float64, minFraction int, maxFraction int) string {
+	s, negative := l.FormatFloat(f, minFraction, maxFraction)
+	if negative {
+		return "-" + s
+	}
+	return s
+}
+
+//Number format number in given language with at most DefaultMaxFractionDigits fraction digits.
+//Return formatted number and any error if raised.
+func Number(lang string, v interface{}) (string, error) {
+	f, err := toFloat64(v)
+	if err != nil {
+		return "", err
+	}
+	return GetLocale(lang).formatNumber(f, 0, DefaultMaxFractionDigits), nil
+}
+
+//Decimal format number in given language with fixed fraction digits.
+//Return formatted number and any error if raised.
+func Decimal(lang string, v interface{}, fraction int) (string, error) {
+	f, err := toFloat64(v)
+	if err != nil {
+		return "", err
+	}
+	if fraction < 0 {
+		fraction = 0
+	}
+	return GetLocale(lang).formatNumber(f, fraction, fraction), nil
+}
+
+//Percent format ratio as percentage in given language,0.25 will be formatted as "25%" in english.
+//Return formatted percentage and any error if raised.
+func Percent(lang string, v interface{}) (string, error) {
+	f, err := toFloat64(v)
+	if err != nil {
+		return "", err
+	}
+	l := GetLocale(lang)
+	s, negative := l.FormatFloat(f*100, 0, 0)
+	s = strings.Replace(l.PercentPattern, "#", s, 1)
+	if negative {
+		return "-" + s, nil
+	}
+	return s, nil
+}
+
+//CurrencySymbol return symbol of currency code in given language.
+//Code itself will be returned if no symbol found.
+func CurrencySymbol(lang string, code string) string {
+	code = strings.ToUpper(code)
+	if s, ok := GetLocale(lang).CurrencySymbols[code]; ok {
+		return s
+	}
+	if s, ok := CurrencySymbols[code]; ok {
+		return s
+	}
+	return code
+}
+
+func isLetter(s string, last bool) bool {
+	r := []rune(s)
+	if len(r) == 0 {
+		return false
+	}
+	if last {
+		return unicode.IsLetter(r[len(r)-1])
+	}
+	return unicode.IsLetter(r[0])
+}
+
+//Currency format amount of currency with ISO 4217 code in given language.
+//Return formatted amount and any error if raised.
+func Currency(lang string, v interface{}, code string) (string, error) {
+	f, err := toFloat64(v)
+	if err != nil {
+		return "", err
+	}
+	code = strings.ToUpper(code)
+	digits, ok := CurrencyDigits[code]
+	if !ok {
+		digits = 2
+	}
+	l := GetLocale(lang)
+	symbol := CurrencySymbol(lang, code)
+	s, negative := l.FormatFloat(f, digits, digits)
+	pattern := l.CurrencyPattern
+	if strings.Contains(pattern, "¤#") && isLetter(symbol, true) {
+		pattern = strings.Replace(pattern, "¤#", "¤\u00a0#", 1)
+	}
+	if strings.Contains(pattern, "#¤") && isLetter(symbol, false) {
+		pattern = strings.Replace(pattern, "#¤", "#\u00a0¤", 1)
+	}
+	s = strings.Replace(strings.Replace(pattern, "#", s, 1), "¤", symbol, 1)
+	if negative {
+		return "-" + s, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,123 @@
+package format
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNumber(t *testing.T) {
+	var tests = []struct {
+		lang   string
+		value  interface{}
+		result string
+	}{
+		{"en", 1234567.891, "1,234,567.891"},
+		{"en", 1.23456, "1.235"},
+		{"en", -0.0001, "0"},
+		{"en", int64(-1234), "-1,234"},
+		{"en", "1234.50", "1,234.5"},
+		{"EN_us", 1234, "1,234"},
+		{"zh-CN", 1234, "1,234"},
+		{"unknown", 1234, "1,234"},
+		{"de", 1234.5, "1.234,5"},
+		{"fr", 1234567.5, "1\u202f234\u202f567,5"},
+		{"es", 1234, "1234"},
+		{"es", 12345, "12.345"},
+		{"ru", 1234.5, "1\u00a0234,5"},
+	}
+	for _, v := range tests {
+		result, err := Number(v.lang, v.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != v.result {
+			t.Fatal(v.lang, v.value, result)
+		}
+	}
+	_, err := Number("en", "abc")
+	if !errors.Is(err, ErrInvalidNumber) {
+		t.Fatal(err)
+	}
+	_, err = Number("en", math.NaN())
+	if !errors.Is(err, ErrInvalidNumber) {
+		t.Fatal(err)
+	}
+	_, err = Number("en", true)
+	if !errors.Is(err, ErrInvalidNumber) {
+		t.Fatal(err)
+	}
+}
+
+func TestDecimalAndPercent(t *testing.T) {
+	result, err := Decimal("en", 1234.5, 2)
+	if err != nil || result != "1,234.50" {
+		t.Fatal(result, err)
+	}
+	result, err = Decimal("de", 2, 2)
+	if err != nil || result != "2,00" {
+		t.Fatal(result, err)
+	}
+	result, err = Decimal("en", 2.6, -1)
+	if err != nil || result != "3" {
+		t.Fatal(result, err)
+	}
+	result, err = Percent("en", 0.256)
+	if err != nil || result != "26%" {
+		t.Fatal(result, err)
+	}
+	result, err = Percent("fr", 0.5)
+	if err != nil || result != "50\u202f%" {
+		t.Fatal(result, err)
+	}
+	result, err = Percent("de", -0.5)
+	if err != nil || result != "-50\u00a0%" {
+		t.Fatal(result, err)
+	}
+	_, err = Decimal("en", "abc", 2)
+	if !errors.Is(err, ErrInvalidNumber) {
+		t.Fatal(err)
+	}
+	_, err = Percent("en", "abc")
+	if !errors.Is(err, ErrInvalidNumber) {
+		t.Fatal(err)
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	var tests = []struct {
+		lang   string
+		value  interface{}
+		code   string
+		result string
+	}{
+		{"en", 1234.5, "usd", "$1,234.50"},
+		{"en", -3, "EUR", "-€3.00"},
+		{"en", 1000, "JPY", "¥1,000"},
+		{"en", 5, "CHF", "CHF\u00a05.00"},
+		{"en", 10, "CNY", "CN¥10.00"},
+		{"zh", 10, "CNY", "¥10.00"},
+		{"zh", 10, "USD", "US$10.00"},
+		{"ja", 1000, "JPY", "￥1,000"},
+		{"de", 1234.5, "EUR", "1.234,50\u00a0€"},
+		{"de", 5, "CHF", "5,00\u00a0CHF"},
+		{"fr", -1234.5, "EUR", "-1\u202f234,50\u00a0€"},
+		{"ru", 10, "RUB", "10,00\u00a0₽"},
+	}
+	for _, v := range tests {
+		result, err := Currency(v.lang, v.value, v.code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != v.result {
+			t.Fatal(v.lang, v.value, v.code, result)
+		}
+	}
+	_, err := Currency("en", "abc", "USD")
+	if !errors.Is(err, ErrInvalidNumber) {
+		t.Fatal(err)
+	}
+	if CurrencySymbol("en", "xyz") != "XYZ" {
+		t.Fatal(CurrencySymbol("en", "xyz"))
+	}
+}
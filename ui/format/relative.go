@@ -0,0 +1,91 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/herb-go/herb/ui"
+)
+
+//ErrUnknownUnit error raised if relative time unit is unknown.
+var ErrUnknownUnit = errors.New("format:unknown relative time unit")
+
+//Relative time units.
+const (
+	UnitSecond = "second"
+	UnitMinute = "minute"
+	UnitHour   = "hour"
+	UnitDay    = "day"
+	UnitWeek   = "week"
+	UnitMonth  = "month"
+	UnitYear   = "year"
+)
+
+//RelativeUnit format value of unit as relative time in given language.
+//Negative value is in the past,"-3,minute" will be formatted as "3 minutes ago" in english.
+//Return formatted relative time and any error if raised.
+func RelativeUnit(lang string, value int64, unit string) (string, error) {
+	l := GetLocale(lang)
+	f := l.Relative[unit]
+	if f == nil {
+		return "", fmt.Errorf("%w : %s", ErrUnknownUnit, unit)
+	}
+	patterns := f.Future
+	if value < 0 {
+		patterns = f.Past
+		value = -value
+	}
+	category, err := ui.CardinalCategory(lang, value)
+	if err != nil {
+		return "", err
+	}
+	pattern, ok := patterns[category]
+	if !ok {
+		pattern = patterns[ui.PluralOther]
+	}
+	return strings.Replace(pattern, "{0}", l.formatNumber(float64(value), 0, 0), 1), nil
+}
+
+//RelativeTo format time relative to given moment in given language with the largest fitting unit.
+//Months and years are approximated as 30 and 365 days.
+//Empty string will be returned if locale has no format of unit.
+func RelativeTo(lang string, t time.Time, now time.Time) string {
+	d := t.Sub(now)
+	abs := time.Duration(math.Abs(float64(d)))
+	var value int64
+	var unit string
+	switch {
+	case abs < time.Second:
+		return GetLocale(lang).Now
+	case abs < time.Minute:
+		value, unit = int64(abs/time.Second), UnitSecond
+	case abs < time.Hour:
+		value, unit = int64(abs/time.Minute), UnitMinute
+	case abs < 24*time.Hour:
+		value, unit = int64(abs/time.Hour), UnitHour
+	case abs < 7*24*time.Hour:
+		value, unit = int64(abs/(24*time.Hour)), UnitDay
+	case abs < 30*24*time.Hour:
+		value, unit = int64(abs/(7*24*time.Hour)), UnitWeek
+	case abs < 365*24*time.Hour:
+		value, unit = int64(abs/(30*24*time.Hour)), UnitMonth
+	default:
+		value, unit = int64(abs/(365*24*time.Hour)), UnitYear
+	}
+	if d < 0 {
+		value = -value
+	}
+	result, err := RelativeUnit(lang, value, unit)
+	if err != nil {
+		return ""
+	}
+	return result
+}
+
+//Relative format time relative to current time in given language,such as "3 minutes ago".
+func Relative(lang string, t time.Time) string {
+	return RelativeTo(lang, t, time.Now())
+}
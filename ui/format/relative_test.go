@@ -0,0 +1,58 @@
+package format
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRelativeTo(t *testing.T) {
+	now := time.Date(2020, 3, 4, 15, 6, 7, 0, time.UTC)
+	var tests = []struct {
+		lang   string
+		d      time.Duration
+		result string
+	}{
+		{"en", 0, "now"},
+		{"en", -500 * time.Millisecond, "now"},
+		{"en", -30 * time.Second, "30 seconds ago"},
+		{"en", -time.Minute, "1 minute ago"},
+		{"en", -3 * time.Minute, "3 minutes ago"},
+		{"en", 2 * time.Hour, "in 2 hours"},
+		{"en", -10 * 24 * time.Hour, "1 week ago"},
+		{"en", -60 * 24 * time.Hour, "2 months ago"},
+		{"en", -400 * 24 * time.Hour, "1 year ago"},
+		{"zh", -3 * time.Minute, "3分钟前"},
+		{"ja", 3 * 24 * time.Hour, "3 日後"},
+		{"ru", -21 * time.Minute, "21 минуту назад"},
+		{"ru", -3 * time.Hour, "3 часа назад"},
+		{"ru", 5 * 24 * time.Hour, "через 5 дней"},
+		{"fr", -time.Hour, "il y a 1 heure"},
+		{"es", 2 * 7 * 24 * time.Hour, "dentro de 2 semanas"},
+	}
+	for _, v := range tests {
+		result := RelativeTo(v.lang, now.Add(v.d), now)
+		if result != v.result {
+			t.Fatal(v.lang, v.d, result)
+		}
+	}
+	result := Relative("en", time.Now().Add(-2*time.Hour-time.Minute))
+	if result != "2 hours ago" {
+		t.Fatal(result)
+	}
+}
+
+func TestRelativeUnit(t *testing.T) {
+	result, err := RelativeUnit("de", 1234, UnitDay)
+	if err != nil || result != "in 1.234 Tagen" {
+		t.Fatal(result, err)
+	}
+	result, err = RelativeUnit("de", -1, UnitYear)
+	if err != nil || result != "vor 1 Jahr" {
+		t.Fatal(result, err)
+	}
+	_, err = RelativeUnit("en", 1, "fortnight")
+	if !errors.Is(err, ErrUnknownUnit) {
+		t.Fatal(err)
+	}
+}
@@ -71,3 +71,43 @@ func NewTemplateMessage(module string, message string, tokens map[string]string)
 		tokens:  tokens,
 	}
 }
+
+//ICUMessage message in ICU message format with arguments
+type ICUMessage struct {
+	message *Message
+	args    map[string]interface{}
+}
+
+//Translate translate message with default translations.
+//if lang if empty,Lang will be used.
+func (m *ICUMessage) Translate(lang string) string {
+	if lang == "" {
+		lang = Lang
+	}
+	return m.TranslateWith(DefaultTranslations, lang)
+}
+
+//TranslateWith translate message with given translations and language.
+//Plural rules of language which pattern is resolved in are used.
+//Translated pattern will be returned unformatted if it is not valid.
+func (m *ICUMessage) TranslateWith(t *Translations, lang string) string {
+	str, resolved, _ := t.LoadLang(lang, m.message.Module, m.message.Text)
+	result, err := FormatMessage(resolved, str, m.args)
+	if err != nil {
+		return str
+	}
+	return result
+}
+
+//Translated create translated message by given language
+func (m *ICUMessage) Translated(lang string) *Translated {
+	return NewTranslated(m, lang)
+}
+
+//NewICUMessage create new ICU message format message with given module,text and arguments.
+func NewICUMessage(module string, message string, args map[string]interface{}) *ICUMessage {
+	return &ICUMessage{
+		message: NewMessage(module, message),
+		args:    args,
+	}
+}
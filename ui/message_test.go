@@ -53,3 +53,38 @@ func TestTemplateMessage(t *testing.T) {
 		t.Fatal(result)
 	}
 }
+
+func TestICUMessage(t *testing.T) {
+	var result string
+	defer func() {
+		DefaultTranslations = NewTranslations()
+		Lang = ""
+	}()
+	Lang = "en"
+	DefaultTranslations = NewTranslations()
+	m := NewMessages()
+	m.Set("{count, plural, other {# files}}", "{count, plural, one {# file} other {# files}} of {{owner}}")
+	m.Set("invalid", "{invalid")
+	DefaultTranslations.SetMessages("en", "testmodule", m)
+	message := NewICUMessage("testmodule", "{count, plural, other {# files}}", map[string]interface{}{"count": 1, "owner": "Tom"})
+	result = message.Translate("")
+	if result != "1 file of Tom" {
+		t.Fatal(result)
+	}
+	result = message.TranslateWith(DefaultTranslations, "zh")
+	if result != "1 file of Tom" {
+		t.Fatal(result)
+	}
+	result = NewICUMessage("testmodule", "{count, plural, other {# files}}", map[string]interface{}{"count": 0, "owner": "Tom"}).TranslateWith(DefaultTranslations, "fr")
+	if result != "0 files of Tom" {
+		t.Fatal(result)
+	}
+	result = message.Translated("").Label()
+	if result != "1 file of Tom" {
+		t.Fatal(result)
+	}
+	result = NewICUMessage("testmodule", "invalid", nil).Translate("")
+	if result != "{invalid" {
+		t.Fatal(result)
+	}
+}
@@ -0,0 +1,643 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+//ErrMessageFormatSyntax error raised if message format pattern is not valid.
+var ErrMessageFormatSyntax = errors.New("ui:message format syntax error")
+
+//ErrInvalidArgument error raised if message argument can not be formatted as given type.
+var ErrInvalidArgument = errors.New("ui:invalid message argument")
+
+//ArgumentFormatter format message argument value in given language and style.
+type ArgumentFormatter func(lang string, style string, value interface{}) (string, error)
+
+//ArgumentFormatters formatters used by "number","date" and "time" arguments.
+//Formatters can be replaced at startup to support locale aware formatting.
+var ArgumentFormatters = map[string]ArgumentFormatter{
+	"number": FormatNumberArgument,
+	"date":   FormatDateArgument,
+	"time":   FormatTimeArgument,
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch data := v.(type) {
+	case int:
+		return float64(data), true
+	case int8:
+		return float64(data), true
+	case int16:
+		return float64(data), true
+	case int32:
+		return float64(data), true
+	case int64:
+		return float64(data), true
+	case uint:
+		return float64(data), true
+	case uint8:
+		return float64(data), true
+	case uint16:
+		return float64(data), true
+	case uint32:
+		return float64(data), true
+	case uint64:
+		return float64(data), true
+	case float32:
+		return float64(data), true
+	case float64:
+		return data, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(data), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func groupDigits(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+func formatDecimal(f float64, maxFraction int) string {
+	s := strconv.FormatFloat(math.Abs(f), 'f', maxFraction, 64)
+	fraction := ""
+	if i := strings.Index(s, "."); i >= 0 {
+		s, fraction = s[:i], strings.TrimRight(s[i+1:], "0")
+	}
+	s = groupDigits(s)
+	if fraction != "" {
+		s = s + "." + fraction
+	}
+	if f < 0 && s != "0" {
+		s = "-" + s
+	}
+	return s
+}
+
+//FormatNumberArgument default number argument formatter.
+//Supported styles are "" for decimal with at most 3 fraction digits,"integer" and "percent".
+//Language is ignored.
+func FormatNumberArgument(lang string, style string, value interface{}) (string, error) {
+	f, ok := toFloat64(value)
+	if !ok {
+		return "", fmt.Errorf("%w : %v", ErrInvalidArgument, value)
+	}
+	switch style {
+	case "integer":
+		return formatDecimal(f, 0), nil
+	case "percent":
+		return formatDecimal(f*100, 0) + "%", nil
+	}
+	return formatDecimal(f, 3), nil
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch data := v.(type) {
+	case time.Time:
+		return data, true
+	case *time.Time:
+		if data != nil {
+			return *data, true
+		}
+	}
+	return time.Time{}, false
+}
+
+var dateLayouts = map[string]string{
+	"short":  "1/2/06",
+	"medium": "Jan 2, 2006",
+	"long":   "January 2, 2006",
+	"full":   "Monday, January 2, 2006",
+}
+
+var timeLayouts = map[string]string{
+	"short":  "3:04 PM",
+	"medium": "3:04:05 PM",
+	"long":   "3:04:05 PM MST",
+	"full":   "3:04:05 PM MST",
+}
+
+func formatTimeLayout(layouts map[string]string, style string, value interface{}) (string, error) {
+	t, ok := toTime(value)
+	if !ok {
+		return "", fmt.Errorf("%w : %v", ErrInvalidArgument, value)
+	}
+	layout, ok := layouts[style]
+	if !ok {
+		layout = layouts["medium"]
+	}
+	return t.Format(layout), nil
+}
+
+//FormatDateArgument default date argument formatter.
+//Supported styles are "short","medium","long" and "full",medium is used by default.
+//Language is ignored.
+func FormatDateArgument(lang string, style string, value interface{}) (string, error) {
+	return formatTimeLayout(dateLayouts, style, value)
+}
+
+//FormatTimeArgument default time argument formatter.
+//Supported styles are "short","medium","long" and "full",medium is used by default.
+//Language is ignored.
+func FormatTimeArgument(lang string, style string, value interface{}) (string, error) {
+	return formatTimeLayout(timeLayouts, style, value)
+}
+
+func formatArgument(lang string, typ string, style string, value interface{}) (string, error) {
+	formatter := ArgumentFormatters[typ]
+	if formatter == nil {
+		return "", fmt.Errorf("%w : formatter of \"%s\" not found", ErrInvalidArgument, typ)
+	}
+	return formatter(lang, style, value)
+}
+
+type mfNode interface {
+	format(w *strings.Builder, lang string, args map[string]interface{}, pound *float64) error
+}
+
+type mfText string
+
+func (n mfText) format(w *strings.Builder, lang string, args map[string]interface{}, pound *float64) error {
+	w.WriteString(string(n))
+	return nil
+}
+
+type mfPound struct{}
+
+func (n mfPound) format(w *strings.Builder, lang string, args map[string]interface{}, pound *float64) error {
+	if pound == nil {
+		w.WriteByte('#')
+		return nil
+	}
+	s, err := formatArgument(lang, "number", "", *pound)
+	if err != nil {
+		return err
+	}
+	w.WriteString(s)
+	return nil
+}
+
+type mfArgument struct {
+	name  string
+	typ   string
+	style string
+}
+
+func (n *mfArgument) format(w *strings.Builder, lang string, args map[string]interface{}, pound *float64) error {
+	v, ok := args[n.name]
+	if !ok {
+		w.WriteString("{" + n.name + "}")
+		return nil
+	}
+	if n.typ != "" {
+		s, err := formatArgument(lang, n.typ, n.style, v)
+		if err != nil {
+			return err
+		}
+		w.WriteString(s)
+		return nil
+	}
+	switch data := v.(type) {
+	case string:
+		w.WriteString(data)
+	case Translatable:
+		w.WriteString(data.Translate(lang))
+	case time.Time, *time.Time:
+		s, err := formatArgument(lang, "date", "", v)
+		if err != nil {
+			return err
+		}
+		w.WriteString(s)
+	default:
+		if _, ok := toFloat64(v); ok {
+			s, err := formatArgument(lang, "number", "", v)
+			if err != nil {
+				return err
+			}
+			w.WriteString(s)
+			return nil
+		}
+		w.WriteString(fmt.Sprint(v))
+	}
+	return nil
+}
+
+type mfPlural struct {
+	name    string
+	ordinal bool
+	offset  float64
+	exact   map[float64][]mfNode
+	cases   map[string][]mfNode
+}
+
+func (n *mfPlural) format(w *strings.Builder, lang string, args map[string]interface{}, pound *float64) error {
+	v, ok := args[n.name]
+	if !ok {
+		w.WriteString("{" + n.name + "}")
+		return nil
+	}
+	f, ok := toFloat64(v)
+	if !ok {
+		return fmt.Errorf("%w : %v", ErrInvalidArgument, v)
+	}
+	value := f - n.offset
+	nodes, ok := n.exact[f]
+	if !ok {
+		var operand interface{} = value
+		if s, isString := v.(string); isString && n.offset == 0 {
+			operand = s
+		}
+		var category PluralCategory
+		var err error
+		if n.ordinal {
+			category, err = OrdinalCategory(lang, operand)
+		} else {
+			category, err = CardinalCategory(lang, operand)
+		}
+		if err != nil {
+			return err
+		}
+		nodes, ok = n.cases[string(category)]
+		if !ok {
+			nodes = n.cases[string(PluralOther)]
+		}
+	}
+	return formatNodes(w, nodes, lang, args, &value)
+}
+
+type mfSelect struct {
+	name  string
+	cases map[string][]mfNode
+}
+
+func (n *mfSelect) format(w *strings.Builder, lang string, args map[string]interface{}, pound *float64) error {
+	v, ok := args[n.name]
+	if !ok {
+		w.WriteString("{" + n.name + "}")
+		return nil
+	}
+	nodes, ok := n.cases[fmt.Sprint(v)]
+	if !ok {
+		nodes = n.cases["other"]
+	}
+	return formatNodes(w, nodes, lang, args, pound)
+}
+
+func formatNodes(w *strings.Builder, nodes []mfNode, lang string, args map[string]interface{}, pound *float64) error {
+	for _, v := range nodes {
+		err := v.format(w, lang, args, pound)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mfParser struct {
+	src []rune
+	pos int
+}
+
+func (p *mfParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w : %s at %d", ErrMessageFormatSyntax, fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *mfParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *mfParser) peek(offset int) rune {
+	if p.pos+offset >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+offset]
+}
+
+func (p *mfParser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *mfParser) word() string {
+	start := p.pos
+	for !p.eof() {
+		r := p.src[p.pos]
+		if unicode.IsSpace(r) || r == ',' || r == '{' || r == '}' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *mfParser) expect(r rune) error {
+	p.skipSpace()
+	if p.peek(0) != r {
+		return p.errorf("\"%c\" expected", r)
+	}
+	p.pos++
+	return nil
+}
+
+func isQuotable(r rune, inPlural bool) bool {
+	return r == '{' || r == '}' || r == '|' || (inPlural && r == '#')
+}
+
+//parseMessage parse message until end of pattern or unmatched "}".
+func (p *mfParser) parseMessage(inPlural bool, nested bool) ([]mfNode, error) {
+	nodes := []mfNode{}
+	text := strings.Builder{}
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, mfText(text.String()))
+			text.Reset()
+		}
+	}
+	for !p.eof() {
+		r := p.src[p.pos]
+		switch {
+		case r == '\'':
+			next := p.peek(1)
+			if next == '\'' {
+				text.WriteRune('\'')
+				p.pos += 2
+				continue
+			}
+			if !isQuotable(next, inPlural) {
+				text.WriteRune('\'')
+				p.pos++
+				continue
+			}
+			p.pos++
+			for !p.eof() {
+				if p.src[p.pos] == '\'' {
+					if p.peek(1) == '\'' {
+						text.WriteRune('\'')
+						p.pos += 2
+						continue
+					}
+					p.pos++
+					break
+				}
+				text.WriteRune(p.src[p.pos])
+				p.pos++
+			}
+		case r == '#' && inPlural:
+			flush()
+			nodes = append(nodes, mfPound{})
+			p.pos++
+		case r == '}':
+			if !nested {
+				return nil, p.errorf("unexpected \"}\"")
+			}
+			flush()
+			return nodes, nil
+		case r == '{':
+			flush()
+			node, err := p.parseArgument(inPlural)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		default:
+			text.WriteRune(r)
+			p.pos++
+		}
+	}
+	if nested {
+		return nil, p.errorf("\"}\" expected")
+	}
+	flush()
+	return nodes, nil
+}
+
+//parseArgument parse argument starts with "{".
+//Legacy "{{name}}" tokens are parsed as simple arguments.
+func (p *mfParser) parseArgument(inPlural bool) (mfNode, error) {
+	p.pos++
+	if p.peek(0) == '{' {
+		p.pos++
+		p.skipSpace()
+		name := p.word()
+		p.skipSpace()
+		if name == "" || p.peek(0) != '}' || p.peek(1) != '}' {
+			return nil, p.errorf("invalid token")
+		}
+		p.pos += 2
+		return &mfArgument{name: name}, nil
+	}
+	p.skipSpace()
+	name := p.word()
+	if name == "" {
+		return nil, p.errorf("argument name expected")
+	}
+	p.skipSpace()
+	if p.peek(0) == '}' {
+		p.pos++
+		return &mfArgument{name: name}, nil
+	}
+	if err := p.expect(','); err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	typ := p.word()
+	p.skipSpace()
+	switch typ {
+	case "number", "date", "time":
+		style := ""
+		if p.peek(0) == ',' {
+			p.pos++
+			start := p.pos
+			for !p.eof() && p.src[p.pos] != '}' {
+				if p.src[p.pos] == '{' {
+					return nil, p.errorf("unexpected \"{\"")
+				}
+				p.pos++
+			}
+			style = strings.TrimSpace(string(p.src[start:p.pos]))
+		}
+		if err := p.expect('}'); err != nil {
+			return nil, err
+		}
+		return &mfArgument{name: name, typ: typ, style: style}, nil
+	case "plural", "selectordinal":
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		return p.parsePlural(name, typ == "selectordinal")
+	case "select":
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		return p.parseSelect(name, inPlural)
+	}
+	return nil, p.errorf("unknown argument type \"%s\"", typ)
+}
+
+func (p *mfParser) parseCases(inPlural bool, f func(selector string, nodes []mfNode) error) error {
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return p.errorf("\"}\" expected")
+		}
+		if p.peek(0) == '}' {
+			p.pos++
+			return nil
+		}
+		selector := p.word()
+		if selector == "" {
+			return p.errorf("selector expected")
+		}
+		if err := p.expect('{'); err != nil {
+			return err
+		}
+		nodes, err := p.parseMessage(inPlural, true)
+		if err != nil {
+			return err
+		}
+		p.pos++
+		err = f(selector, nodes)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *mfParser) parsePlural(name string, ordinal bool) (mfNode, error) {
+	n := &mfPlural{
+		name:    name,
+		ordinal: ordinal,
+		exact:   map[float64][]mfNode{},
+		cases:   map[string][]mfNode{},
+	}
+	p.skipSpace()
+	if strings.HasPrefix(string(p.src[p.pos:]), "offset:") {
+		p.pos += len("offset:")
+		p.skipSpace()
+		offset, err := strconv.ParseFloat(p.word(), 64)
+		if err != nil {
+			return nil, p.errorf("invalid offset")
+		}
+		n.offset = offset
+	}
+	err := p.parseCases(true, func(selector string, nodes []mfNode) error {
+		if strings.HasPrefix(selector, "=") {
+			f, err := strconv.ParseFloat(selector[1:], 64)
+			if err != nil {
+				return p.errorf("invalid selector \"%s\"", selector)
+			}
+			n.exact[f] = nodes
+			return nil
+		}
+		switch PluralCategory(selector) {
+		case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+			n.cases[selector] = nodes
+			return nil
+		}
+		return p.errorf("invalid selector \"%s\"", selector)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n.cases[string(PluralOther)] == nil {
+		return nil, p.errorf("\"other\" case of \"%s\" required", name)
+	}
+	return n, nil
+}
+
+func (p *mfParser) parseSelect(name string, inPlural bool) (mfNode, error) {
+	n := &mfSelect{
+		name:  name,
+		cases: map[string][]mfNode{},
+	}
+	err := p.parseCases(inPlural, func(selector string, nodes []mfNode) error {
+		n.cases[selector] = nodes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n.cases["other"] == nil {
+		return nil, p.errorf("\"other\" case of \"%s\" required", name)
+	}
+	return n, nil
+}
+
+//MessageFormat compiled ICU message format pattern
+type MessageFormat struct {
+	pattern string
+	nodes   []mfNode
+}
+
+//String return message format pattern.
+func (f *MessageFormat) String() string {
+	return f.pattern
+}
+
+//Format format message with given language and arguments.
+//Missing arguments are output as "{name}".
+//Return formatted message and any error if raised.
+func (f *MessageFormat) Format(lang string, args map[string]interface{}) (string, error) {
+	w := &strings.Builder{}
+	err := formatNodes(w, f.nodes, lang, args, nil)
+	if err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+//CompileMessageFormat compile ICU message format pattern.
+//Plural,select,selectordinal,number,date and time arguments are supported.
+//Legacy "{{name}}" tokens are parsed as simple arguments.
+//Return compiled message format and any error if raised.
+func CompileMessageFormat(pattern string) (*MessageFormat, error) {
+	p := &mfParser{src: []rune(pattern)}
+	nodes, err := p.parseMessage(false, false)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageFormat{pattern: pattern, nodes: nodes}, nil
+}
+
+var messageFormatCache sync.Map
+
+//FormatMessage format ICU message format pattern with given language and arguments.
+//Compiled patterns are cached.
+//Return formatted message and any error if raised.
+func FormatMessage(lang string, pattern string, args map[string]interface{}) (string, error) {
+	var f *MessageFormat
+	cached, ok := messageFormatCache.Load(pattern)
+	if ok {
+		f = cached.(*MessageFormat)
+	} else {
+		var err error
+		f, err = CompileMessageFormat(pattern)
+		if err != nil {
+			return "", err
+		}
+		messageFormatCache.Store(pattern, f)
+	}
+	return f.Format(lang, args)
+}
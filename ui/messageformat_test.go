@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMessageFormat(t *testing.T) {
+	date := time.Date(2020, 3, 4, 15, 6, 7, 0, time.UTC)
+	var tests = []struct {
+		lang    string
+		pattern string
+		args    map[string]interface{}
+		result  string
+	}{
+		{"en", "Hello {name}", map[string]interface{}{"name": "world"}, "Hello world"},
+		{"en", "Hello {{name}}", map[string]interface{}{"name": "world"}, "Hello world"},
+		{"en", "Hello {missing}", nil, "Hello {missing}"},
+		{"en", "{count} items", map[string]interface{}{"count": 12345}, "12,345 items"},
+		{"en", "{n, number}", map[string]interface{}{"n": -1234.5678}, "-1,234.568"},
+		{"en", "{n, number, integer}", map[string]interface{}{"n": 1234.6}, "1,235"},
+		{"en", "{n, number, percent}", map[string]interface{}{"n": 0.256}, "26%"},
+		{"en", "{d, date, short}", map[string]interface{}{"d": date}, "3/4/20"},
+		{"en", "{d, date}", map[string]interface{}{"d": date}, "Mar 4, 2020"},
+		{"en", "{d, date, full}", map[string]interface{}{"d": &date}, "Wednesday, March 4, 2020"},
+		{"en", "{d, time, short}", map[string]interface{}{"d": date}, "3:06 PM"},
+		{"en", "{count, plural, =0 {no files} one {# file} other {# files}}", map[string]interface{}{"count": 0}, "no files"},
+		{"en", "{count, plural, =0 {no files} one {# file} other {# files}}", map[string]interface{}{"count": 1}, "1 file"},
+		{"en", "{count, plural, =0 {no files} one {# file} other {# files}}", map[string]interface{}{"count": 1200}, "1,200 files"},
+		{"en", "{count, plural, one {# file} other {# files}}", map[string]interface{}{"count": "1.5"}, "1.5 files"},
+		{"zh", "{count, plural, one {# file} other {# 个文件}}", map[string]interface{}{"count": 1}, "1 个文件"},
+		{"ru", "{count, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", map[string]interface{}{"count": 3}, "3 файла"},
+		{"ru", "{count, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", map[string]interface{}{"count": 25}, "25 файлов"},
+		{"ar", "{count, plural, zero {zero} one {one} two {two} few {few} many {many} other {other}}", map[string]interface{}{"count": 104}, "few"},
+		{"en", "{count, plural, offset:1 =0 {nobody} =1 {{name}} one {{name} and # other} other {{name} and # others}}", map[string]interface{}{"count": 3, "name": "Tom"}, "Tom and 2 others"},
+		{"en", "{count, plural, offset:1 =0 {nobody} =1 {{name}} one {{name} and # other} other {{name} and # others}}", map[string]interface{}{"count": 2, "name": "Tom"}, "Tom and 1 other"},
+		{"en", "{count, plural, offset:1 =0 {nobody} =1 {{name}} one {{name} and # other} other {{name} and # others}}", map[string]interface{}{"count": 1, "name": "Tom"}, "Tom"},
+		{"en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]interface{}{"n": 23}, "23rd"},
+		{"en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]interface{}{"n": 11}, "11th"},
+		{"en", "{gender, select, male {He} female {She} other {They}} liked it", map[string]interface{}{"gender": "female"}, "She liked it"},
+		{"en", "{gender, select, male {He} female {She} other {They}} liked it", map[string]interface{}{"gender": "unknown"}, "They liked it"},
+		{"en", "{count, plural, other {{gender, select, male {his #} other {their #}}}}", map[string]interface{}{"count": 2, "gender": "male"}, "his 2"},
+		{"en", "It''s '{name}' # '#'", map[string]interface{}{"name": "x"}, "It's {name} # '#'"},
+		{"en", "{count, plural, other {'#' is #}}", map[string]interface{}{"count": 5}, "# is 5"},
+		{"en", "{label}", map[string]interface{}{"label": NewMessage("test", "translatable")}, "translatable"},
+	}
+	for _, v := range tests {
+		result, err := FormatMessage(v.lang, v.pattern, v.args)
+		if err != nil {
+			t.Fatal(v.pattern, err)
+		}
+		if result != v.result {
+			t.Fatal(v.pattern, result)
+		}
+	}
+}
+
+func TestMessageFormatError(t *testing.T) {
+	var patterns = []string{
+		"{",
+		"}",
+		"{name",
+		"{name, unknown}",
+		"{count, plural, one {#}}",
+		"{count, plural, single {#} other {#}}",
+		"{count, plural, =a {#} other {#}}",
+		"{gender, select, male {he}}",
+		"{count, plural, other {#}",
+		"{n, number, {style}}",
+		"{{name}",
+		"{, number}",
+	}
+	for _, v := range patterns {
+		_, err := CompileMessageFormat(v)
+		if !errors.Is(err, ErrMessageFormatSyntax) {
+			t.Fatal(v, err)
+		}
+	}
+	_, err := FormatMessage("en", "{n, number}", map[string]interface{}{"n": "abc"})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal(err)
+	}
+	_, err = FormatMessage("en", "{count, plural, other {#}}", map[string]interface{}{"count": true})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal(err)
+	}
+	_, err = FormatMessage("en", "{d, date}", map[string]interface{}{"d": 1})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatal(err)
+	}
+}
+
+func TestArgumentFormatters(t *testing.T) {
+	formatter := ArgumentFormatters["number"]
+	defer func() {
+		ArgumentFormatters["number"] = formatter
+	}()
+	ArgumentFormatters["number"] = func(lang string, style string, value interface{}) (string, error) {
+		return lang + ":" + style, nil
+	}
+	result, err := FormatMessage("de", "{n, number, integer} {count, plural, other {#}}", map[string]interface{}{"n": 1, "count": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "de:integer de:" {
+		t.Fatal(result)
+	}
+}
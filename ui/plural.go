@@ -0,0 +1,278 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//ErrInvalidPluralOperand error raised if value can not be used as plural operand.
+var ErrInvalidPluralOperand = errors.New("ui:invalid plural operand")
+
+//PluralCategory CLDR plural category
+type PluralCategory string
+
+//CLDR plural categories.
+const (
+	PluralZero  = PluralCategory("zero")
+	PluralOne   = PluralCategory("one")
+	PluralTwo   = PluralCategory("two")
+	PluralFew   = PluralCategory("few")
+	PluralMany  = PluralCategory("many")
+	PluralOther = PluralCategory("other")
+)
+
+//PluralOperands CLDR plural operands
+type PluralOperands struct {
+	//N absolute value
+	N float64
+	//I integer digits
+	I int64
+	//V number of visible fraction digits,with trailing zeros
+	V int
+	//W number of visible fraction digits,without trailing zeros
+	W int
+	//F visible fraction digits,with trailing zeros
+	F int64
+	//T visible fraction digits,without trailing zeros
+	T int64
+}
+
+//NewPluralOperands create plural operands from integer,float or decimal string.
+//Visible fraction digits of string such as "1.50" are kept.
+//Return operands and any error if raised.
+func NewPluralOperands(v interface{}) (*PluralOperands, error) {
+	var s string
+	switch data := v.(type) {
+	case string:
+		s = strings.TrimSpace(data)
+	case float64:
+		s = strconv.FormatFloat(data, 'f', -1, 64)
+	case float32:
+		s = strconv.FormatFloat(float64(data), 'f', -1, 32)
+	default:
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, fmt.Errorf("%w : %v", ErrInvalidPluralOperand, v)
+		}
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	s = strings.TrimPrefix(s, "-")
+	o := &PluralOperands{}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return nil, fmt.Errorf("%w : %v", ErrInvalidPluralOperand, v)
+	}
+	o.N = n
+	intpart := s
+	fraction := ""
+	if i := strings.Index(s, "."); i >= 0 {
+		intpart, fraction = s[:i], s[i+1:]
+	}
+	if intpart == "" {
+		intpart = "0"
+	}
+	o.I, err = strconv.ParseInt(intpart, 10, 64)
+	if err != nil {
+		o.I = int64(math.Trunc(n))
+	}
+	if fraction != "" {
+		o.V = len(fraction)
+		o.F, _ = strconv.ParseInt(fraction, 10, 64)
+		trimmed := strings.TrimRight(fraction, "0")
+		o.W = len(trimmed)
+		o.T, _ = strconv.ParseInt("0"+trimmed, 10, 64)
+	}
+	return o, nil
+}
+
+//PluralRule func which returns plural category of operands
+type PluralRule func(o *PluralOperands) PluralCategory
+
+func inRange(x float64, min float64, max float64) bool {
+	return x == math.Trunc(x) && x >= min && x <= max
+}
+
+func imod(i int64, m int64) int64 {
+	return i % m
+}
+
+func pluralOther(o *PluralOperands) PluralCategory {
+	return PluralOther
+}
+
+func pluralOneInteger(o *PluralOperands) PluralCategory {
+	if o.I == 1 && o.V == 0 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralOneN(o *PluralOperands) PluralCategory {
+	if o.N == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralOneZeroOne(o *PluralOperands) PluralCategory {
+	if o.I == 0 || o.I == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralEastSlavic(o *PluralOperands) PluralCategory {
+	if o.V != 0 {
+		return PluralOther
+	}
+	i10, i100 := imod(o.I, 10), imod(o.I, 100)
+	switch {
+	case i10 == 1 && i100 != 11:
+		return PluralOne
+	case i10 >= 2 && i10 <= 4 && (i100 < 12 || i100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralPolish(o *PluralOperands) PluralCategory {
+	if o.V != 0 {
+		return PluralOther
+	}
+	i10, i100 := imod(o.I, 10), imod(o.I, 100)
+	switch {
+	case o.I == 1:
+		return PluralOne
+	case i10 >= 2 && i10 <= 4 && (i100 < 12 || i100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralCzech(o *PluralOperands) PluralCategory {
+	switch {
+	case o.V != 0:
+		return PluralMany
+	case o.I == 1:
+		return PluralOne
+	case o.I >= 2 && o.I <= 4:
+		return PluralFew
+	}
+	return PluralOther
+}
+
+func pluralArabic(o *PluralOperands) PluralCategory {
+	n100 := math.Mod(o.N, 100)
+	switch {
+	case o.N == 0:
+		return PluralZero
+	case o.N == 1:
+		return PluralOne
+	case o.N == 2:
+		return PluralTwo
+	case inRange(n100, 3, 10):
+		return PluralFew
+	case inRange(n100, 11, 99):
+		return PluralMany
+	}
+	return PluralOther
+}
+
+func pluralHebrew(o *PluralOperands) PluralCategory {
+	switch {
+	case (o.I == 1 && o.V == 0) || (o.I == 0 && o.V != 0):
+		return PluralOne
+	case o.I == 2 && o.V == 0:
+		return PluralTwo
+	}
+	return PluralOther
+}
+
+func ordinalEnglish(o *PluralOperands) PluralCategory {
+	n10, n100 := math.Mod(o.N, 10), math.Mod(o.N, 100)
+	switch {
+	case n10 == 1 && n100 != 11:
+		return PluralOne
+	case n10 == 2 && n100 != 12:
+		return PluralTwo
+	case n10 == 3 && n100 != 13:
+		return PluralFew
+	}
+	return PluralOther
+}
+
+func ordinalItalian(o *PluralOperands) PluralCategory {
+	switch o.N {
+	case 11, 8, 80, 800:
+		return PluralMany
+	}
+	return PluralOther
+}
+
+//CardinalRules CLDR cardinal plural rules by language.
+//Languages not listed only have "other" category.
+var CardinalRules = map[string]PluralRule{
+	"en": pluralOneInteger,
+	"de": pluralOneInteger,
+	"nl": pluralOneInteger,
+	"sv": pluralOneInteger,
+	"it": pluralOneInteger,
+	"es": pluralOneN,
+	"tr": pluralOneN,
+	"fr": pluralOneZeroOne,
+	"pt": pluralOneZeroOne,
+	"ru": pluralEastSlavic,
+	"uk": pluralEastSlavic,
+	"pl": pluralPolish,
+	"cs": pluralCzech,
+	"ar": pluralArabic,
+	"he": pluralHebrew,
+	"zh": pluralOther,
+	"ja": pluralOther,
+	"ko": pluralOther,
+	"vi": pluralOther,
+	"th": pluralOther,
+	"id": pluralOther,
+}
+
+//OrdinalRules CLDR ordinal plural rules by language.
+//Languages not listed only have "other" category.
+var OrdinalRules = map[string]PluralRule{
+	"en": ordinalEnglish,
+	"fr": pluralOneN,
+	"it": ordinalItalian,
+}
+
+func findPluralRule(rules map[string]PluralRule, lang string) PluralRule {
+	for _, l := range appendLangChain(nil, NormalizeLang(lang)) {
+		if r := rules[l]; r != nil {
+			return r
+		}
+	}
+	return pluralOther
+}
+
+//CardinalCategory return cardinal plural category of value in given language.
+//Return category and any error if raised.
+func CardinalCategory(lang string, v interface{}) (PluralCategory, error) {
+	o, err := NewPluralOperands(v)
+	if err != nil {
+		return PluralOther, err
+	}
+	return findPluralRule(CardinalRules, lang)(o), nil
+}
+
+//OrdinalCategory return ordinal plural category of value in given language.
+//Return category and any error if raised.
+func OrdinalCategory(lang string, v interface{}) (PluralCategory, error) {
+	o, err := NewPluralOperands(v)
+	if err != nil {
+		return PluralOther, err
+	}
+	return findPluralRule(OrdinalRules, lang)(o), nil
+}
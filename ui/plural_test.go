@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPluralOperands(t *testing.T) {
+	o, err := NewPluralOperands("-1.50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.N != 1.5 || o.I != 1 || o.V != 2 || o.W != 1 || o.F != 50 || o.T != 5 {
+		t.Fatal(o)
+	}
+	o, err = NewPluralOperands(12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.N != 12 || o.I != 12 || o.V != 0 {
+		t.Fatal(o)
+	}
+	_, err = NewPluralOperands("abc")
+	if !errors.Is(err, ErrInvalidPluralOperand) {
+		t.Fatal(err)
+	}
+	_, err = NewPluralOperands(struct{}{})
+	if !errors.Is(err, ErrInvalidPluralOperand) {
+		t.Fatal(err)
+	}
+}
+
+func TestCardinalCategory(t *testing.T) {
+	var tests = []struct {
+		lang     string
+		value    interface{}
+		category PluralCategory
+	}{
+		{"en", 1, PluralOne},
+		{"en", 0, PluralOther},
+		{"en", "1.0", PluralOther},
+		{"en-US", 1, PluralOne},
+		{"zh", 1, PluralOther},
+		{"zh-TW", 1, PluralOther},
+		{"fr", 0, PluralOne},
+		{"fr", 1.5, PluralOne},
+		{"fr", 2, PluralOther},
+		{"ru", 1, PluralOne},
+		{"ru", 21, PluralOne},
+		{"ru", 11, PluralMany},
+		{"ru", 3, PluralFew},
+		{"ru", 13, PluralMany},
+		{"ru", 5, PluralMany},
+		{"ru", 1.5, PluralOther},
+		{"pl", 1, PluralOne},
+		{"pl", 22, PluralFew},
+		{"pl", 21, PluralMany},
+		{"cs", 3, PluralFew},
+		{"cs", 0.5, PluralMany},
+		{"ar", 0, PluralZero},
+		{"ar", 1, PluralOne},
+		{"ar", 2, PluralTwo},
+		{"ar", 103, PluralFew},
+		{"ar", 11, PluralMany},
+		{"ar", 100, PluralOther},
+		{"ar", 3.5, PluralOther},
+		{"he", 2, PluralTwo},
+		{"unknown", 1, PluralOther},
+	}
+	for _, v := range tests {
+		c, err := CardinalCategory(v.lang, v.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != v.category {
+			t.Fatal(v.lang, v.value, c)
+		}
+	}
+}
+
+func TestOrdinalCategory(t *testing.T) {
+	var tests = []struct {
+		lang     string
+		value    interface{}
+		category PluralCategory
+	}{
+		{"en", 1, PluralOne},
+		{"en", 11, PluralOther},
+		{"en", 22, PluralTwo},
+		{"en", 103, PluralFew},
+		{"en", 4, PluralOther},
+		{"fr", 1, PluralOne},
+		{"it", 8, PluralMany},
+		{"zh", 1, PluralOther},
+	}
+	for _, v := range tests {
+		c, err := OrdinalCategory(v.lang, v.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != v.category {
+			t.Fatal(v.lang, v.value, c)
+		}
+	}
+}
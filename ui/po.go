@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//POContextSeparator separator between msgctxt and msgid in message keys,same as gettext.
+const POContextSeparator = "\x04"
+
+type poEntry struct {
+	context   *string
+	id        *string
+	plural    *string
+	strs      map[int]*string
+	fuzzy     bool
+	lastField *string
+}
+
+//ParseMessagesPO parse messages from gettext po file.
+//Header,fuzzy and untranslated entries are skipped.
+//Message key is msgid,or msgctxt and msgid joined by POContextSeparator.
+//First plural form will be used for entries with msgid_plural.
+//Return messages and any error if raised.
+func ParseMessagesPO(data []byte) (*Messages, error) {
+	m := NewMessages()
+	e := &poEntry{strs: map[int]*string{}}
+	fuzzy := false
+	flush := func() {
+		if e.id != nil && *e.id != "" && !e.fuzzy {
+			if str := e.strs[0]; str != nil && *str != "" {
+				key := *e.id
+				if e.context != nil {
+					key = *e.context + POContextSeparator + key
+				}
+				(*m)[key] = *str
+			}
+		}
+		e = &poEntry{strs: map[int]*string{}}
+	}
+	for k, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		lineno := k + 1
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				fuzzy = true
+			}
+			continue
+		}
+		if line[0] == '"' {
+			if e.lastField == nil {
+				return nil, fmt.Errorf("%w : unexpected string at line %d", ErrInvalidCatalogue, lineno)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("%w : invalid string at line %d", ErrInvalidCatalogue, lineno)
+			}
+			*e.lastField += s
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w : invalid line %d", ErrInvalidCatalogue, lineno)
+		}
+		s, err := strconv.Unquote(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("%w : invalid string at line %d", ErrInvalidCatalogue, lineno)
+		}
+		keyword := fields[0]
+		if (keyword == "msgctxt" || keyword == "msgid") && (e.id != nil || e.context != nil) && len(e.strs) > 0 {
+			flush()
+		}
+		if keyword == "msgctxt" || (keyword == "msgid" && e.context == nil) {
+			e.fuzzy = fuzzy
+			fuzzy = false
+		}
+		switch {
+		case keyword == "msgctxt":
+			e.context = &s
+			e.lastField = e.context
+		case keyword == "msgid":
+			e.id = &s
+			e.lastField = e.id
+		case keyword == "msgid_plural":
+			e.plural = &s
+			e.lastField = e.plural
+		case keyword == "msgstr":
+			e.strs[0] = &s
+			e.lastField = e.strs[0]
+		case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+			n, err := strconv.Atoi(keyword[7 : len(keyword)-1])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%w : invalid keyword at line %d", ErrInvalidCatalogue, lineno)
+			}
+			e.strs[n] = &s
+			e.lastField = e.strs[n]
+		default:
+			return nil, fmt.Errorf("%w : invalid keyword at line %d", ErrInvalidCatalogue, lineno)
+		}
+	}
+	flush()
+	return m, nil
+}
@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//tomlParser parser of toml subset used by catalogues:
+//tables,dotted and quoted keys,basic,literal and multi-line strings.
+type tomlParser struct {
+	data   string
+	pos    int
+	line   int
+	result *Messages
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w : %s at line %d", ErrInvalidCatalogue, fmt.Sprintf(format, args...), p.line)
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *tomlParser) skipSpaces() {
+	for !p.eof() && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+//endLine skip spaces and comment,then expect line end.
+func (p *tomlParser) endLine() error {
+	p.skipSpaces()
+	if !p.eof() && p.data[p.pos] == '#' {
+		for !p.eof() && p.data[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+	if p.eof() {
+		return nil
+	}
+	if strings.HasPrefix(p.data[p.pos:], "\r\n") {
+		p.pos++
+	}
+	if p.data[p.pos] != '\n' {
+		return p.errorf("unexpected %q", p.data[p.pos])
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+func isTOMLBareKeyByte(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *tomlParser) parseKey() (string, error) {
+	parts := []string{}
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return "", p.errorf("key expected")
+		}
+		c := p.data[p.pos]
+		switch {
+		case c == '"' || c == '\'':
+			s, err := p.parseString()
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		case isTOMLBareKeyByte(c):
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyByte(p.data[p.pos]) {
+				p.pos++
+			}
+			parts = append(parts, p.data[start:p.pos])
+		default:
+			return "", p.errorf("unexpected %q", c)
+		}
+		p.skipSpaces()
+		if p.eof() || p.data[p.pos] != '.' {
+			return strings.Join(parts, "."), nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseEscape() (string, error) {
+	if p.eof() {
+		return "", p.errorf("unterminated string")
+	}
+	c := p.data[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		return "\b", nil
+	case 't':
+		return "\t", nil
+	case 'n':
+		return "\n", nil
+	case 'f':
+		return "\f", nil
+	case 'r':
+		return "\r", nil
+	case '"':
+		return "\"", nil
+	case '\\':
+		return "\\", nil
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.data) {
+			return "", p.errorf("invalid unicode escape")
+		}
+		v, err := strconv.ParseUint(p.data[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(v)) {
+			return "", p.errorf("invalid unicode escape")
+		}
+		p.pos += n
+		return string(rune(v)), nil
+	}
+	return "", p.errorf("invalid escape \"\\%c\"", c)
+}
+
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.data[p.pos]
+	multiline := strings.HasPrefix(p.data[p.pos:], strings.Repeat(string(quote), 3))
+	if multiline {
+		p.pos += 3
+		if strings.HasPrefix(p.data[p.pos:], "\r\n") {
+			p.pos += 2
+			p.line++
+		} else if strings.HasPrefix(p.data[p.pos:], "\n") {
+			p.pos++
+			p.line++
+		}
+	} else {
+		p.pos++
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.data[p.pos]
+		if multiline && strings.HasPrefix(p.data[p.pos:], strings.Repeat(string(quote), 3)) {
+			p.pos += 3
+			//Up to two quotes are allowed before closing delimiter.
+			for i := 0; i < 2 && !p.eof() && p.data[p.pos] == quote; i++ {
+				b.WriteByte(quote)
+				p.pos++
+			}
+			return b.String(), nil
+		}
+		if !multiline && c == quote {
+			p.pos++
+			return b.String(), nil
+		}
+		if c == '\n' {
+			if !multiline {
+				return "", p.errorf("unterminated string")
+			}
+			p.line++
+		}
+		p.pos++
+		if c != '\\' || quote == '\'' {
+			b.WriteByte(c)
+			continue
+		}
+		if multiline {
+			//Line ending backslash trims whitespaces and newlines.
+			rest := strings.TrimLeft(p.data[p.pos:], " \t")
+			if strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+				trimmed := strings.TrimLeft(rest, " \t\r\n")
+				p.line += strings.Count(rest[:len(rest)-len(trimmed)], "\n")
+				p.pos = len(p.data) - len(trimmed)
+				continue
+			}
+		}
+		s, err := p.parseEscape()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s)
+	}
+}
+
+func (p *tomlParser) parse() error {
+	table := ""
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return nil
+		}
+		switch p.data[p.pos] {
+		case '\r', '\n', '#':
+		case '[':
+			p.pos++
+			if !p.eof() && p.data[p.pos] == '[' {
+				return p.errorf("array of tables not supported")
+			}
+			key, err := p.parseKey()
+			if err != nil {
+				return err
+			}
+			if p.eof() || p.data[p.pos] != ']' {
+				return p.errorf("\"]\" expected")
+			}
+			p.pos++
+			table = key
+		default:
+			key, err := p.parseKey()
+			if err != nil {
+				return err
+			}
+			if p.eof() || p.data[p.pos] != '=' {
+				return p.errorf("\"=\" expected")
+			}
+			p.pos++
+			p.skipSpaces()
+			if p.eof() || (p.data[p.pos] != '"' && p.data[p.pos] != '\'') {
+				return p.errorf("value of \"%s\" is not string", key)
+			}
+			value, err := p.parseString()
+			if err != nil {
+				return err
+			}
+			if table != "" {
+				key = table + "." + key
+			}
+			if _, ok := (*p.result)[key]; ok {
+				return p.errorf("duplicate key \"%s\"", key)
+			}
+			(*p.result)[key] = value
+		}
+		err := p.endLine()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//ParseMessagesTOML parse messages from toml document.
+//Only string values are supported,keys of tables are joined with ".".
+//Return messages and any error if raised.
+func ParseMessagesTOML(data []byte) (*Messages, error) {
+	p := &tomlParser{
+		data:   string(data),
+		line:   1,
+		result: NewMessages(),
+	}
+	err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return p.result, nil
+}
@@ -1,25 +1,228 @@
 package ui
 
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+//NormalizeLang normalize language code to lower case with "-" separator,
+//such as "zh_TW" to "zh-tw".
+func NormalizeLang(lang string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(lang), "_", "-", -1))
+}
+
+//MissingHandler func called when message not found in any fallback language.
+type MissingHandler func(lang string, module string, key string)
+
 //Translations messages collection grouped by lang and module.
-type Translations map[string]map[string]*Messages
+//Translations is safe for concurrent use.
+//Messages should not be modified after set to translations,
+//replace them by SetMessages or ReplaceAll instead.
+//Zero value is ready to use.
+type Translations struct {
+	locker      sync.RWMutex
+	data        map[string]map[string]*Messages
+	fallbacks   map[string][]string
+	moduleLangs map[string]string
+	onMissing   MissingHandler
+	//languages cached languages,reset when messages changed.
+	languages atomic.Value
+}
 
 //SetMessages set collection messages by given lang and module
 func (c *Translations) SetMessages(lang string, module string, m *Messages) {
-	if (*c)[lang] == nil {
-		(*c)[lang] = map[string]*Messages{}
+	lang = NormalizeLang(lang)
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	if c.data == nil {
+		c.data = map[string]map[string]*Messages{}
+	}
+	if c.data[lang] == nil {
+		c.data[lang] = map[string]*Messages{}
 	}
-	(*c)[lang][module] = m
+	c.data[lang][module] = m
+	c.languages.Store([]string(nil))
+}
+
+//DeleteMessages delete messages by given lang and module.
+func (c *Translations) DeleteMessages(lang string, module string) {
+	lang = NormalizeLang(lang)
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	delete(c.data[lang], module)
+	if len(c.data[lang]) == 0 {
+		delete(c.data, lang)
+	}
+	c.languages.Store([]string(nil))
+}
+
+//ReplaceAll replace all messages with messages in given translations at once.
+//Fallbacks,module languages and missing handler are kept.
+func (c *Translations) ReplaceAll(t *Translations) {
+	t.locker.RLock()
+	data := make(map[string]map[string]*Messages, len(t.data))
+	for lang, modules := range t.data {
+		data[lang] = make(map[string]*Messages, len(modules))
+		for module, m := range modules {
+			data[lang][module] = m
+		}
+	}
+	t.locker.RUnlock()
+	c.locker.Lock()
+	c.data = data
+	c.languages.Store([]string(nil))
+	c.locker.Unlock()
+}
+
+//Languages return sorted normalized languages which have messages.
+//Result is cached until messages changed,and should not be modified.
+func (c *Translations) Languages() []string {
+	if v, ok := c.languages.Load().([]string); ok && v != nil {
+		return v
+	}
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+	result := make([]string, 0, len(c.data))
+	for lang := range c.data {
+		result = append(result, lang)
+	}
+	sort.Strings(result)
+	//Stored under read lock,so cache will not overwrite reset by writers.
+	c.languages.Store(result)
+	return result
+}
+
+//SetFallback set fallback languages tried after given lang,
+//such as "zh-tw" for "zh-hk".
+func (c *Translations) SetFallback(lang string, fallbacks ...string) {
+	normalized := make([]string, len(fallbacks))
+	for k := range fallbacks {
+		normalized[k] = NormalizeLang(fallbacks[k])
+	}
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	if c.fallbacks == nil {
+		c.fallbacks = map[string][]string{}
+	}
+	c.fallbacks[NormalizeLang(lang)] = normalized
+}
+
+//SetModuleLang set default language of module.
+//Module default language is tried before global Lang,
+//and missing messages in it will not be reported because keys are written in it.
+func (c *Translations) SetModuleLang(module string, lang string) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	if c.moduleLangs == nil {
+		c.moduleLangs = map[string]string{}
+	}
+	c.moduleLangs[module] = NormalizeLang(lang)
+}
+
+//SetMissingHandler set handler called when message not found.
+func (c *Translations) SetMissingHandler(h MissingHandler) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.onMissing = h
+}
+
+func appendLang(chain []string, lang string) []string {
+	for _, v := range chain {
+		if v == lang {
+			return chain
+		}
+	}
+	return append(chain, lang)
+}
+
+//appendLangChain append lang and its parents to chain.
+func appendLangChain(chain []string, lang string) []string {
+	for lang != "" {
+		chain = appendLang(chain, lang)
+		i := strings.LastIndex(lang, "-")
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	return chain
+}
+
+func (c *Translations) fallbackLangs(lang string, module string) []string {
+	chain := []string{}
+	for _, l := range appendLangChain(nil, NormalizeLang(lang)) {
+		chain = appendLang(chain, l)
+		for _, v := range c.fallbacks[l] {
+			chain = appendLang(chain, v)
+		}
+	}
+	chain = appendLangChain(chain, c.moduleLangs[module])
+	return appendLangChain(chain, NormalizeLang(Lang))
+}
+
+//FallbackLangs return languages tried in order when translating message of given lang and module.
+//Each language such as "zh-hk" is followed by its fallback languages and then its parent "zh",
+//fallback languages themselves are not expanded to parents,
+//and chain ends with module default language and global Lang.
+func (c *Translations) FallbackLangs(lang string, module string) []string {
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+	return c.fallbackLangs(lang, module)
 }
 
 //Load load translated message by given lang.module and key.
+//Fallback languages are tried in order.
+//Message will not be translated if lang or module is empty.
 //Return translated message and true if found.
 //Return raw message and false if not found
 func (c *Translations) Load(lang string, module string, key string) (string, bool) {
-	m := c.GetMessages(lang, module)
-	if m == nil {
-		return key, false
+	v, _, ok := c.LoadLang(lang, module, key)
+	return v, ok
+}
+
+//LoadLang load translated message by given lang.module and key,
+//and report language which message is resolved in.
+//Fallback languages are tried in order.
+//Message will not be translated if lang or module is empty.
+//Return translated message,resolved language and true if found.
+//Return raw message,module default language or given lang if module default language not set and false if not found
+func (c *Translations) LoadLang(lang string, module string, key string) (string, string, bool) {
+	if lang == "" || module == "" {
+		return key, lang, false
+	}
+	c.locker.RLock()
+	for _, l := range c.fallbackLangs(lang, module) {
+		m := c.data[l][module]
+		if m == nil {
+			continue
+		}
+		if v, ok := (*m)[key]; ok {
+			c.locker.RUnlock()
+			return v, l, true
+		}
+	}
+	onMissing := c.onMissing
+	moduleLang := c.moduleLangs[module]
+	c.locker.RUnlock()
+	if onMissing != nil && (moduleLang == "" || !inLangChain(NormalizeLang(lang), moduleLang)) {
+		onMissing(lang, module, key)
+	}
+	if moduleLang != "" {
+		return key, moduleLang, false
+	}
+	return key, lang, false
+}
+
+//inLangChain check if target is lang or parent of lang.
+func inLangChain(lang string, target string) bool {
+	for _, v := range appendLangChain(nil, lang) {
+		if v == target {
+			return true
+		}
 	}
-	return m.Load(key)
+	return false
 }
 
 //Get load get message by given lang,module and key.
@@ -31,26 +234,27 @@ func (c *Translations) Get(lang string, module string, key string) string {
 }
 
 //GetMessages get messages by given lang and module
+//Fallback languages are not used.
 //Return nil if messages not found
 func (c *Translations) GetMessages(lang string, module string) *Messages {
 	if lang == "" {
 		return nil
 	}
-	if (*c)[lang] == nil {
-		return nil
-	}
 	if module == "" {
 		return nil
 	}
-	if (*c)[lang][module] == nil {
-		return nil
-	}
-	return (*c)[lang][module]
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+	return c.data[NormalizeLang(lang)][module]
 }
 
 // NewTranslations  create new messages Translations
 func NewTranslations() *Translations {
-	return &Translations{}
+	return &Translations{
+		data:        map[string]map[string]*Messages{},
+		fallbacks:   map[string][]string{},
+		moduleLangs: map[string]string{},
+	}
 }
 
 //DefaultTranslations default messages Translations
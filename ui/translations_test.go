@@ -1,6 +1,10 @@
 package ui
 
-import "testing"
+import (
+	"strings"
+	"sync"
+	"testing"
+)
 
 func TestTranslations(t *testing.T) {
 	defer func() {
@@ -47,3 +51,151 @@ func TestTranslations(t *testing.T) {
 		t.Fatal(c)
 	}
 }
+
+func newTestTranslations() *Translations {
+	t := NewTranslations()
+	t.SetMessages("en", "app", NewMessages().Set("hello", "Hello").Set("bye", "Bye").Set("only", "Only en"))
+	t.SetMessages("zh", "app", NewMessages().Set("hello", "你好").Set("bye", "再见"))
+	t.SetMessages("zh_TW", "app", NewMessages().Set("hello", "您好"))
+	t.SetMessages("ja", "lib", NewMessages().Set("hello", "こんにちは"))
+	return t
+}
+
+func TestFallback(t *testing.T) {
+	defer func() {
+		Lang = ""
+	}()
+	c := newTestTranslations()
+	for _, v := range []struct {
+		lang     string
+		key      string
+		expected string
+		ok       bool
+	}{
+		{"zh-TW", "hello", "您好", true},
+		{"zh-tw", "bye", "再见", true},
+		{"zh-Hant-TW", "bye", "再见", true},
+		{"zh-hk", "hello", "你好", true},
+		{"zh-TW", "only", "only", false},
+		{"fr", "hello", "hello", false},
+		{"", "hello", "hello", false},
+	} {
+		result, ok := c.Load(v.lang, "app", v.key)
+		if result != v.expected || ok != v.ok {
+			t.Fatal(v.lang, v.key, result, ok)
+		}
+	}
+	Lang = "en"
+	if result := c.Get("zh-TW", "app", "only"); result != "Only en" {
+		t.Fatal(result)
+	}
+	if result := c.Get("fr", "app", "hello"); result != "Hello" {
+		t.Fatal(result)
+	}
+	c.SetFallback("zh-HK", "zh-TW")
+	if result := c.Get("zh-hk", "app", "hello"); result != "您好" {
+		t.Fatal(result)
+	}
+	c.SetModuleLang("lib", "ja")
+	if result := c.Get("fr", "lib", "hello"); result != "こんにちは" {
+		t.Fatal(result)
+	}
+	if result, lang, ok := c.LoadLang("zh-hk", "app", "hello"); result != "您好" || lang != "zh-tw" || !ok {
+		t.Fatal(result, lang, ok)
+	}
+	if result, lang, ok := c.LoadLang("fr", "lib", "hello"); result != "こんにちは" || lang != "ja" || !ok {
+		t.Fatal(result, lang, ok)
+	}
+	if result, lang, ok := c.LoadLang("fr", "lib", "notexist"); result != "notexist" || lang != "ja" || ok {
+		t.Fatal(result, lang, ok)
+	}
+	langs := c.FallbackLangs("zh-hk", "lib")
+	if strings.Join(langs, ",") != "zh-hk,zh-tw,zh,ja,en" {
+		t.Fatal(langs)
+	}
+	if strings.Join(c.Languages(), ",") != "en,ja,zh,zh-tw" {
+		t.Fatal(c.Languages())
+	}
+	if c.GetMessages("zh_TW", "app") == nil || c.GetMessages("zh-hk", "app") != nil {
+		t.Fatal(c)
+	}
+	c.DeleteMessages("ja", "lib")
+	if strings.Join(c.Languages(), ",") != "en,zh,zh-tw" {
+		t.Fatal(c.Languages())
+	}
+}
+
+func TestZeroValueTranslations(t *testing.T) {
+	defer func() {
+		Lang = ""
+	}()
+	var c Translations
+	if result := c.Get("en", "app", "hello"); result != "hello" {
+		t.Fatal(result)
+	}
+	c.DeleteMessages("en", "app")
+	m := NewMessages()
+	m.Set("hello", "Hello")
+	c.SetMessages("en", "app", m)
+	c.SetFallback("fr", "en")
+	c.SetModuleLang("lib", "en")
+	if result := c.Get("fr", "app", "hello"); result != "Hello" {
+		t.Fatal(result)
+	}
+	t2 := &Translations{}
+	t2.SetModuleLang("lib", "ja")
+	t2.ReplaceAll(&c)
+	if result := t2.Get("fr-ca", "app", "hello"); result != "hello" {
+		t.Fatal(result)
+	}
+	if result := t2.Get("en", "app", "hello"); result != "Hello" {
+		t.Fatal(result)
+	}
+}
+
+func TestMissingHandler(t *testing.T) {
+	c := newTestTranslations()
+	missing := []string{}
+	c.SetMissingHandler(func(lang string, module string, key string) {
+		missing = append(missing, lang+"/"+module+"/"+key)
+	})
+	c.SetModuleLang("app", "en")
+	c.Get("zh-TW", "app", "hello")
+	c.Get("zh-TW", "app", "notexist")
+	c.Get("en-US", "app", "notexist")
+	c.Get("", "app", "notexist")
+	c.Get("fr", "other", "notexist")
+	if strings.Join(missing, ",") != "zh-TW/app/notexist,fr/other/notexist" {
+		t.Fatal(missing)
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	c := newTestTranslations()
+	c.SetFallback("zh-hk", "zh-tw")
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				result := c.Get("zh-hk", "app", "hello")
+				if result != "您好" && result != "哈囉" {
+					panic(result)
+				}
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		n := NewTranslations()
+		n.SetMessages("zh-tw", "app", NewMessages().Set("hello", "哈囉"))
+		c.ReplaceAll(n)
+	}
+	wg.Wait()
+	if result := c.Get("zh-hk", "app", "hello"); result != "哈囉" {
+		t.Fatal(result)
+	}
+	if c.GetMessages("en", "app") != nil {
+		t.Fatal(c)
+	}
+}
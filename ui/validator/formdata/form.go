@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 
+	"github.com/herb-go/herb/middleware/locale"
 	"github.com/herb-go/herb/ui/validator"
 )
 
@@ -12,9 +13,13 @@ import (
 const MsgBadRequest = "Bad request."
 
 //MustValidate init form with request and validate it.
+//Language negotiated by locale middleware will be set to form if form language is empty.
 //You should set form value manually or set form value in form.InitWithRequest method.
 func MustValidate(r *http.Request, m RequestValidator) bool {
 	m.SetHTTPRequest(r)
+	if m.Lang() == "" {
+		locale.Apply(r, m)
+	}
 	err := m.InitWithRequest(r)
 	if err != nil {
 		panic(err)
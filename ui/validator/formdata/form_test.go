@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/herb-go/herb/middleware"
+	"github.com/herb-go/herb/middleware/locale"
 	model "github.com/herb-go/herb/ui/validator"
 )
 
@@ -129,3 +131,31 @@ func TestForm(t *testing.T) {
 		t.Error(resultform.User)
 	}
 }
+
+func TestFormLang(t *testing.T) {
+	l := locale.New()
+	l.Languages = []string{"en", "zh-CN"}
+	var lang string
+	app := middleware.New(l.ServeMiddleware).HandleFunc(func(w http.ResponseWriter, r *http.Request) {
+		form := newTestForm()
+		MustValidate(r, form)
+		lang = form.Lang()
+		form = newTestForm()
+		form.SetLang("en")
+		MustValidate(r, form)
+		if form.Lang() != "en" {
+			t.Fatal(form.Lang())
+		}
+	})
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	if lang != "zh-cn" {
+		t.Fatal(lang)
+	}
+	form := newTestForm()
+	MustValidate(httptest.NewRequest("POST", "/", nil), form)
+	if form.Lang() != "" {
+		t.Fatal(form.Lang())
+	}
+}